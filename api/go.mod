@@ -1,6 +1,6 @@
 module github.com/ericbg27/top10movies-api
 
-go 1.15
+go 1.16
 
 require (
 	github.com/gin-gonic/gin v1.7.1
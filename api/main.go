@@ -1,9 +1,17 @@
 package main
 
 import (
+	"os"
+
 	"github.com/ericbg27/top10movies-api/src/app"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		app.RunMigrations(os.Args[2:])
+
+		return
+	}
+
 	app.StartApplication()
 }
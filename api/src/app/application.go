@@ -7,32 +7,122 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/ericbg27/top10movies-api/src/datasources/cache"
 	"github.com/ericbg27/top10movies-api/src/datasources/database"
+	"github.com/ericbg27/top10movies-api/src/datasources/filemailer"
+	"github.com/ericbg27/top10movies-api/src/datasources/fixtureprovider"
+	"github.com/ericbg27/top10movies-api/src/datasources/lrucache"
+	"github.com/ericbg27/top10movies-api/src/datasources/mailer"
+	memorydb "github.com/ericbg27/top10movies-api/src/datasources/memory"
+	"github.com/ericbg27/top10movies-api/src/datasources/memoryratelimiter"
+	"github.com/ericbg27/top10movies-api/src/datasources/movieprovider"
 	postgresdb "github.com/ericbg27/top10movies-api/src/datasources/postgresql/db"
+	"github.com/ericbg27/top10movies-api/src/datasources/ratelimiter"
 	redisdb "github.com/ericbg27/top10movies-api/src/datasources/redis"
+	"github.com/ericbg27/top10movies-api/src/datasources/smtpmailer"
+	"github.com/ericbg27/top10movies-api/src/datasources/tmdbprovider"
+	movies_service "github.com/ericbg27/top10movies-api/src/services/movies"
 	users_service "github.com/ericbg27/top10movies-api/src/services/users"
+	"github.com/ericbg27/top10movies-api/src/utils/authorization"
 	"github.com/ericbg27/top10movies-api/src/utils/config"
 	"github.com/ericbg27/top10movies-api/src/utils/logger"
+	"github.com/ericbg27/top10movies-api/src/utils/loginguard"
+	"github.com/ericbg27/top10movies-api/src/utils/ratelimit"
 )
 
 var (
 	router = gin.Default()
 )
 
-func StartApplication() {
-	var db database.DatabaseClient
-	db = &postgresdb.PostgresDBClient{
-		Client: nil,
+func newDatabaseClient() database.DatabaseClient {
+	switch config.GetConfig().Database.Driver {
+	case config.DatabaseDriverMemory:
+		return &memorydb.MemoryDBClient{}
+	default:
+		return &postgresdb.PostgresDBClient{
+			Client: nil,
+		}
+	}
+}
+
+func newCacheClient() cache.CacheClient {
+	switch config.GetConfig().Cache.Driver {
+	case config.CacheDriverLRU:
+		return &lrucache.LRUCacheClient{
+			Capacity: config.GetConfig().Cache.Capacity,
+		}
+	default:
+		return &redisdb.RedisCacheClient{
+			Client: nil,
+		}
+	}
+}
+
+func newMovieProvider() movieprovider.MovieProvider {
+	movieApiCfg := config.GetConfig().MovieApi
+
+	switch movieApiCfg.Provider {
+	case config.MovieProviderFixture:
+		logger.Info(fmt.Sprintf("Serving movie data from fixtures at %s", movieApiCfg.FixturesPath))
+
+		return fixtureprovider.NewFixtureMovieProvider(movieApiCfg.FixturesPath)
+	default:
+		return tmdbprovider.NewTMDBMovieProvider(movieApiCfg.ApiKey)
+	}
+}
+
+func newRateLimiter(cacheClient cache.CacheClient) ratelimiter.RateLimiter {
+	if config.GetConfig().RateLimit.Driver == config.RateLimitDriverRedis {
+		if redisClient, ok := cacheClient.(*redisdb.RedisCacheClient); ok {
+			return redisdb.NewRedisRateLimiter(redisClient.Client)
+		}
+
+		logger.Info("Redis rate limiting needs the redis cache driver, falling back to in-memory rate limiting")
+	}
+
+	return memoryratelimiter.NewMemoryRateLimiter()
+}
+
+func newMailer() mailer.Mailer {
+	mailerCfg := config.GetConfig().Mailer
+
+	switch mailerCfg.Driver {
+	case config.MailerDriverFile:
+		logger.Info(fmt.Sprintf("Writing outgoing mail to %q", mailerCfg.OutputPath))
+
+		return filemailer.NewFileMailer(mailerCfg.OutputPath)
+	default:
+		return smtpmailer.NewSMTPMailer(mailerCfg.Host, mailerCfg.Port, mailerCfg.Username, mailerCfg.Password, mailerCfg.From)
 	}
+}
+
+func StartApplication() {
+	db := newDatabaseClient()
 
 	db.SetupDbConnection()
 	defer db.CloseDbConnection(context.Background())
 
+	prepareSchema(db)
+
+	cacheClient := newCacheClient()
+
+	cacheClient.SetupCacheConnection()
+	defer cacheClient.CloseCacheConnection()
+
 	users_service.UsersService.SetupDBClient(db)
+	users_service.UsersService.SetupCacheClient(cacheClient)
+	users_service.UsersService.SetupMailer(newMailer())
+	movies_service.MoviesService.SetupCacheClient(cacheClient)
+	movies_service.MoviesService.SetupMovieProvider(newMovieProvider())
+	authorization.AuthManager.SetupCacheClient(cacheClient)
+	loginguard.LoginGuard.SetupCacheClient(cacheClient)
 
-	mapUrls()
+	stopPurge := startPurgeJob()
+	defer close(stopPurge)
+
+	router.Use(ratelimit.RateLimit(newRateLimiter(cacheClient)))
 
-	redisdb.SetupRedisConnection()
+	mapUrls()
 
 	cfg := config.GetConfig()
 
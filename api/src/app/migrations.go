@@ -0,0 +1,90 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/ericbg27/top10movies-api/src/datasources/database"
+	"github.com/ericbg27/top10movies-api/src/datasources/postgresql/migrations"
+	"github.com/ericbg27/top10movies-api/src/utils/config"
+	"github.com/ericbg27/top10movies-api/src/utils/logger"
+)
+
+const (
+	migrateUsage = "usage: migrate up|down|status"
+)
+
+func prepareSchema(db database.DatabaseClient) {
+	if config.GetConfig().Database.Driver != config.DatabaseDriverPostgres {
+		return
+	}
+
+	migrator, err := migrations.NewMigrator(db)
+	if err != nil {
+		logger.Error("Unable to load database migrations", err)
+		panic(err)
+	}
+
+	if config.GetConfig().Database.AutoMigrate {
+		err = migrator.Up(context.Background())
+	} else {
+		err = migrator.CheckVersion(context.Background())
+	}
+
+	if err != nil {
+		logger.Error("Database schema is not compatible with the application", err)
+		panic(err)
+	}
+}
+
+func RunMigrations(args []string) {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+
+	if config.GetConfig().Database.Driver != config.DatabaseDriverPostgres {
+		fmt.Fprintln(os.Stderr, "migrations are only supported by the postgres database driver")
+		os.Exit(2)
+	}
+
+	db := newDatabaseClient()
+
+	migrator, err := migrations.NewMigrator(db)
+	if err != nil {
+		logger.Error("Unable to load database migrations", err)
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+
+	db.SetupDbConnection()
+
+	switch args[0] {
+	case "up":
+		err = migrator.Up(ctx)
+	case "down":
+		err = migrator.Down(ctx)
+	case "status":
+		var statuses []migrations.MigrationStatus
+		statuses, err = migrator.Status(ctx)
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.Applied {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+
+			fmt.Printf("%04d_%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+	default:
+		err = fmt.Errorf("unknown migration command %q", args[0])
+	}
+
+	db.CloseDbConnection(ctx)
+
+	if err != nil {
+		logger.Error(fmt.Sprintf("Migration command %s failed", args[0]), err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,50 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ericbg27/top10movies-api/src/domain/users"
+	users_service "github.com/ericbg27/top10movies-api/src/services/users"
+	"github.com/ericbg27/top10movies-api/src/utils/config"
+	"github.com/ericbg27/top10movies-api/src/utils/logger"
+)
+
+// startPurgeJob periodically hard-deletes accounts whose soft-delete grace
+// period has expired. Closing the returned channel stops the job.
+func startPurgeJob() chan struct{} {
+	stop := make(chan struct{})
+
+	usersCfg := config.GetConfig().Users
+
+	go runPurgeJob(usersCfg.PurgeInterval, usersCfg.DeletionGracePeriod, stop)
+
+	return stop
+}
+
+func runPurgeJob(interval time.Duration, gracePeriod time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			purgeDeletedUsers(gracePeriod)
+		case <-stop:
+			return
+		}
+	}
+}
+
+func purgeDeletedUsers(gracePeriod time.Duration) {
+	purged, err := users_service.UsersService.PurgeDeletedUsers(users.User{}, time.Now().Add(-gracePeriod))
+	if err != nil {
+		logger.Error("Unable to purge deleted users", err)
+
+		return
+	}
+
+	if purged > 0 {
+		logger.Info(fmt.Sprintf("Purged %d deleted users", purged))
+	}
+}
@@ -3,18 +3,51 @@ package app
 import (
 	"github.com/ericbg27/top10movies-api/src/controllers/movies"
 	"github.com/ericbg27/top10movies-api/src/controllers/users"
+	users_domain "github.com/ericbg27/top10movies-api/src/domain/users"
+	"github.com/ericbg27/top10movies-api/src/utils/authorization"
 )
 
 func mapUrls() {
 	router.POST("/login", users.UsersController.Login)
 	router.POST("/register", users.UsersController.Create)
-	router.POST("/users/:user_id", users.UsersController.Update)
-	router.PATCH("/users/:user_id", users.UsersController.Update)
-	router.DELETE("/users/:user_id", users.UsersController.Delete)
-	router.GET("/users/search", users.UsersController.Search)
+	router.GET("/verify", users.UsersController.Verify)
+	router.POST("/verify/resend", users.UsersController.ResendVerification)
+	router.POST("/password/forgot", users.UsersController.ForgotPassword)
+	router.POST("/password/reset", users.UsersController.ResetPassword)
+	router.POST("/token/refresh", users.UsersController.RefreshToken)
+	router.POST("/logout", users.UsersController.Logout)
+	router.GET("/users/search", authorization.OptionalAuthenticate(), users.UsersController.Search)
+	router.GET("/users/:user_id", authorization.OptionalAuthenticate(), users.UsersController.GetProfile)
+	router.GET("/users/:user_id/favorites", authorization.OptionalAuthenticate(), users.UsersController.GetFavorites)
+	router.GET("/users/:user_id/followers", users.UsersController.GetFollowers)
+	router.GET("/users/:user_id/following", users.UsersController.GetFollowing)
+	router.POST("/users/:user_id/follow", authorization.Authenticate(), users.UsersController.Follow)
+	router.DELETE("/users/:user_id/follow", authorization.Authenticate(), users.UsersController.Unfollow)
 
-	router.GET("/users/:user_id/favorites", users.UsersController.GetFavorites)
-	router.POST("/users/:user_id/favorite", users.UsersController.AddFavorite) // TODO: Do we put movie_id in the URL?
+	owner := router.Group("/users/:user_id", authorization.Authenticate(), authorization.RequireOwner("user_id"))
+
+	owner.POST("", users.UsersController.Update)
+	owner.PATCH("", users.UsersController.Update)
+	owner.DELETE("", users.UsersController.Delete)
+	owner.POST("/password", users.UsersController.ChangePassword)
+	owner.GET("/sessions", users.UsersController.GetSessions)
+	owner.POST("/sessions/revoke-all", users.UsersController.RevokeAllSessions)
+
+	owner.POST("/favorite", users.UsersController.AddFavorite) // TODO: Do we put movie_id in the URL?
+	owner.PUT("/favorites/order", users.UsersController.ReorderFavorites)
+	owner.PUT("/favorites/visibility", users.UsersController.UpdateFavoritesVisibility)
+	owner.PUT("/favorites/rank/:rank", users.UsersController.ReplaceFavorite)
+	owner.DELETE("/favorites/:movie_id", users.UsersController.RemoveFavorite)
+	owner.GET("/feed", users.UsersController.GetFeed)
+
+	admin := router.Group("/admin", authorization.Authenticate())
+
+	admin.GET("/users", authorization.RequireRole(users_domain.RoleModerator, users_domain.RoleAdmin), users.UsersController.ListUsers)
+	admin.PUT("/users/:user_id/status", authorization.RequireRole(users_domain.RoleAdmin), users.UsersController.UpdateUserStatus)
+	admin.PUT("/users/:user_id/role", authorization.RequireRole(users_domain.RoleAdmin), users.UsersController.UpdateUserRole)
+	admin.DELETE("/users/:user_id", authorization.RequireRole(users_domain.RoleAdmin), users.UsersController.DeleteUser)
 
 	router.GET("/search", movies.Search)
+	router.GET("/movies/:movie_id/credits", movies.GetCredits)
+	router.GET("/movies/:movie_id/images", movies.GetImages)
 }
@@ -2,12 +2,23 @@ package movies
 
 import (
 	"net/http"
+	"strconv"
 	"strings"
 
 	movies_service "github.com/ericbg27/top10movies-api/src/services/movies"
+	"github.com/ericbg27/top10movies-api/src/utils/rest_errors"
 	"github.com/gin-gonic/gin"
 )
 
+func getMovieID(movieIdParam string) (int, *rest_errors.RestErr) {
+	movieID, err := strconv.Atoi(movieIdParam)
+	if err != nil {
+		return 0, rest_errors.NewBadRequestError("Movie ID should be a number")
+	}
+
+	return movieID, nil
+}
+
 func Search(c *gin.Context) {
 	queryParams := make(map[string]string)
 
@@ -26,3 +37,39 @@ func Search(c *gin.Context) {
 
 	c.JSON(http.StatusOK, result)
 }
+
+func GetCredits(c *gin.Context) {
+	movieID, idErr := getMovieID(c.Param("movie_id"))
+	if idErr != nil {
+		c.JSON(idErr.Status, idErr)
+
+		return
+	}
+
+	result, getErr := movies_service.MoviesService.GetMovieCredits(movieID)
+	if getErr != nil {
+		c.JSON(getErr.Status, getErr)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func GetImages(c *gin.Context) {
+	movieID, idErr := getMovieID(c.Param("movie_id"))
+	if idErr != nil {
+		c.JSON(idErr.Status, idErr)
+
+		return
+	}
+
+	result, getErr := movies_service.MoviesService.GetMovieImages(movieID)
+	if getErr != nil {
+		c.JSON(getErr.Status, getErr)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -100,3 +100,77 @@ func TestSearchFail(t *testing.T) {
 	assert.EqualValues(t, "Failed to search for movies", result.Message)
 	assert.EqualValues(t, "internal_server_error", result.Err)
 }
+
+func TestGetCreditsSuccess(t *testing.T) {
+	w := PrepareTest(make([]byte, 0), "GET")
+
+	c.Params = append(c.Params, gin.Param{Key: "movie_id", Value: "550"})
+
+	GetCredits(c)
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var result tmdb.MovieCredits
+	err := json.Unmarshal(responseData, &result)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusOK, w.Code)
+	assert.EqualValues(t, 550, result.ID)
+}
+
+func TestGetCreditsInvalidMovieID(t *testing.T) {
+	w := PrepareTest(make([]byte, 0), "GET")
+
+	c.Params = append(c.Params, gin.Param{Key: "movie_id", Value: "abc"})
+
+	GetCredits(c)
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var result rest_errors.RestErr
+	err := json.Unmarshal(responseData, &result)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, w.Code)
+	assert.EqualValues(t, "Movie ID should be a number", result.Message)
+	assert.EqualValues(t, "bad_request", result.Err)
+}
+
+func TestGetImagesSuccess(t *testing.T) {
+	w := PrepareTest(make([]byte, 0), "GET")
+
+	c.Params = append(c.Params, gin.Param{Key: "movie_id", Value: "550"})
+
+	GetImages(c)
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var result tmdb.MovieImages
+	err := json.Unmarshal(responseData, &result)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusOK, w.Code)
+	assert.EqualValues(t, 550, result.ID)
+}
+
+func TestGetImagesFail(t *testing.T) {
+	w := PrepareTest(make([]byte, 0), "GET")
+
+	c.Params = append(c.Params, gin.Param{Key: "movie_id", Value: "550"})
+
+	movies_service.MoviesService.(*movies_service_mock.MoviesServiceMock).CanGetMovie = false
+
+	GetImages(c)
+
+	movies_service.MoviesService.(*movies_service_mock.MoviesServiceMock).CanGetMovie = true
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var result rest_errors.RestErr
+	err := json.Unmarshal(responseData, &result)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusInternalServerError, w.Code)
+	assert.EqualValues(t, "Failed to get movie images", result.Message)
+	assert.EqualValues(t, "internal_server_error", result.Err)
+}
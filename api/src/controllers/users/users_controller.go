@@ -1,41 +1,78 @@
 package users
 
 import (
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/ericbg27/top10movies-api/src/domain/movies"
 	"github.com/ericbg27/top10movies-api/src/domain/user_favorites"
+	"github.com/ericbg27/top10movies-api/src/domain/user_follows"
+	"github.com/ericbg27/top10movies-api/src/domain/user_profiles"
 	"github.com/ericbg27/top10movies-api/src/domain/users"
 	movies_service "github.com/ericbg27/top10movies-api/src/services/movies"
 	users_service "github.com/ericbg27/top10movies-api/src/services/users"
 	"github.com/ericbg27/top10movies-api/src/utils/authorization"
 	"github.com/ericbg27/top10movies-api/src/utils/logger"
+	"github.com/ericbg27/top10movies-api/src/utils/loginguard"
 	"github.com/ericbg27/top10movies-api/src/utils/rest_errors"
 	"github.com/gin-gonic/gin"
+	"github.com/ryanbradynd05/go-tmdb"
 	"golang.org/x/crypto/bcrypt"
 )
 
 const (
 	layoutISO = "2006-01-02"
+
+	defaultListLimit = 20
+	maxListLimit     = 100
 )
 
 type usersController struct{}
 
 type UsersControllerInterface interface {
 	Login(c *gin.Context)
+	RefreshToken(c *gin.Context)
+	Logout(c *gin.Context)
+	RevokeAllSessions(c *gin.Context)
+	GetSessions(c *gin.Context)
 	Create(c *gin.Context)
+	Verify(c *gin.Context)
+	ResendVerification(c *gin.Context)
+	ChangePassword(c *gin.Context)
+	ForgotPassword(c *gin.Context)
+	ResetPassword(c *gin.Context)
 	Update(c *gin.Context)
 	Delete(c *gin.Context)
 	GetFavorites(c *gin.Context)
+	UpdateFavoritesVisibility(c *gin.Context)
+	GetProfile(c *gin.Context)
 	AddFavorite(c *gin.Context)
+	ReorderFavorites(c *gin.Context)
+	RemoveFavorite(c *gin.Context)
+	ReplaceFavorite(c *gin.Context)
+	GetFeed(c *gin.Context)
+	Follow(c *gin.Context)
+	Unfollow(c *gin.Context)
+	GetFollowers(c *gin.Context)
+	GetFollowing(c *gin.Context)
 	Search(c *gin.Context)
+	ListUsers(c *gin.Context)
+	UpdateUserStatus(c *gin.Context)
+	UpdateUserRole(c *gin.Context)
+	DeleteUser(c *gin.Context)
 }
 
 var (
 	UsersController UsersControllerInterface = &usersController{}
+
+	dummyPasswordHash     []byte
+	dummyPasswordHashOnce sync.Once
 )
 
 func getID(userIDParam string) (int64, *rest_errors.RestErr) {
@@ -47,6 +84,136 @@ func getID(userIDParam string) (int64, *rest_errors.RestErr) {
 	return userID, nil
 }
 
+func getMovieID(movieIDParam string) (int, *rest_errors.RestErr) {
+	movieID, movieErr := strconv.Atoi(movieIDParam)
+	if movieErr != nil {
+		return 0, rest_errors.NewBadRequestError("Movie ID should be a number")
+	}
+
+	return movieID, nil
+}
+
+func getRank(rankParam string) (int, *rest_errors.RestErr) {
+	rank, rankErr := strconv.Atoi(rankParam)
+	if rankErr != nil || rank < 1 || rank > user_favorites.MaxFavorites {
+		return 0, rest_errors.NewBadRequestError(fmt.Sprintf("Rank should be a number between 1 and %d", user_favorites.MaxFavorites))
+	}
+
+	return rank, nil
+}
+
+func getLimit(limitParam string) (int, *rest_errors.RestErr) {
+	if limitParam == "" {
+		return defaultListLimit, nil
+	}
+
+	limit, limitErr := strconv.Atoi(limitParam)
+	if limitErr != nil || limit < 1 || limit > maxListLimit {
+		return 0, rest_errors.NewBadRequestError(fmt.Sprintf("Limit should be a number between 1 and %d", maxListLimit))
+	}
+
+	return limit, nil
+}
+
+func getPagination(limitParam string, offsetParam string) (int, int, *rest_errors.RestErr) {
+	limit, limitErr := getLimit(limitParam)
+	if limitErr != nil {
+		return 0, 0, limitErr
+	}
+
+	offset := 0
+	if offsetParam != "" {
+		parsedOffset, offsetErr := strconv.Atoi(offsetParam)
+		if offsetErr != nil || parsedOffset < 0 {
+			return 0, 0, rest_errors.NewBadRequestError("Offset should be a non-negative number")
+		}
+
+		offset = parsedOffset
+	}
+
+	return limit, offset, nil
+}
+
+func getTargetUserID(c *gin.Context) (int64, *rest_errors.RestErr) {
+	userID, idErr := getID(c.Param("user_id"))
+	if idErr != nil {
+		return 0, idErr
+	}
+
+	if userID == authorization.GetUserID(c) {
+		return 0, rest_errors.NewBadRequestError("Administrative actions cannot target your own account")
+	}
+
+	return userID, nil
+}
+
+// compareDummyPassword spends the same time as a real password check so unknown emails
+// cannot be told apart from wrong passwords by timing the response
+func compareDummyPassword(password string) {
+	dummyPasswordHashOnce.Do(func() {
+		hashedPass, hashErr := users.HashPassword("top10movies-dummy-password")
+		if hashErr == nil {
+			dummyPasswordHash = []byte(hashedPass)
+		}
+	})
+
+	bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+}
+
+// registerLoginFailure records the failed attempt and returns the error used for every kind
+// of bad credentials, so the response does not reveal whether the email exists
+func registerLoginFailure(email string, ip string, reason string) *rest_errors.RestErr {
+	if _, err := loginguard.LoginGuard.RegisterFailure(email, ip, reason); err != nil {
+		logger.Error("Could not register failed login attempt", err)
+	}
+
+	return rest_errors.NewUnauthorizedError("Invalid email or password")
+}
+
+// rehashPassword upgrades a stored hash to the configured bcrypt cost. Failures are only
+// logged since the user already proved the password is right.
+func rehashPassword(savedUser users.User, password string) {
+	hashedPass, hashErr := users.HashPassword(password)
+	if hashErr != nil {
+		return
+	}
+
+	savedUser.Password = hashedPass
+
+	if updateErr := users_service.UsersService.UpdateUserPassword(savedUser); updateErr != nil {
+		logger.Error("Could not rehash user password", updateErr)
+	}
+}
+
+// checkRefreshTokenOwner refuses to rotate tokens of accounts that are no longer
+// active, revoking every remaining session of the account on the way out.
+func checkRefreshTokenOwner(refreshToken string) *rest_errors.RestErr {
+	userID, err := authorization.AuthManager.RefreshTokenUserID(refreshToken)
+	if err != nil {
+		return rest_errors.NewUnauthorizedError("Invalid refresh token")
+	}
+
+	result, getErr := users_service.UsersService.GetUserById(users.User{ID: userID})
+	if getErr != nil {
+		if getErr.Status == http.StatusNotFound {
+			return rest_errors.NewUnauthorizedError("Invalid refresh token")
+		}
+
+		return getErr
+	}
+
+	savedUser := result.(users.User)
+	if savedUser.Status == users.StatusActive {
+		return nil
+	}
+
+	if err := authorization.AuthManager.RevokeAllSessions(userID); err != nil {
+		logger.Error("Could not revoke inactive user sessions", err)
+	}
+
+	return rest_errors.NewForbiddenError(fmt.Sprintf("User account is %s", savedUser.Status))
+}
+
 func (u *usersController) Login(c *gin.Context) {
 	var user users.User
 	if err := c.ShouldBindJSON(&user); err != nil {
@@ -56,9 +223,33 @@ func (u *usersController) Login(c *gin.Context) {
 		return
 	}
 
+	ip := c.ClientIP()
+
+	lockout, guardErr := loginguard.LoginGuard.Check(user.Email, ip)
+	if guardErr != nil {
+		logger.Error("Could not check failed login attempts", guardErr)
+	}
+
+	if lockout > 0 {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(lockout.Seconds()))))
+		lockoutErr := rest_errors.NewTooManyRequestsError("Too many failed login attempts, try again later")
+		c.JSON(lockoutErr.Status, lockoutErr)
+
+		return
+	}
+
 	result, getErr := users_service.UsersService.GetUser(user)
 	if getErr != nil {
-		c.JSON(getErr.Status, getErr)
+		if getErr.Status != http.StatusNotFound {
+			c.JSON(getErr.Status, getErr)
+
+			return
+		}
+
+		compareDummyPassword(user.Password)
+
+		credentialsErr := registerLoginFailure(user.Email, ip, loginguard.ReasonUnknownEmail)
+		c.JSON(credentialsErr.Status, credentialsErr)
 
 		return
 	}
@@ -67,13 +258,28 @@ func (u *usersController) Login(c *gin.Context) {
 
 	err := bcrypt.CompareHashAndPassword([]byte(savedUser.Password), []byte(user.Password))
 	if err != nil {
-		passwordErr := rest_errors.NewBadRequestError("Wrong password")
-		c.JSON(passwordErr.Status, passwordErr)
+		credentialsErr := registerLoginFailure(user.Email, ip, loginguard.ReasonWrongPassword)
+		c.JSON(credentialsErr.Status, credentialsErr)
+
+		return
+	}
+
+	if err := loginguard.LoginGuard.RegisterSuccess(user.Email); err != nil {
+		logger.Error("Could not reset failed login attempts", err)
+	}
+
+	if savedUser.Status != users.StatusActive {
+		statusErr := rest_errors.NewForbiddenError(fmt.Sprintf("User account is %s", savedUser.Status))
+		c.JSON(statusErr.Status, statusErr)
 
 		return
 	}
 
-	token, err := authorization.AuthManager.CreateToken(savedUser.ID)
+	if users.NeedsRehash(savedUser.Password) {
+		rehashPassword(savedUser, user.Password)
+	}
+
+	token, err := authorization.AuthManager.CreateToken(savedUser.ID, savedUser.Role, c.Request.UserAgent())
 	if err != nil {
 		tokenErr := rest_errors.NewInternalServerError("Could not generate jwt access token")
 		c.JSON(tokenErr.Status, tokenErr)
@@ -89,68 +295,102 @@ func (u *usersController) Login(c *gin.Context) {
 	c.JSON(http.StatusOK, tokensInfo)
 }
 
-func (u *usersController) Create(c *gin.Context) {
-	var user users.User
-	if err := c.ShouldBindJSON(&user); err != nil {
+func (u *usersController) RefreshToken(c *gin.Context) {
+	var request struct {
+		RefreshToken string `json:"refresh_token" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
 		restErr := rest_errors.NewBadRequestError("Invalid JSON body")
 		c.JSON(restErr.Status, restErr)
 
 		return
 	}
 
-	user.Status = users.StatusActive
-	user.DateCreated = time.Now().Format(layoutISO)
-
-	hashedPass, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
-	if err != nil {
-		logger.Error("Unable to hash password", err)
-		hashErr := rest_errors.NewBadRequestError("Unable to hash password")
-		c.JSON(hashErr.Status, hashErr)
+	if statusErr := checkRefreshTokenOwner(request.RefreshToken); statusErr != nil {
+		c.JSON(statusErr.Status, statusErr)
 
 		return
 	}
 
-	user.Password = string(hashedPass)
+	token, err := authorization.AuthManager.RefreshToken(request.RefreshToken)
+	if err != nil {
+		var refreshErr *rest_errors.RestErr
+
+		switch {
+		case errors.Is(err, authorization.ErrRefreshTokenReused):
+			refreshErr = rest_errors.NewUnauthorizedError("Refresh token was already used, session has been revoked")
+		case errors.Is(err, authorization.ErrInvalidRefreshToken):
+			refreshErr = rest_errors.NewUnauthorizedError("Invalid refresh token")
+		default:
+			logger.Error("Could not refresh jwt tokens", err)
+			refreshErr = rest_errors.NewInternalServerError("Could not refresh jwt tokens")
+		}
 
-	result, saveErr := users_service.UsersService.CreateUser(user)
-	if saveErr != nil {
-		c.JSON(saveErr.Status, saveErr)
+		c.JSON(refreshErr.Status, refreshErr)
 
 		return
 	}
 
-	newUser := result.(users.User)
-
-	newUser.Password = ""
+	tokensInfo := map[string]string{
+		"access_token":  token.AccessToken,
+		"refresh_token": token.RefreshToken,
+	}
 
-	c.JSON(http.StatusCreated, newUser)
+	c.JSON(http.StatusOK, tokensInfo)
 }
 
-func (u *usersController) Update(c *gin.Context) {
+func (u *usersController) Logout(c *gin.Context) {
 	bearToken := c.Request.Header.Get("Authorization")
 
-	userID, err := authorization.AuthManager.FetchAuth(bearToken)
-	if err != nil {
-		authErr := rest_errors.NewUnauthorizedError("Invalid JWT token")
-		c.JSON(authErr.Status, authErr)
+	if err := authorization.AuthManager.Logout(bearToken); err != nil {
+		var logoutErr *rest_errors.RestErr
+
+		if errors.Is(err, authorization.ErrInvalidAccessToken) {
+			logoutErr = rest_errors.NewUnauthorizedError("Invalid JWT token")
+		} else {
+			logger.Error("Could not revoke session", err)
+			logoutErr = rest_errors.NewInternalServerError("Could not revoke session")
+		}
+
+		c.JSON(logoutErr.Status, logoutErr)
 
 		return
 	}
 
-	requestUserID, IdErr := getID(c.Param("user_id"))
-	if IdErr != nil {
-		c.JSON(IdErr.Status, IdErr)
+	c.Status(http.StatusOK)
+}
+
+func (u *usersController) RevokeAllSessions(c *gin.Context) {
+	userID := authorization.GetUserID(c)
+
+	if err := authorization.AuthManager.RevokeAllSessions(userID); err != nil {
+		logger.Error("Could not revoke user sessions", err)
+		revokeErr := rest_errors.NewInternalServerError("Could not revoke user sessions")
+		c.JSON(revokeErr.Status, revokeErr)
 
 		return
 	}
 
-	if requestUserID != int64(userID) {
-		wrongIdErr := rest_errors.NewUnauthorizedError("User ID in the request does not match token user ID")
-		c.JSON(wrongIdErr.Status, wrongIdErr)
+	c.Status(http.StatusOK)
+}
+
+func (u *usersController) GetSessions(c *gin.Context) {
+	userID := authorization.GetUserID(c)
+
+	sessions, err := authorization.AuthManager.ListSessions(userID)
+	if err != nil {
+		logger.Error("Could not list user sessions", err)
+		listErr := rest_errors.NewInternalServerError("Could not list user sessions")
+		c.JSON(listErr.Status, listErr)
 
 		return
 	}
 
+	c.JSON(http.StatusOK, sessions)
+}
+
+func (u *usersController) Create(c *gin.Context) {
 	var user users.User
 	if err := c.ShouldBindJSON(&user); err != nil {
 		restErr := rest_errors.NewBadRequestError("Invalid JSON body")
@@ -159,201 +399,853 @@ func (u *usersController) Update(c *gin.Context) {
 		return
 	}
 
-	user.ID = int64(userID)
-
-	isPartial := c.Request.Method == http.MethodPatch
+	user.Status = users.StatusPending
+	user.Role = users.RoleUser
+	user.DateCreated = time.Now().Format(layoutISO)
 
-	result, updateErr := users_service.UsersService.UpdateUser(user, isPartial)
-	if updateErr != nil {
-		c.JSON(updateErr.Status, updateErr)
+	if validateErr := users.ValidatePassword(user.Password); validateErr != nil {
+		c.JSON(validateErr.Status, validateErr)
 
 		return
 	}
 
-	updatedUser := result.(users.User)
-	updatedUser.Password = ""
+	hashedPass, hashErr := users.HashPassword(user.Password)
+	if hashErr != nil {
+		c.JSON(hashErr.Status, hashErr)
 
-	c.JSON(http.StatusOK, updatedUser)
-}
+		return
+	}
 
-func (u *usersController) Delete(c *gin.Context) {
-	bearToken := c.Request.Header.Get("Authorization")
+	user.Password = hashedPass
 
-	userID, err := authorization.AuthManager.FetchAuth(bearToken)
-	if err != nil {
-		authErr := rest_errors.NewUnauthorizedError("Invalid JWT token")
-		c.JSON(authErr.Status, authErr)
+	result, saveErr := users_service.UsersService.CreateUser(user)
+	if saveErr != nil {
+		c.JSON(saveErr.Status, saveErr)
 
 		return
 	}
 
-	requestUserID, IdErr := getID(c.Param("user_id"))
-	if IdErr != nil {
-		c.JSON(IdErr.Status, IdErr)
+	newUser := result.(users.User)
 
-		return
+	if sendErr := users_service.UsersService.SendVerificationEmail(newUser); sendErr != nil {
+		logger.Error("Could not send verification email", sendErr)
 	}
 
-	if requestUserID != int64(userID) {
-		wrongIdErr := rest_errors.NewUnauthorizedError("User ID in the request does not match token user ID")
-		c.JSON(wrongIdErr.Status, wrongIdErr)
+	c.JSON(http.StatusCreated, newUser.Marshall(false))
+}
+
+func (u *usersController) Verify(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		tokenErr := rest_errors.NewBadRequestError("Verification token is required")
+		c.JSON(tokenErr.Status, tokenErr)
 
 		return
 	}
 
-	var user users.User
-	if err := c.ShouldBindJSON(&user); err != nil {
-		restErr := rest_errors.NewBadRequestError("Invalid JSON body")
-		c.JSON(restErr.Status, restErr)
+	verifyErr := users_service.UsersService.VerifyEmail(users.User{}, token)
+	if verifyErr != nil {
+		c.JSON(verifyErr.Status, verifyErr)
 
 		return
 	}
 
-	user.ID = int64(userID)
+	c.Status(http.StatusOK)
+}
+
+func (u *usersController) ResendVerification(c *gin.Context) {
+	var request struct {
+		Email string `json:"email" binding:"required"`
+	}
 
-	deleteErr := users_service.UsersService.DeleteUser(user)
-	if deleteErr != nil {
-		c.JSON(deleteErr.Status, deleteErr)
+	if err := c.ShouldBindJSON(&request); err != nil {
+		restErr := rest_errors.NewBadRequestError("Invalid JSON body")
+		c.JSON(restErr.Status, restErr)
 
 		return
 	}
 
+	// The response never tells whether the email belongs to a pending account
+	result, getErr := users_service.UsersService.GetUser(users.User{Email: request.Email})
+	if getErr == nil && result.(users.User).Status == users.StatusPending {
+		if sendErr := users_service.UsersService.SendVerificationEmail(result); sendErr != nil {
+			logger.Error("Could not send verification email", sendErr)
+		}
+	}
+
 	c.Status(http.StatusOK)
 }
 
-func (u *usersController) GetFavorites(c *gin.Context) {
-	userID, IdErr := getID(c.Param("user_id"))
-	if IdErr != nil {
-		c.JSON(IdErr.Status, IdErr)
+func (u *usersController) ChangePassword(c *gin.Context) {
+	userID := authorization.GetUserID(c)
 
-		return
+	var request struct {
+		CurrentPassword string `json:"current_password" binding:"required"`
+		NewPassword     string `json:"new_password" binding:"required"`
 	}
 
-	var usrFav user_favorites.UserFavorites
-	usrFav.UserID = userID
+	if err := c.ShouldBindJSON(&request); err != nil {
+		restErr := rest_errors.NewBadRequestError("Invalid JSON body")
+		c.JSON(restErr.Status, restErr)
 
-	userFavorites, cachedFavorites, getErr := users_service.UsersService.GetUserFavorites(usrFav)
+		return
+	}
+
+	result, getErr := users_service.UsersService.GetUserById(users.User{ID: userID})
 	if getErr != nil {
 		c.JSON(getErr.Status, getErr)
 
 		return
 	}
 
-	usrFav.MoviesData = append(usrFav.MoviesData, userFavorites.(user_favorites.UserFavorites).MoviesData...)
+	savedUser := result.(users.User)
 
-	for _, movieId := range userFavorites.(user_favorites.UserFavorites).MoviesIDs {
-		if _, cached := cachedFavorites[movieId]; !cached {
-			var movie movies.MovieInfo
-			movie.Movie.ID = movieId
+	if err := bcrypt.CompareHashAndPassword([]byte(savedUser.Password), []byte(request.CurrentPassword)); err != nil {
+		passwordErr := rest_errors.NewBadRequestError("Wrong password")
+		c.JSON(passwordErr.Status, passwordErr)
 
-			movieResult, err := movies_service.MoviesService.GetMovieById(movie.Movie.ID)
-			if err != nil { // TODO: Do we return an error if one of the favorites is not found?
-				c.JSON(err.Status, err)
+		return
+	}
 
-				return
-			}
+	if validateErr := users.ValidatePassword(request.NewPassword); validateErr != nil {
+		c.JSON(validateErr.Status, validateErr)
 
-			movie.Movie = *movieResult
-			addErr := movies_service.MoviesService.AddMovie(movie)
-			if addErr != nil { // TODO: Do we return an error if we fail to save in cache? Maybe just log!
-				c.JSON(addErr.Status, addErr)
+		return
+	}
 
-				return
-			}
+	hashedPass, hashErr := users.HashPassword(request.NewPassword)
+	if hashErr != nil {
+		c.JSON(hashErr.Status, hashErr)
 
-			usrFav.MoviesData = append(usrFav.MoviesData, *movieResult)
-		}
+		return
 	}
 
-	c.JSON(http.StatusOK, usrFav)
-}
-
-func (u *usersController) AddFavorite(c *gin.Context) {
-	bearToken := c.Request.Header.Get("Authorization")
+	savedUser.Password = hashedPass
 
-	userID, err := authorization.AuthManager.FetchAuth(bearToken)
-	if err != nil {
-		authErr := rest_errors.NewUnauthorizedError("Invalid JWT token")
-		c.JSON(authErr.Status, authErr)
+	updateErr := users_service.UsersService.UpdateUserPassword(savedUser)
+	if updateErr != nil {
+		c.JSON(updateErr.Status, updateErr)
 
 		return
 	}
 
-	requestUserID, IdErr := getID(c.Param("user_id"))
-	if IdErr != nil {
-		c.JSON(IdErr.Status, IdErr)
+	if err := authorization.AuthManager.RevokeAllSessions(userID); err != nil {
+		logger.Error("Could not revoke user sessions after password change", err)
+	}
 
-		return
+	c.Status(http.StatusOK)
+}
+
+func (u *usersController) ForgotPassword(c *gin.Context) {
+	var request struct {
+		Email string `json:"email" binding:"required"`
 	}
 
-	if requestUserID != int64(userID) {
-		wrongIdErr := rest_errors.NewUnauthorizedError("User ID in the request does not match token user ID")
-		c.JSON(wrongIdErr.Status, wrongIdErr)
+	if err := c.ShouldBindJSON(&request); err != nil {
+		restErr := rest_errors.NewBadRequestError("Invalid JSON body")
+		c.JSON(restErr.Status, restErr)
 
 		return
 	}
 
-	var movie movies.MovieInfo
+	// The response never tells whether the email belongs to an account
+	result, getErr := users_service.UsersService.GetUser(users.User{Email: request.Email})
+	if getErr == nil {
+		status := result.(users.User).Status
+		if status == users.StatusActive || status == users.StatusPending {
+			if sendErr := users_service.UsersService.SendPasswordResetEmail(result); sendErr != nil {
+				logger.Error("Could not send password reset email", sendErr)
+			}
+		}
+	}
 
-	if err := c.ShouldBindJSON(&movie); err != nil {
+	c.Status(http.StatusOK)
+}
+
+func (u *usersController) ResetPassword(c *gin.Context) {
+	var request struct {
+		Token       string `json:"token" binding:"required"`
+		NewPassword string `json:"new_password" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
 		restErr := rest_errors.NewBadRequestError("Invalid JSON body")
 		c.JSON(restErr.Status, restErr)
 
 		return
 	}
 
-	movieCacheResult, cacheErr := movies_service.MoviesService.GetMovieFromCache(movie)
-	if cacheErr != nil {
-		c.JSON(cacheErr.Status, cacheErr)
+	if validateErr := users.ValidatePassword(request.NewPassword); validateErr != nil {
+		c.JSON(validateErr.Status, validateErr)
 
 		return
 	}
 
-	movieCache := movieCacheResult.(movies.MovieInfo)
-	if movieCache.Movie.ID == -1 { // Movie is not cached
-		addErr := movies_service.MoviesService.AddMovie(movie)
-		if addErr != nil { // TODO: Do we return an error if we fail to save in cache? Maybe just log!
-			c.JSON(addErr.Status, addErr)
+	hashedPass, hashErr := users.HashPassword(request.NewPassword)
+	if hashErr != nil {
+		c.JSON(hashErr.Status, hashErr)
 
-			return
-		}
+		return
 	}
 
-	var userFavorite user_favorites.UserFavorites
-	userFavorite.UserID = int64(userID)
-	userFavorite.MoviesIDs = append(userFavorite.MoviesIDs, movie.Movie.ID)
-
-	addErr := users_service.UsersService.AddUserFavorite(userFavorite)
-	if addErr != nil {
-		c.JSON(addErr.Status, addErr)
+	result, resetErr := users_service.UsersService.ResetPassword(users.User{Password: hashedPass}, request.Token)
+	if resetErr != nil {
+		c.JSON(resetErr.Status, resetErr)
 
 		return
 	}
 
+	userID := result.(users.User).ID
+
+	if err := authorization.AuthManager.RevokeAllSessions(userID); err != nil {
+		logger.Error("Could not revoke user sessions after password reset", err)
+	}
+
 	c.Status(http.StatusOK)
 }
 
-func (u *usersController) Search(c *gin.Context) {
-	queryParams := make(map[string]string)
+func (u *usersController) Update(c *gin.Context) {
+	userID := authorization.GetUserID(c)
 
-	for queryKey, queryVal := range c.Request.URL.Query() {
-		queryParams[queryKey] = queryVal[0]
+	var request struct {
+		FirstName string                     `json:"first_name"`
+		LastName  string                     `json:"last_name"`
+		Email     string                     `json:"email"`
+		Profile   *user_profiles.UserProfile `json:"profile"`
 	}
 
-	queryParams[users_service.QueryParam] = strings.ReplaceAll(queryParams[users_service.QueryParam], "+", " ")
+	if err := c.ShouldBindJSON(&request); err != nil {
+		restErr := rest_errors.NewBadRequestError("Invalid JSON body")
+		c.JSON(restErr.Status, restErr)
 
-	queryArray := strings.Split(queryParams[users_service.QueryParam], " ")
+		return
+	}
 
-	var userToSearch users.User
-	userToSearch.FirstName = queryArray[0]
-	userToSearch.LastName = strings.Join(queryArray[1:], " ")
+	user := users.User{
+		ID:        userID,
+		FirstName: request.FirstName,
+		LastName:  request.LastName,
+		Email:     request.Email,
+	}
 
-	foundUsers, searchErr := users_service.UsersService.SearchUser(userToSearch)
-	if searchErr != nil {
-		c.JSON(searchErr.Status, searchErr)
+	isPartial := c.Request.Method == http.MethodPatch
+
+	var result users.UserInterface
+	var updatedProfile user_profiles.UserProfileInterface
+	var updateErr *rest_errors.RestErr
+
+	// The profile is only touched when the request carries one, and then together with the user
+	if request.Profile != nil {
+		request.Profile.UserID = userID
+
+		result, updatedProfile, updateErr = users_service.UsersService.UpdateUserWithProfile(user, *request.Profile, isPartial)
+	} else {
+		result, updateErr = users_service.UsersService.UpdateUser(user, isPartial)
+	}
+
+	if updateErr != nil {
+		c.JSON(updateErr.Status, updateErr)
 
 		return
 	}
 
-	c.JSON(http.StatusOK, foundUsers)
+	var response struct {
+		users.PrivateUser
+		Profile user_profiles.UserProfileInterface `json:"profile,omitempty"`
+	}
+	response.PrivateUser = result.Marshall(false).(users.PrivateUser)
+	response.Profile = updatedProfile
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (u *usersController) Delete(c *gin.Context) {
+	userID := authorization.GetUserID(c)
+
+	var user users.User
+	if err := c.ShouldBindJSON(&user); err != nil {
+		restErr := rest_errors.NewBadRequestError("Invalid JSON body")
+		c.JSON(restErr.Status, restErr)
+
+		return
+	}
+
+	user.ID = userID
+
+	deleteErr := users_service.UsersService.DeleteUser(user)
+	if deleteErr != nil {
+		c.JSON(deleteErr.Status, deleteErr)
+
+		return
+	}
+
+	if err := authorization.AuthManager.RevokeAllSessions(userID); err != nil {
+		logger.Error("Could not revoke deleted user sessions", err)
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// loadFavorites returns the ranked favorites of a user with the movie data, taken from the cache
+// when possible. Movies that could not be fetched are reported in Errors. The list is only returned
+// when the requester, or the share_token query parameter, is allowed to see it.
+func loadFavorites(c *gin.Context, userID int64) (user_favorites.UserFavorites, *rest_errors.RestErr) {
+	var usrFav user_favorites.UserFavorites
+	usrFav.UserID = userID
+
+	owner := users.User{ID: userID}
+	viewerID := authorization.GetUserID(c)
+	shareToken := c.Query("share_token")
+
+	userFavorites, cachedFavorites, getErr := users_service.UsersService.GetUserFavorites(owner, usrFav, viewerID, shareToken)
+	if getErr != nil {
+		return usrFav, getErr
+	}
+
+	cachedMovies := make(map[int]tmdb.Movie)
+	for _, cachedMovie := range userFavorites.(user_favorites.UserFavorites).MoviesData {
+		cachedMovies[cachedMovie.ID] = cachedMovie
+	}
+
+	usrFav.MoviesIDs = userFavorites.(user_favorites.UserFavorites).MoviesIDs
+
+	var uncachedIds []int
+	for _, movieId := range usrFav.MoviesIDs {
+		if _, cached := cachedFavorites[movieId]; !cached {
+			uncachedIds = append(uncachedIds, movieId)
+		}
+	}
+
+	fetchedMovies, fetchErrors := movies_service.MoviesService.GetMoviesByIds(c.Request.Context(), uncachedIds)
+
+	for _, movieId := range usrFav.MoviesIDs {
+		if _, cached := cachedFavorites[movieId]; cached {
+			usrFav.MoviesData = append(usrFav.MoviesData, cachedMovies[movieId])
+
+			continue
+		}
+
+		if movie, fetched := fetchedMovies[movieId]; fetched {
+			usrFav.MoviesData = append(usrFav.MoviesData, movie)
+
+			continue
+		}
+
+		usrFav.Errors = append(usrFav.Errors, user_favorites.FavoriteError{
+			MovieID: movieId,
+			Error:   fetchErrors[movieId],
+		})
+	}
+
+	return usrFav, nil
+}
+
+func (u *usersController) GetFavorites(c *gin.Context) {
+	userID, IdErr := getID(c.Param("user_id"))
+	if IdErr != nil {
+		c.JSON(IdErr.Status, IdErr)
+
+		return
+	}
+
+	usrFav, getErr := loadFavorites(c, userID)
+	if getErr != nil {
+		c.JSON(getErr.Status, getErr)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, usrFav)
+}
+
+func (u *usersController) UpdateFavoritesVisibility(c *gin.Context) {
+	userID := authorization.GetUserID(c)
+
+	var request struct {
+		Visibility string `json:"visibility" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		restErr := rest_errors.NewBadRequestError("Invalid JSON body")
+		c.JSON(restErr.Status, restErr)
+
+		return
+	}
+
+	if !users.IsValidFavoritesVisibility(request.Visibility) {
+		visibilityErr := rest_errors.NewBadRequestError(fmt.Sprintf("Visibility should be one of: %s", strings.Join(users.FavoritesVisibilities, ", ")))
+		c.JSON(visibilityErr.Status, visibilityErr)
+
+		return
+	}
+
+	var user users.User
+	user.ID = userID
+	user.FavoritesVisibility = request.Visibility
+
+	result, updateErr := users_service.UsersService.UpdateFavoritesVisibility(user)
+	if updateErr != nil {
+		c.JSON(updateErr.Status, updateErr)
+
+		return
+	}
+
+	updatedUser := result.(users.User)
+
+	visibilityInfo := map[string]string{
+		"favorites_visibility":  updatedUser.FavoritesVisibility,
+		"favorites_share_token": updatedUser.FavoritesShareToken,
+	}
+
+	c.JSON(http.StatusOK, visibilityInfo)
+}
+
+func (u *usersController) GetProfile(c *gin.Context) {
+	// The route shares its wildcard with the /users/:user_id routes, here it holds the handle
+	handle := c.Param("user_id")
+
+	result, getErr := users_service.UsersService.GetProfileByHandle(user_profiles.UserProfile{Handle: handle})
+	if getErr != nil {
+		c.JSON(getErr.Status, getErr)
+
+		return
+	}
+
+	profile := result.(user_profiles.UserProfile)
+
+	publicProfile := user_profiles.PublicProfile{
+		UserProfile: profile,
+	}
+
+	// A hidden favorites list leaves the rest of the profile visible
+	favorites, favoritesErr := loadFavorites(c, profile.UserID)
+	if favoritesErr != nil && favoritesErr.Status != http.StatusNotFound {
+		c.JSON(favoritesErr.Status, favoritesErr)
+
+		return
+	}
+
+	if favoritesErr == nil {
+		publicProfile.Favorites = &favorites
+	}
+
+	c.JSON(http.StatusOK, publicProfile)
+}
+
+// bindFavoriteMovie reads the movie of a favorite request and makes sure it is cached
+func bindFavoriteMovie(c *gin.Context) (movies.MovieInfo, *rest_errors.RestErr) {
+	var movie movies.MovieInfo
+
+	if err := c.ShouldBindJSON(&movie); err != nil {
+		return movie, rest_errors.NewBadRequestError("Invalid JSON body")
+	}
+
+	movieCacheResult, cacheErr := movies_service.MoviesService.GetMovieFromCache(movie)
+	if cacheErr != nil {
+		return movie, cacheErr
+	}
+
+	movieCache := movieCacheResult.(movies.MovieInfo)
+	if movieCache.Movie.ID == -1 { // Movie is not cached
+		if addErr := movies_service.MoviesService.AddMovie(movie); addErr != nil { // TODO: Do we return an error if we fail to save in cache? Maybe just log!
+			return movie, addErr
+		}
+	}
+
+	return movie, nil
+}
+
+func (u *usersController) AddFavorite(c *gin.Context) {
+	userID := authorization.GetUserID(c)
+
+	movie, movieErr := bindFavoriteMovie(c)
+	if movieErr != nil {
+		c.JSON(movieErr.Status, movieErr)
+
+		return
+	}
+
+	var userFavorite user_favorites.UserFavorites
+	userFavorite.UserID = userID
+	userFavorite.MoviesIDs = append(userFavorite.MoviesIDs, movie.Movie.ID)
+
+	addErr := users_service.UsersService.AddUserFavorite(userFavorite)
+	if addErr != nil {
+		c.JSON(addErr.Status, addErr)
+
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (u *usersController) ReorderFavorites(c *gin.Context) {
+	userID := authorization.GetUserID(c)
+
+	var userFavorites user_favorites.UserFavorites
+	if err := c.ShouldBindJSON(&userFavorites); err != nil {
+		restErr := rest_errors.NewBadRequestError("Invalid JSON body")
+		c.JSON(restErr.Status, restErr)
+
+		return
+	}
+
+	userFavorites.UserID = userID
+
+	reorderErr := users_service.UsersService.ReorderUserFavorites(userFavorites)
+	if reorderErr != nil {
+		c.JSON(reorderErr.Status, reorderErr)
+
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (u *usersController) RemoveFavorite(c *gin.Context) {
+	userID := authorization.GetUserID(c)
+
+	movieID, movieIdErr := getMovieID(c.Param("movie_id"))
+	if movieIdErr != nil {
+		c.JSON(movieIdErr.Status, movieIdErr)
+
+		return
+	}
+
+	var userFavorite user_favorites.UserFavorites
+	userFavorite.UserID = userID
+	userFavorite.MoviesIDs = append(userFavorite.MoviesIDs, movieID)
+
+	removeErr := users_service.UsersService.RemoveUserFavorite(userFavorite)
+	if removeErr != nil {
+		c.JSON(removeErr.Status, removeErr)
+
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (u *usersController) ReplaceFavorite(c *gin.Context) {
+	userID := authorization.GetUserID(c)
+
+	rank, rankErr := getRank(c.Param("rank"))
+	if rankErr != nil {
+		c.JSON(rankErr.Status, rankErr)
+
+		return
+	}
+
+	movie, movieErr := bindFavoriteMovie(c)
+	if movieErr != nil {
+		c.JSON(movieErr.Status, movieErr)
+
+		return
+	}
+
+	var userFavorite user_favorites.UserFavorites
+	userFavorite.UserID = userID
+	userFavorite.MoviesIDs = append(userFavorite.MoviesIDs, movie.Movie.ID)
+
+	replaceErr := users_service.UsersService.ReplaceUserFavorite(userFavorite, rank)
+	if replaceErr != nil {
+		c.JSON(replaceErr.Status, replaceErr)
+
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (u *usersController) GetFeed(c *gin.Context) {
+	userID := authorization.GetUserID(c)
+
+	limit, limitErr := getLimit(c.Query("limit"))
+	if limitErr != nil {
+		c.JSON(limitErr.Status, limitErr)
+
+		return
+	}
+
+	feed, feedErr := users_service.UsersService.GetFeed(user_favorites.UserFavorites{UserID: userID}, limit, c.Query("cursor"))
+	if feedErr != nil {
+		c.JSON(feedErr.Status, feedErr)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, feed)
+}
+
+// getFollow builds the follow between the authenticated user and the user in the URL
+func getFollow(c *gin.Context) (user_follows.UserFollow, *rest_errors.RestErr) {
+	followedID, idErr := getID(c.Param("user_id"))
+	if idErr != nil {
+		return user_follows.UserFollow{}, idErr
+	}
+
+	return user_follows.UserFollow{
+		FollowerID: authorization.GetUserID(c),
+		FollowedID: followedID,
+	}, nil
+}
+
+func (u *usersController) Follow(c *gin.Context) {
+	follow, followErr := getFollow(c)
+	if followErr != nil {
+		c.JSON(followErr.Status, followErr)
+
+		return
+	}
+
+	if err := users_service.UsersService.FollowUser(follow); err != nil {
+		c.JSON(err.Status, err)
+
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (u *usersController) Unfollow(c *gin.Context) {
+	follow, followErr := getFollow(c)
+	if followErr != nil {
+		c.JSON(followErr.Status, followErr)
+
+		return
+	}
+
+	if err := users_service.UsersService.UnfollowUser(follow); err != nil {
+		c.JSON(err.Status, err)
+
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (u *usersController) GetFollowers(c *gin.Context) {
+	userID, idErr := getID(c.Param("user_id"))
+	if idErr != nil {
+		c.JSON(idErr.Status, idErr)
+
+		return
+	}
+
+	limit, limitErr := getLimit(c.Query("limit"))
+	if limitErr != nil {
+		c.JSON(limitErr.Status, limitErr)
+
+		return
+	}
+
+	followers, listErr := users_service.UsersService.GetFollowers(user_follows.UserFollow{FollowedID: userID}, limit, c.Query("cursor"))
+	if listErr != nil {
+		c.JSON(listErr.Status, listErr)
+
+		return
+	}
+
+	followers.Items = users.MarshallUsers(followers.Items.([]users.UserInterface), true)
+
+	c.JSON(http.StatusOK, followers)
+}
+
+func (u *usersController) GetFollowing(c *gin.Context) {
+	userID, idErr := getID(c.Param("user_id"))
+	if idErr != nil {
+		c.JSON(idErr.Status, idErr)
+
+		return
+	}
+
+	limit, limitErr := getLimit(c.Query("limit"))
+	if limitErr != nil {
+		c.JSON(limitErr.Status, limitErr)
+
+		return
+	}
+
+	following, listErr := users_service.UsersService.GetFollowing(user_follows.UserFollow{FollowerID: userID}, limit, c.Query("cursor"))
+	if listErr != nil {
+		c.JSON(listErr.Status, listErr)
+
+		return
+	}
+
+	following.Items = users.MarshallUsers(following.Items.([]users.UserInterface), true)
+
+	c.JSON(http.StatusOK, following)
+}
+
+func (u *usersController) Search(c *gin.Context) {
+	sort := c.DefaultQuery("sort", users.SortByName)
+	if !users.IsValidSort(sort) {
+		sortErr := rest_errors.NewBadRequestError(fmt.Sprintf("Sort should be one of: %s", strings.Join(users.SortOptions, ", ")))
+		c.JSON(sortErr.Status, sortErr)
+
+		return
+	}
+
+	limit, limitErr := getLimit(c.Query("limit"))
+	if limitErr != nil {
+		c.JSON(limitErr.Status, limitErr)
+
+		return
+	}
+
+	isAdmin := authorization.GetUserRole(c) == users.RoleAdmin
+
+	criteria := users.SearchCriteria{
+		Query:        c.Query(users_service.QueryParam),
+		IncludeEmail: isAdmin,
+		Sort:         sort,
+		Limit:        limit,
+		Cursor:       c.Query("cursor"),
+	}
+
+	foundUsers, searchErr := users_service.UsersService.SearchUser(users.User{}, criteria)
+	if searchErr != nil {
+		c.JSON(searchErr.Status, searchErr)
+
+		return
+	}
+
+	// Only administrators search by email, everyone else gets display data only
+	foundUsers.Items = users.MarshallUsers(foundUsers.Items.([]users.UserInterface), !isAdmin)
+
+	c.JSON(http.StatusOK, foundUsers)
+}
+
+func (u *usersController) ListUsers(c *gin.Context) {
+	limit, offset, pageErr := getPagination(c.Query("limit"), c.Query("offset"))
+	if pageErr != nil {
+		c.JSON(pageErr.Status, pageErr)
+
+		return
+	}
+
+	listedUsers, listErr := users_service.UsersService.ListUsers(users.User{}, limit, offset)
+	if listErr != nil {
+		c.JSON(listErr.Status, listErr)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, users.MarshallUsers(listedUsers, false))
+}
+
+func (u *usersController) UpdateUserStatus(c *gin.Context) {
+	userID, idErr := getTargetUserID(c)
+	if idErr != nil {
+		c.JSON(idErr.Status, idErr)
+
+		return
+	}
+
+	var request struct {
+		Status string `json:"status" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		restErr := rest_errors.NewBadRequestError("Invalid JSON body")
+		c.JSON(restErr.Status, restErr)
+
+		return
+	}
+
+	if !users.IsValidStatus(request.Status) {
+		statusErr := rest_errors.NewBadRequestError(fmt.Sprintf("Status should be one of: %s, %s, %s, %s", users.StatusPending, users.StatusActive, users.StatusSuspended, users.StatusDeleted))
+		c.JSON(statusErr.Status, statusErr)
+
+		return
+	}
+
+	var user users.User
+	user.ID = userID
+
+	updateErr := users_service.UsersService.UpdateUserStatus(user, request.Status)
+	if updateErr != nil {
+		c.JSON(updateErr.Status, updateErr)
+
+		return
+	}
+
+	if request.Status != users.StatusActive {
+		if err := authorization.AuthManager.RevokeAllSessions(userID); err != nil {
+			logger.Error("Could not revoke inactive user sessions", err)
+		}
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (u *usersController) UpdateUserRole(c *gin.Context) {
+	userID, idErr := getTargetUserID(c)
+	if idErr != nil {
+		c.JSON(idErr.Status, idErr)
+
+		return
+	}
+
+	var request struct {
+		Role string `json:"role" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		restErr := rest_errors.NewBadRequestError("Invalid JSON body")
+		c.JSON(restErr.Status, restErr)
+
+		return
+	}
+
+	if !users.IsValidRole(request.Role) {
+		roleErr := rest_errors.NewBadRequestError(fmt.Sprintf("Role should be one of: %s, %s, %s", users.RoleUser, users.RoleModerator, users.RoleAdmin))
+		c.JSON(roleErr.Status, roleErr)
+
+		return
+	}
+
+	var user users.User
+	user.ID = userID
+	user.Role = request.Role
+
+	updateErr := users_service.UsersService.UpdateUserRole(user)
+	if updateErr != nil {
+		c.JSON(updateErr.Status, updateErr)
+
+		return
+	}
+
+	// Roles are embedded in the access tokens, so the user has to log in again for the new role to apply
+	if err := authorization.AuthManager.RevokeAllSessions(userID); err != nil {
+		logger.Error("Could not revoke user sessions after role change", err)
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (u *usersController) DeleteUser(c *gin.Context) {
+	userID, idErr := getTargetUserID(c)
+	if idErr != nil {
+		c.JSON(idErr.Status, idErr)
+
+		return
+	}
+
+	var user users.User
+	user.ID = userID
+
+	deleteErr := users_service.UsersService.DeleteUser(user)
+	if deleteErr != nil {
+		c.JSON(deleteErr.Status, deleteErr)
+
+		return
+	}
+
+	if err := authorization.AuthManager.RevokeAllSessions(userID); err != nil {
+		logger.Error("Could not revoke deleted user sessions", err)
+	}
+
+	c.Status(http.StatusOK)
 }
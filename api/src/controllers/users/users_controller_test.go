@@ -6,19 +6,27 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"testing"
 	"time"
 
+	"github.com/ericbg27/top10movies-api/src/datasources/database"
+	memorydb "github.com/ericbg27/top10movies-api/src/datasources/memory"
 	"github.com/ericbg27/top10movies-api/src/domain/movies"
 	"github.com/ericbg27/top10movies-api/src/domain/user_favorites"
+	"github.com/ericbg27/top10movies-api/src/domain/user_follows"
+	"github.com/ericbg27/top10movies-api/src/domain/user_profiles"
 	"github.com/ericbg27/top10movies-api/src/domain/users"
 	authorization_mock "github.com/ericbg27/top10movies-api/src/mocks/authorization"
+	loginguard_mock "github.com/ericbg27/top10movies-api/src/mocks/loginguard"
 	movies_service_mock "github.com/ericbg27/top10movies-api/src/mocks/services/movies"
 	users_service_mock "github.com/ericbg27/top10movies-api/src/mocks/services/users"
 	movies_service "github.com/ericbg27/top10movies-api/src/services/movies"
 	users_service "github.com/ericbg27/top10movies-api/src/services/users"
 	"github.com/ericbg27/top10movies-api/src/utils/authorization"
+	"github.com/ericbg27/top10movies-api/src/utils/config"
+	"github.com/ericbg27/top10movies-api/src/utils/loginguard"
 	"github.com/ericbg27/top10movies-api/src/utils/rest_errors"
 	"github.com/gin-gonic/gin"
 	"github.com/ryanbradynd05/go-tmdb"
@@ -28,8 +36,26 @@ import (
 
 var (
 	c *gin.Context
+
+	// realUsersService is captured before TestMain replaces it with the mock
+	realUsersService = users_service.UsersService
 )
 
+// runWithMemoryDB runs fn against the real users service backed by a fresh in-memory database
+func runWithMemoryDB(fn func(db database.DatabaseClient)) {
+	db := &memorydb.MemoryDBClient{}
+	db.SetupDbConnection()
+
+	realUsersService.SetupDBClient(db)
+
+	mockService := users_service.UsersService
+	users_service.UsersService = realUsersService
+
+	fn(db)
+
+	users_service.UsersService = mockService
+}
+
 func PrepareTest(request []byte, method string) *httptest.ResponseRecorder {
 	w := httptest.NewRecorder()
 	c, _ = gin.CreateTestContext(w)
@@ -45,6 +71,34 @@ func PrepareTest(request []byte, method string) *httptest.ResponseRecorder {
 	return w
 }
 
+func runAsOwner(handler gin.HandlerFunc) {
+	for _, middleware := range []gin.HandlerFunc{authorization.Authenticate(), authorization.RequireOwner("user_id")} {
+		if middleware(c); c.IsAborted() {
+			return
+		}
+	}
+
+	handler(c)
+}
+
+func runAuthenticated(handler gin.HandlerFunc) {
+	if authorization.Authenticate()(c); c.IsAborted() {
+		return
+	}
+
+	handler(c)
+}
+
+func runWithRole(handler gin.HandlerFunc, roles ...string) {
+	for _, middleware := range []gin.HandlerFunc{authorization.Authenticate(), authorization.RequireRole(roles...)} {
+		if middleware(c); c.IsAborted() {
+			return
+		}
+	}
+
+	handler(c)
+}
+
 func TestMain(m *testing.M) {
 	hashedPass, err := bcrypt.GenerateFromPassword([]byte("123456"), bcrypt.DefaultCost)
 	if err != nil {
@@ -53,16 +107,38 @@ func TestMain(m *testing.M) {
 
 	users_service_mock.MockDb = map[string]string{
 		"johndoe@gmail.com": string(hashedPass),
+		"janedoe@gmail.com": string(hashedPass),
 	}
 	users_service_mock.MockDbID = map[int64]users.User{
 		1: {
-			ID:          1,
-			FirstName:   "John",
-			LastName:    "Doe",
-			Email:       "johndoe@gmail.com",
-			DateCreated: "",
-			Status:      "",
-			Password:    "1234",
+			ID:                  1,
+			FirstName:           "John",
+			LastName:            "Doe",
+			Email:               "johndoe@gmail.com",
+			DateCreated:         "",
+			Status:              users.StatusActive,
+			Password:            string(hashedPass),
+			FavoritesVisibility: users.FavoritesPublic,
+		},
+		3: {
+			ID:                  3,
+			FirstName:           "Jane",
+			LastName:            "Doe",
+			Email:               "janedoe@gmail.com",
+			DateCreated:         "",
+			Status:              users.StatusSuspended,
+			Password:            string(hashedPass),
+			FavoritesVisibility: users.FavoritesPrivate,
+		},
+	}
+
+	users_service_mock.MockProfiles = map[int64]user_profiles.UserProfile{
+		1: {
+			UserID:         1,
+			Handle:         "johndoe",
+			DisplayName:    "Johnny",
+			Bio:            "Horror movies all day",
+			FavoriteGenres: []string{"Horror"},
 		},
 	}
 
@@ -73,9 +149,17 @@ func TestMain(m *testing.M) {
 	oldUsersService := users_service.UsersService
 
 	users_service.UsersService = &users_service_mock.UsersServiceMock{
-		CanGetFavorites: true,
-		CanAddFavorite:  true,
-		FavoriteCached:  true,
+		CanGetFavorites:  true,
+		CanAddFavorite:   true,
+		CanReorder:       true,
+		CanRemove:        true,
+		CanReplace:       true,
+		FavoriteCached:   true,
+		CanVerify:        true,
+		CanResetPassword: true,
+		CanSearch:        true,
+		CanUpdateProfile: true,
+		CanGetFeed:       true,
 	}
 
 	oldMoviesService := movies_service.MoviesService
@@ -93,6 +177,15 @@ func TestMain(m *testing.M) {
 		CanCreate:  true,
 		Authorized: true,
 		WrongID:    false,
+		CanRefresh: true,
+		CanRevoke:  true,
+		CanList:    true,
+	}
+
+	oldLoginGuard := loginguard.LoginGuard
+
+	loginguard.LoginGuard = &loginguard_mock.LoginGuardMock{
+		CanCheck: true,
 	}
 
 	exitCode := m.Run()
@@ -100,18 +193,17 @@ func TestMain(m *testing.M) {
 	users_service.UsersService = oldUsersService
 	movies_service.MoviesService = oldMoviesService
 	authorization.AuthManager = oldAuthorizationManager
+	loginguard.LoginGuard = oldLoginGuard
 
 	os.Exit(exitCode)
 }
 
 func TestLoginSuccess(t *testing.T) {
-	exampleJsonReq, err := json.Marshal(
-		users.User{
-			ID:       1,
-			Email:    "johndoe@gmail.com",
-			Password: "123456",
-		},
-	)
+	exampleJsonReq, err := json.Marshal(map[string]interface{}{
+		"id":       1,
+		"email":    "johndoe@gmail.com",
+		"password": "123456",
+	})
 	if err != nil {
 		panic(err)
 	}
@@ -132,35 +224,38 @@ func TestLoginSuccess(t *testing.T) {
 	assert.EqualValues(t, "token_1", r)
 }
 
-func TestLoginWrongPassword(t *testing.T) {
-	exampleJsonReq, err := json.Marshal(
-		users.User{
-			Email:    "johndoe@gmail.com",
-			Password: "12345",
-		},
-	)
+func TestLoginRehashesPassword(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(map[string]interface{}{
+		"id":       1,
+		"email":    "johndoe@gmail.com",
+		"password": "123456",
+	})
 	if err != nil {
 		panic(err)
 	}
 
 	w := PrepareTest(exampleJsonReq, "POST")
 
-	UsersController.Login(c)
+	usersServiceMock := users_service.UsersService.(*users_service_mock.UsersServiceMock)
+	updated := len(usersServiceMock.UpdatedPasswordsIDs)
 
-	responseData, _ := ioutil.ReadAll(w.Body)
+	oldCost := config.GetConfig().Password.BcryptCost
+	config.GetConfig().Password.BcryptCost = bcrypt.DefaultCost + 1
 
-	var receivedResponse rest_errors.RestErr
-	err = json.Unmarshal(responseData, &receivedResponse)
+	UsersController.Login(c)
 
-	assert.Nil(t, err)
-	assert.EqualValues(t, http.StatusBadRequest, w.Code)
-	assert.EqualValues(t, "Wrong password", receivedResponse.Message)
-	assert.EqualValues(t, http.StatusBadRequest, receivedResponse.Status)
-	assert.EqualValues(t, "bad_request", receivedResponse.Err)
+	config.GetConfig().Password.BcryptCost = oldCost
+
+	assert.EqualValues(t, http.StatusOK, w.Code)
+	assert.EqualValues(t, updated+1, len(usersServiceMock.UpdatedPasswordsIDs))
+	assert.EqualValues(t, 1, usersServiceMock.UpdatedPasswordsIDs[updated])
 }
 
-func TestLoginInvalidJSON(t *testing.T) {
-	exampleJsonReq, err := json.Marshal(`{"invalid_key": "true"}`)
+func TestLoginWrongPassword(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(map[string]string{
+		"email":    "johndoe@gmail.com",
+		"password": "12345",
+	})
 	if err != nil {
 		panic(err)
 	}
@@ -175,73 +270,66 @@ func TestLoginInvalidJSON(t *testing.T) {
 	err = json.Unmarshal(responseData, &receivedResponse)
 
 	assert.Nil(t, err)
-	assert.EqualValues(t, http.StatusBadRequest, w.Code)
-	assert.EqualValues(t, receivedResponse.Message, "Invalid JSON body")
-	assert.EqualValues(t, receivedResponse.Status, http.StatusBadRequest)
-	assert.EqualValues(t, receivedResponse.Err, "bad_request")
+	assert.EqualValues(t, http.StatusUnauthorized, w.Code)
+	assert.EqualValues(t, "Invalid email or password", receivedResponse.Message)
+	assert.EqualValues(t, http.StatusUnauthorized, receivedResponse.Status)
+	assert.EqualValues(t, "unauthorized", receivedResponse.Err)
+
+	guardMock := loginguard.LoginGuard.(*loginguard_mock.LoginGuardMock)
+	assert.EqualValues(t, "johndoe@gmail.com", guardMock.Failures[len(guardMock.Failures)-1])
+	assert.EqualValues(t, loginguard.ReasonWrongPassword, guardMock.Reasons[len(guardMock.Reasons)-1])
 }
 
-func TestLoginUserNotFound(t *testing.T) {
-	exampleJsonReq, err := json.Marshal(
-		users.User{
-			Email:    "nonregisteredemail@gmail.com",
-			Password: "1234",
-		},
-	)
+func TestLoginLockedOut(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(map[string]string{
+		"email":    "johndoe@gmail.com",
+		"password": "123456",
+	})
 	if err != nil {
 		panic(err)
 	}
 
 	w := PrepareTest(exampleJsonReq, "POST")
 
+	loginguard.LoginGuard.(*loginguard_mock.LoginGuardMock).Lockout = 90 * time.Second
+
 	UsersController.Login(c)
 
+	loginguard.LoginGuard.(*loginguard_mock.LoginGuardMock).Lockout = 0
+
 	responseData, _ := ioutil.ReadAll(w.Body)
 
 	var receivedResponse rest_errors.RestErr
 	err = json.Unmarshal(responseData, &receivedResponse)
 
 	assert.Nil(t, err)
-	assert.EqualValues(t, http.StatusNotFound, w.Code)
-	assert.EqualValues(t, "User not found", receivedResponse.Message)
-	assert.EqualValues(t, http.StatusNotFound, receivedResponse.Status)
-	assert.EqualValues(t, "not_found", receivedResponse.Err)
+	assert.EqualValues(t, http.StatusTooManyRequests, w.Code)
+	assert.EqualValues(t, "90", w.Header().Get("Retry-After"))
+	assert.EqualValues(t, "Too many failed login attempts, try again later", receivedResponse.Message)
 }
 
-func TestCreateSuccess(t *testing.T) {
-	exampleJsonReq, err := json.Marshal(
-		users.User{
-			Email:     "johndoe2@gmail.com",
-			Password:  "1234",
-			FirstName: "John",
-			LastName:  "Doe",
-		},
-	)
+func TestLoginGuardErrorDoesNotBlockLogin(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(map[string]string{
+		"email":    "johndoe@gmail.com",
+		"password": "123456",
+	})
 	if err != nil {
 		panic(err)
 	}
 
 	w := PrepareTest(exampleJsonReq, "POST")
 
-	UsersController.Create(c)
+	loginguard.LoginGuard.(*loginguard_mock.LoginGuardMock).CanCheck = false
 
-	responseData, _ := ioutil.ReadAll(w.Body)
+	UsersController.Login(c)
 
-	var receivedResponse users.User
-	err = json.Unmarshal(responseData, &receivedResponse)
+	loginguard.LoginGuard.(*loginguard_mock.LoginGuardMock).CanCheck = true
 
-	assert.Nil(t, err)
-	assert.EqualValues(t, http.StatusCreated, w.Code)
-	assert.EqualValues(t, 2, receivedResponse.ID)
-	assert.EqualValues(t, users.StatusActive, receivedResponse.Status)
-	assert.EqualValues(t, "John", receivedResponse.FirstName)
-	assert.EqualValues(t, "Doe", receivedResponse.LastName)
-	assert.EqualValues(t, "johndoe2@gmail.com", receivedResponse.Email)
-	assert.EqualValues(t, "", receivedResponse.Password)
-	assert.EqualValues(t, users_service_mock.Now, receivedResponse.DateCreated)
+	assert.EqualValues(t, http.StatusOK, w.Code)
+	assert.Contains(t, loginguard.LoginGuard.(*loginguard_mock.LoginGuardMock).Resets, "johndoe@gmail.com")
 }
 
-func TestCreateInvalidJSON(t *testing.T) {
+func TestLoginInvalidJSON(t *testing.T) {
 	exampleJsonReq, err := json.Marshal(`{"invalid_key": "true"}`)
 	if err != nil {
 		panic(err)
@@ -249,7 +337,7 @@ func TestCreateInvalidJSON(t *testing.T) {
 
 	w := PrepareTest(exampleJsonReq, "POST")
 
-	UsersController.Create(c)
+	UsersController.Login(c)
 
 	responseData, _ := ioutil.ReadAll(w.Body)
 
@@ -263,219 +351,197 @@ func TestCreateInvalidJSON(t *testing.T) {
 	assert.EqualValues(t, receivedResponse.Err, "bad_request")
 }
 
-func TestCreateSaveError(t *testing.T) {
-	exampleJsonReq, err := json.Marshal(
-		users.User{
-			Email:    "johndoe@gmail.com",
-			Password: "1234",
-		},
-	)
+func TestRefreshTokenSuccess(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(map[string]string{
+		"refresh_token": "refresh_token_1",
+	})
 	if err != nil {
 		panic(err)
 	}
 
 	w := PrepareTest(exampleJsonReq, "POST")
 
-	UsersController.Create(c)
+	UsersController.RefreshToken(c)
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var response map[string]string
+	err = json.Unmarshal(responseData, &response)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusOK, w.Code)
+	assert.EqualValues(t, "token_1", response["access_token"])
+	assert.EqualValues(t, "refresh_token_1", response["refresh_token"])
+}
+
+func TestRefreshTokenMissingToken(t *testing.T) {
+	w := PrepareTest([]byte("{}"), "POST")
+
+	UsersController.RefreshToken(c)
 
 	responseData, _ := ioutil.ReadAll(w.Body)
 
 	var receivedResponse rest_errors.RestErr
-	err = json.Unmarshal(responseData, &receivedResponse)
+	err := json.Unmarshal(responseData, &receivedResponse)
 
 	assert.Nil(t, err)
-	assert.EqualValues(t, http.StatusInternalServerError, w.Code)
-	assert.EqualValues(t, "Error when trying to save user", receivedResponse.Message)
-	assert.EqualValues(t, http.StatusInternalServerError, receivedResponse.Status)
-	assert.EqualValues(t, receivedResponse.Err, "internal_server_error")
+	assert.EqualValues(t, http.StatusBadRequest, w.Code)
+	assert.EqualValues(t, "Invalid JSON body", receivedResponse.Message)
 }
 
-func TestUpdateSuccess(t *testing.T) {
-	exampleJsonReq, err := json.Marshal(
-		users.User{
-			Email:     "johndoe2@gmail.com",
-			FirstName: "Johnn",
-			LastName:  "Doee",
-		},
-	)
+func TestRefreshTokenInvalid(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(map[string]string{
+		"refresh_token": "invalid",
+	})
 	if err != nil {
 		panic(err)
 	}
 
 	w := PrepareTest(exampleJsonReq, "POST")
 
-	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
-	c.Request.Header.Set("Authorization", "token_1")
+	authorization.AuthManager.(*authorization_mock.AuthorizationMock).CanRefresh = false
 
-	UsersController.Update(c)
+	UsersController.RefreshToken(c)
 
-	c.Params = make([]gin.Param, 0)
-	c.Request.Header.Del("Authorization")
+	authorization.AuthManager.(*authorization_mock.AuthorizationMock).CanRefresh = true
 
 	responseData, _ := ioutil.ReadAll(w.Body)
 
-	var receivedResponse users.User
+	var receivedResponse rest_errors.RestErr
 	err = json.Unmarshal(responseData, &receivedResponse)
 
 	assert.Nil(t, err)
-	assert.EqualValues(t, http.StatusOK, w.Code)
-	assert.EqualValues(t, 1, receivedResponse.ID)
-	assert.EqualValues(t, "Johnn", receivedResponse.FirstName)
-	assert.EqualValues(t, "Doee", receivedResponse.LastName)
-	assert.EqualValues(t, "johndoe2@gmail.com", receivedResponse.Email)
-	assert.EqualValues(t, "", receivedResponse.Password)
+	assert.EqualValues(t, http.StatusUnauthorized, w.Code)
+	assert.EqualValues(t, "Invalid refresh token", receivedResponse.Message)
+	assert.EqualValues(t, "unauthorized", receivedResponse.Err)
 }
 
-func TestUpdatePartialSuccess(t *testing.T) {
-	exampleJsonReq, err := json.Marshal(
-		users.User{
-			FirstName: "Johnn",
-			LastName:  "Doee",
-		},
-	)
+func TestRefreshTokenReused(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(map[string]string{
+		"refresh_token": "refresh_token_1",
+	})
 	if err != nil {
 		panic(err)
 	}
 
-	w := PrepareTest(exampleJsonReq, "PATCH")
+	w := PrepareTest(exampleJsonReq, "POST")
 
-	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
-	c.Request.Header.Set("Authorization", "token_1")
+	authorization.AuthManager.(*authorization_mock.AuthorizationMock).RefreshReused = true
 
-	UsersController.Update(c)
+	UsersController.RefreshToken(c)
 
-	c.Params = make([]gin.Param, 0)
-	c.Request.Header.Del("Authorization")
+	authorization.AuthManager.(*authorization_mock.AuthorizationMock).RefreshReused = false
 
 	responseData, _ := ioutil.ReadAll(w.Body)
 
-	var receivedResponse users.User
+	var receivedResponse rest_errors.RestErr
 	err = json.Unmarshal(responseData, &receivedResponse)
 
 	assert.Nil(t, err)
-	assert.EqualValues(t, http.StatusOK, w.Code)
-	assert.EqualValues(t, 1, receivedResponse.ID)
-	assert.EqualValues(t, "Johnn", receivedResponse.FirstName)
-	assert.EqualValues(t, "Doee", receivedResponse.LastName)
-	assert.EqualValues(t, "johndoe@gmail.com", receivedResponse.Email)
-	assert.EqualValues(t, "", receivedResponse.Password)
+	assert.EqualValues(t, http.StatusUnauthorized, w.Code)
+	assert.EqualValues(t, "Refresh token was already used, session has been revoked", receivedResponse.Message)
 }
 
-func TestUpdateInvalidUserID(t *testing.T) {
-	exampleJsonReq, err := json.Marshal(
-		users.User{
-			FirstName: "Johnn",
-			LastName:  "Doee",
-			Email:     "johndoe2@gmail.com",
-		},
-	)
-	if err != nil {
-		panic(err)
-	}
+func TestLogoutSuccess(t *testing.T) {
+	w := PrepareTest(nil, "POST")
 
-	w := PrepareTest(exampleJsonReq, "POST")
 	c.Request.Header.Set("Authorization", "token_1")
 
-	UsersController.Update(c)
+	UsersController.Logout(c)
 
 	c.Request.Header.Del("Authorization")
 
+	assert.EqualValues(t, http.StatusOK, w.Code)
+}
+
+func TestLogoutUnauthorized(t *testing.T) {
+	w := PrepareTest(nil, "POST")
+
+	authorization.AuthManager.(*authorization_mock.AuthorizationMock).Authorized = false
+
+	UsersController.Logout(c)
+
+	authorization.AuthManager.(*authorization_mock.AuthorizationMock).Authorized = true
+
 	responseData, _ := ioutil.ReadAll(w.Body)
 
 	var receivedResponse rest_errors.RestErr
-	err = json.Unmarshal(responseData, &receivedResponse)
+	err := json.Unmarshal(responseData, &receivedResponse)
 
 	assert.Nil(t, err)
-	assert.EqualValues(t, http.StatusBadRequest, w.Code)
-	assert.EqualValues(t, "User ID should be a number", receivedResponse.Message)
-	assert.EqualValues(t, "bad_request", receivedResponse.Err)
-	assert.EqualValues(t, http.StatusBadRequest, receivedResponse.Status)
+	assert.EqualValues(t, http.StatusUnauthorized, w.Code)
+	assert.EqualValues(t, "Invalid JWT token", receivedResponse.Message)
 }
 
-func TestUpdateInvalidToken(t *testing.T) {
-	exampleJsonReq, err := json.Marshal(
-		users.User{
-			FirstName: "Johnn",
-			LastName:  "Doee",
-			Email:     "johndoe2@gmail.com",
-		},
-	)
-	if err != nil {
-		panic(err)
-	}
-
-	w := PrepareTest(exampleJsonReq, "POST")
+func TestLogoutFailure(t *testing.T) {
+	w := PrepareTest(nil, "POST")
 
 	c.Request.Header.Set("Authorization", "token_1")
 
-	authorization.AuthManager.(*authorization_mock.AuthorizationMock).Authorized = false
+	authorization.AuthManager.(*authorization_mock.AuthorizationMock).CanRevoke = false
 
-	UsersController.Update(c)
+	UsersController.Logout(c)
 
-	authorization.AuthManager.(*authorization_mock.AuthorizationMock).Authorized = true
+	authorization.AuthManager.(*authorization_mock.AuthorizationMock).CanRevoke = true
 
 	c.Request.Header.Del("Authorization")
 
 	responseData, _ := ioutil.ReadAll(w.Body)
 
 	var receivedResponse rest_errors.RestErr
-	err = json.Unmarshal(responseData, &receivedResponse)
+	err := json.Unmarshal(responseData, &receivedResponse)
 
 	assert.Nil(t, err)
-	assert.EqualValues(t, http.StatusUnauthorized, w.Code)
-	assert.EqualValues(t, http.StatusUnauthorized, receivedResponse.Status)
-	assert.EqualValues(t, "Invalid JWT token", receivedResponse.Message)
-	assert.EqualValues(t, "unauthorized", receivedResponse.Err)
+	assert.EqualValues(t, http.StatusInternalServerError, w.Code)
+	assert.EqualValues(t, "Could not revoke session", receivedResponse.Message)
 }
 
-func TestUpdateWrongTokenID(t *testing.T) {
-	exampleJsonReq, err := json.Marshal(
-		users.User{
-			FirstName: "Johnn",
-			LastName:  "Doee",
-			Email:     "johndoe2@gmail.com",
-		},
-	)
-	if err != nil {
-		panic(err)
-	}
-
-	w := PrepareTest(exampleJsonReq, "POST")
+func TestRevokeAllSessionsSuccess(t *testing.T) {
+	w := PrepareTest(nil, "POST")
 
 	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
+	c.Request.Header.Set("Authorization", "token_1")
 
-	authorization.AuthManager.(*authorization_mock.AuthorizationMock).WrongID = true
+	runAsOwner(UsersController.RevokeAllSessions)
+
+	c.Params = make([]gin.Param, 0)
+	c.Request.Header.Del("Authorization")
 
-	UsersController.Update(c)
+	assert.EqualValues(t, http.StatusOK, w.Code)
+}
 
-	authorization.AuthManager.(*authorization_mock.AuthorizationMock).WrongID = false
+func TestRevokeAllSessionsWrongUser(t *testing.T) {
+	w := PrepareTest(nil, "POST")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "2"})
+	c.Request.Header.Set("Authorization", "token_1")
+
+	runAsOwner(UsersController.RevokeAllSessions)
 
 	c.Params = make([]gin.Param, 0)
+	c.Request.Header.Del("Authorization")
 
 	responseData, _ := ioutil.ReadAll(w.Body)
 
 	var receivedResponse rest_errors.RestErr
-	err = json.Unmarshal(responseData, &receivedResponse)
+	err := json.Unmarshal(responseData, &receivedResponse)
 
 	assert.Nil(t, err)
 	assert.EqualValues(t, http.StatusUnauthorized, w.Code)
-	assert.EqualValues(t, http.StatusUnauthorized, receivedResponse.Status)
 	assert.EqualValues(t, "User ID in the request does not match token user ID", receivedResponse.Message)
-	assert.EqualValues(t, "unauthorized", receivedResponse.Err)
 }
 
-func TestUpdateInvalidJSONBody(t *testing.T) {
-	exampleJsonReq, err := json.Marshal(`{"invalid_key": "true"}`)
-	if err != nil {
-		panic(err)
-	}
-
-	w := PrepareTest(exampleJsonReq, "POST")
+func TestRevokeAllSessionsFailure(t *testing.T) {
+	w := PrepareTest(nil, "POST")
 
 	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
 	c.Request.Header.Set("Authorization", "token_1")
 
-	UsersController.Update(c)
+	authorization.AuthManager.(*authorization_mock.AuthorizationMock).CanRevoke = false
+
+	runAsOwner(UsersController.RevokeAllSessions)
+
+	authorization.AuthManager.(*authorization_mock.AuthorizationMock).CanRevoke = true
 
 	c.Params = make([]gin.Param, 0)
 	c.Request.Header.Del("Authorization")
@@ -483,123 +549,107 @@ func TestUpdateInvalidJSONBody(t *testing.T) {
 	responseData, _ := ioutil.ReadAll(w.Body)
 
 	var receivedResponse rest_errors.RestErr
-	err = json.Unmarshal(responseData, &receivedResponse)
+	err := json.Unmarshal(responseData, &receivedResponse)
 
 	assert.Nil(t, err)
-	assert.EqualValues(t, http.StatusBadRequest, w.Code)
-	assert.EqualValues(t, receivedResponse.Message, "Invalid JSON body")
-	assert.EqualValues(t, receivedResponse.Status, http.StatusBadRequest)
-	assert.EqualValues(t, receivedResponse.Err, "bad_request")
+	assert.EqualValues(t, http.StatusInternalServerError, w.Code)
+	assert.EqualValues(t, "Could not revoke user sessions", receivedResponse.Message)
 }
 
-func TestUpdateSaveError(t *testing.T) {
-	exampleJsonReq, err := json.Marshal(
-		users.User{
-			ID:        2,
-			FirstName: "Johnn",
-			LastName:  "Doee",
-		},
-	)
-	if err != nil {
-		panic(err)
-	}
+func TestGetSessionsSuccess(t *testing.T) {
+	w := PrepareTest(nil, "GET")
 
-	w := PrepareTest(exampleJsonReq, "POST")
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
+	c.Request.Header.Set("Authorization", "token_1")
+	c.Request.Header.Set("User-Agent", "test-agent")
 
-	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "2"})
-	c.Request.Header.Set("Authorization", "token_2")
+	authorization.AuthManager.CreateToken(1, users.RoleUser, c.Request.UserAgent())
 
-	UsersController.Update(c)
+	runAsOwner(UsersController.GetSessions)
 
 	c.Params = make([]gin.Param, 0)
 	c.Request.Header.Del("Authorization")
 
 	responseData, _ := ioutil.ReadAll(w.Body)
 
-	var receivedResponse rest_errors.RestErr
-	err = json.Unmarshal(responseData, &receivedResponse)
+	var sessions []authorization.SessionInfo
+	err := json.Unmarshal(responseData, &sessions)
 
 	assert.Nil(t, err)
-	assert.EqualValues(t, http.StatusInternalServerError, w.Code)
-	assert.EqualValues(t, "Error when trying to update user", receivedResponse.Message)
-	assert.EqualValues(t, "internal_server_error", receivedResponse.Err)
-	assert.EqualValues(t, http.StatusInternalServerError, receivedResponse.Status)
+	assert.EqualValues(t, http.StatusOK, w.Code)
+	assert.EqualValues(t, 1, len(sessions))
+	assert.EqualValues(t, "session_1", sessions[0].ID)
+	assert.EqualValues(t, "test-agent", sessions[0].UserAgent)
 }
 
-func TestDeleteSuccess(t *testing.T) {
-	exampleJsonReq, err := json.Marshal(
-		users.User{
-			ID:        1,
-			FirstName: "Johnn",
-			LastName:  "Doee",
-		},
-	)
-	if err != nil {
-		panic(err)
-	}
-
-	w := PrepareTest(exampleJsonReq, "DELETE")
+func TestGetSessionsFailure(t *testing.T) {
+	w := PrepareTest(nil, "GET")
 
 	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
 	c.Request.Header.Set("Authorization", "token_1")
 
-	UsersController.Delete(c)
+	authorization.AuthManager.(*authorization_mock.AuthorizationMock).CanList = false
+
+	runAsOwner(UsersController.GetSessions)
+
+	authorization.AuthManager.(*authorization_mock.AuthorizationMock).CanList = true
 
 	c.Params = make([]gin.Param, 0)
 	c.Request.Header.Del("Authorization")
 
 	responseData, _ := ioutil.ReadAll(w.Body)
 
-	receivedResponse := string(responseData[:])
+	var receivedResponse rest_errors.RestErr
+	err := json.Unmarshal(responseData, &receivedResponse)
 
-	assert.EqualValues(t, "", receivedResponse)
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusInternalServerError, w.Code)
+	assert.EqualValues(t, "Could not list user sessions", receivedResponse.Message)
 }
 
-func TestDeleteInvalidUserID(t *testing.T) {
-	exampleJsonReq, err := json.Marshal(
-		users.User{
-			FirstName: "Johnn",
-			LastName:  "Doee",
-			Email:     "johndoe2@gmail.com",
-		},
-	)
+func TestLoginUserNotFound(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(map[string]string{
+		"email":    "nonregisteredemail@gmail.com",
+		"password": "Sup3rSecretPass",
+	})
 	if err != nil {
 		panic(err)
 	}
 
-	w := PrepareTest(exampleJsonReq, "Delete")
-	c.Request.Header.Set("Authorization", "token_1")
+	w := PrepareTest(exampleJsonReq, "POST")
 
-	UsersController.Delete(c)
+	UsersController.Login(c)
 
 	responseData, _ := ioutil.ReadAll(w.Body)
-	c.Request.Header.Del("Authorization")
 
 	var receivedResponse rest_errors.RestErr
 	err = json.Unmarshal(responseData, &receivedResponse)
 
 	assert.Nil(t, err)
-	assert.EqualValues(t, http.StatusBadRequest, w.Code)
-	assert.EqualValues(t, "User ID should be a number", receivedResponse.Message)
-	assert.EqualValues(t, "bad_request", receivedResponse.Err)
-	assert.EqualValues(t, http.StatusBadRequest, receivedResponse.Status)
+	assert.EqualValues(t, http.StatusUnauthorized, w.Code)
+	assert.EqualValues(t, "Invalid email or password", receivedResponse.Message)
+	assert.EqualValues(t, http.StatusUnauthorized, receivedResponse.Status)
+	assert.EqualValues(t, "unauthorized", receivedResponse.Err)
+
+	guardMock := loginguard.LoginGuard.(*loginguard_mock.LoginGuardMock)
+	assert.EqualValues(t, "nonregisteredemail@gmail.com", guardMock.Failures[len(guardMock.Failures)-1])
+	assert.EqualValues(t, loginguard.ReasonUnknownEmail, guardMock.Reasons[len(guardMock.Reasons)-1])
 }
 
-func TestDeleteInvalidJSONBody(t *testing.T) {
-	exampleJsonReq, err := json.Marshal(`{"invalid_key": "true"}`)
+func TestLoginUnknownEmailWithDatabase(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(map[string]string{
+		"email":    "unknown@gmail.com",
+		"password": "Sup3rSecretPass",
+	})
 	if err != nil {
 		panic(err)
 	}
 
-	w := PrepareTest(exampleJsonReq, "DELETE")
-
-	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
-	c.Request.Header.Set("Authorization", "token_1")
-
-	UsersController.Delete(c)
+	w := PrepareTest(exampleJsonReq, "POST")
 
-	c.Params = make([]gin.Param, 0)
-	c.Request.Header.Del("Authorization")
+	runWithMemoryDB(func(db database.DatabaseClient) {
+		UsersController.Login(c)
+	})
 
 	responseData, _ := ioutil.ReadAll(w.Body)
 
@@ -607,128 +657,106 @@ func TestDeleteInvalidJSONBody(t *testing.T) {
 	err = json.Unmarshal(responseData, &receivedResponse)
 
 	assert.Nil(t, err)
-	assert.EqualValues(t, http.StatusBadRequest, w.Code)
-	assert.EqualValues(t, receivedResponse.Message, "Invalid JSON body")
-	assert.EqualValues(t, receivedResponse.Status, http.StatusBadRequest)
-	assert.EqualValues(t, receivedResponse.Err, "bad_request")
+	assert.EqualValues(t, http.StatusUnauthorized, w.Code)
+	assert.EqualValues(t, "Invalid email or password", receivedResponse.Message)
+	assert.EqualValues(t, "unauthorized", receivedResponse.Err)
+
+	guardMock := loginguard.LoginGuard.(*loginguard_mock.LoginGuardMock)
+	assert.EqualValues(t, "unknown@gmail.com", guardMock.Failures[len(guardMock.Failures)-1])
+	assert.EqualValues(t, loginguard.ReasonUnknownEmail, guardMock.Reasons[len(guardMock.Reasons)-1])
 }
 
-func TestDeleteDeleteError(t *testing.T) {
-	exampleJsonReq, err := json.Marshal(
-		users.User{
-			FirstName: "Johnn",
-			LastName:  "Doee",
-		},
-	)
+func TestCreateSuccess(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(map[string]string{
+		"email":      "johndoe2@gmail.com",
+		"password":   "Sup3rSecretPass",
+		"first_name": "John",
+		"last_name":  "Doe",
+	})
 	if err != nil {
 		panic(err)
 	}
 
-	w := PrepareTest(exampleJsonReq, "DELETE")
-
-	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "2"})
-	c.Request.Header.Set("Authorization", "token_2")
-
-	UsersController.Delete(c)
+	w := PrepareTest(exampleJsonReq, "POST")
 
-	c.Params = make([]gin.Param, 0)
-	c.Request.Header.Del("Authorization")
+	UsersController.Create(c)
 
 	responseData, _ := ioutil.ReadAll(w.Body)
 
-	var receivedResponse rest_errors.RestErr
+	var receivedResponse users.User
 	err = json.Unmarshal(responseData, &receivedResponse)
 
 	assert.Nil(t, err)
-	assert.EqualValues(t, http.StatusInternalServerError, w.Code)
-	assert.EqualValues(t, "Error when trying to delete user", receivedResponse.Message)
-	assert.EqualValues(t, http.StatusInternalServerError, receivedResponse.Status)
-	assert.EqualValues(t, "internal_server_error", receivedResponse.Err)
+	assert.EqualValues(t, http.StatusCreated, w.Code)
+	assert.EqualValues(t, 2, receivedResponse.ID)
+	assert.EqualValues(t, users.StatusPending, receivedResponse.Status)
+	assert.EqualValues(t, "John", receivedResponse.FirstName)
+	assert.EqualValues(t, "Doe", receivedResponse.LastName)
+	assert.EqualValues(t, "johndoe2@gmail.com", receivedResponse.Email)
+	assert.EqualValues(t, "", receivedResponse.Password)
+	assert.EqualValues(t, users_service_mock.Now, receivedResponse.DateCreated)
+	assert.Contains(t, users_service.UsersService.(*users_service_mock.UsersServiceMock).VerificationsSent, "johndoe2@gmail.com")
 }
 
-func TestGetFavoritesSuccessCached(t *testing.T) {
-	exampleJsonReq, err := json.Marshal(
-		user_favorites.UserFavorites{
-			UserID:    1,
-			MoviesIDs: []int{},
-		},
-	)
+func TestCreateWeakPassword(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(map[string]string{
+		"email":      "johndoe2@gmail.com",
+		"password":   "Password1234",
+		"first_name": "John",
+		"last_name":  "Doe",
+	})
 	if err != nil {
 		panic(err)
 	}
 
-	w := PrepareTest(exampleJsonReq, "GET")
-
-	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
-
-	UsersController.GetFavorites(c)
+	w := PrepareTest(exampleJsonReq, "POST")
 
-	c.Params = make([]gin.Param, 0)
+	UsersController.Create(c)
 
 	responseData, _ := ioutil.ReadAll(w.Body)
 
-	var receivedResponse user_favorites.UserFavorites
+	var receivedResponse rest_errors.RestErr
 	err = json.Unmarshal(responseData, &receivedResponse)
 
 	assert.Nil(t, err)
-	assert.NotNil(t, receivedResponse)
-	assert.EqualValues(t, http.StatusOK, w.Code)
-	assert.EqualValues(t, 1, len(receivedResponse.MoviesData))
-	assert.EqualValues(t, 1, receivedResponse.MoviesData[0].ID)
+	assert.EqualValues(t, http.StatusBadRequest, w.Code)
+	assert.EqualValues(t, "Password is too common", receivedResponse.Message)
 }
 
-func TestGetFavoritesSuccessNotCached(t *testing.T) {
-	exampleJsonReq, err := json.Marshal(
-		user_favorites.UserFavorites{
-			UserID:    1,
-			MoviesIDs: []int{},
-		},
-	)
+func TestCreateInvalidJSON(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(`{"invalid_key": "true"}`)
 	if err != nil {
 		panic(err)
 	}
 
-	w := PrepareTest(exampleJsonReq, "GET")
-
-	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
-
-	users_service.UsersService.(*users_service_mock.UsersServiceMock).FavoriteCached = false
-
-	UsersController.GetFavorites(c)
-
-	users_service.UsersService.(*users_service_mock.UsersServiceMock).FavoriteCached = true
+	w := PrepareTest(exampleJsonReq, "POST")
 
-	c.Params = make([]gin.Param, 0)
+	UsersController.Create(c)
 
 	responseData, _ := ioutil.ReadAll(w.Body)
 
-	var receivedResponse user_favorites.UserFavorites
+	var receivedResponse rest_errors.RestErr
 	err = json.Unmarshal(responseData, &receivedResponse)
 
 	assert.Nil(t, err)
-	assert.NotNil(t, receivedResponse)
-	assert.EqualValues(t, http.StatusOK, w.Code)
-	assert.EqualValues(t, 1, len(receivedResponse.MoviesData))
-	assert.EqualValues(t, 1, receivedResponse.MoviesData[0].ID)
-	assert.EqualValues(t, true, movies_service.MoviesService.(*movies_service_mock.MoviesServiceMock).AddedMovie)
-
-	movies_service.MoviesService.(*movies_service_mock.MoviesServiceMock).AddedMovie = false
+	assert.EqualValues(t, http.StatusBadRequest, w.Code)
+	assert.EqualValues(t, receivedResponse.Message, "Invalid JSON body")
+	assert.EqualValues(t, receivedResponse.Status, http.StatusBadRequest)
+	assert.EqualValues(t, receivedResponse.Err, "bad_request")
 }
 
-func TestGetFavoritesInvalidUserID(t *testing.T) {
-	exampleJsonReq, err := json.Marshal(
-		user_favorites.UserFavorites{
-			UserID:    1,
-			MoviesIDs: []int{},
-		},
-	)
+func TestCreateSaveError(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(map[string]string{
+		"email":    "johndoe@gmail.com",
+		"password": "Sup3rSecretPass",
+	})
 	if err != nil {
 		panic(err)
 	}
 
-	w := PrepareTest(exampleJsonReq, "GET")
+	w := PrepareTest(exampleJsonReq, "POST")
 
-	UsersController.GetFavorites(c)
+	UsersController.Create(c)
 
 	responseData, _ := ioutil.ReadAll(w.Body)
 
@@ -736,126 +764,246 @@ func TestGetFavoritesInvalidUserID(t *testing.T) {
 	err = json.Unmarshal(responseData, &receivedResponse)
 
 	assert.Nil(t, err)
-	assert.EqualValues(t, http.StatusBadRequest, w.Code)
-	assert.EqualValues(t, "User ID should be a number", receivedResponse.Message)
-	assert.EqualValues(t, "bad_request", receivedResponse.Err)
-	assert.EqualValues(t, http.StatusBadRequest, receivedResponse.Status)
+	assert.EqualValues(t, http.StatusInternalServerError, w.Code)
+	assert.EqualValues(t, "Error when trying to save user", receivedResponse.Message)
+	assert.EqualValues(t, http.StatusInternalServerError, receivedResponse.Status)
+	assert.EqualValues(t, receivedResponse.Err, "internal_server_error")
 }
 
-func TestGetFavoritesFailure(t *testing.T) {
+func TestUpdateSuccess(t *testing.T) {
 	exampleJsonReq, err := json.Marshal(
-		user_favorites.UserFavorites{
-			UserID:    1,
-			MoviesIDs: []int{},
+		users.User{
+			Email:     "johndoe2@gmail.com",
+			FirstName: "Johnn",
+			LastName:  "Doee",
 		},
 	)
 	if err != nil {
 		panic(err)
 	}
 
-	w := PrepareTest(exampleJsonReq, "GET")
+	w := PrepareTest(exampleJsonReq, "POST")
 
 	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
+	c.Request.Header.Set("Authorization", "token_1")
 
-	users_service.UsersService.(*users_service_mock.UsersServiceMock).CanGetFavorites = false
-
-	UsersController.GetFavorites(c)
-
-	users_service.UsersService.(*users_service_mock.UsersServiceMock).CanGetFavorites = true
+	runAsOwner(UsersController.Update)
 
 	c.Params = make([]gin.Param, 0)
+	c.Request.Header.Del("Authorization")
 
 	responseData, _ := ioutil.ReadAll(w.Body)
 
-	var receivedResponse *rest_errors.RestErr
+	var receivedResponse users.User
 	err = json.Unmarshal(responseData, &receivedResponse)
 
 	assert.Nil(t, err)
-	assert.NotNil(t, receivedResponse)
-	assert.EqualValues(t, http.StatusInternalServerError, w.Code)
-	assert.EqualValues(t, "Error when trying to get user favorites", receivedResponse.Message)
-	assert.EqualValues(t, http.StatusInternalServerError, receivedResponse.Status)
-	assert.EqualValues(t, "internal_server_error", receivedResponse.Err)
+	assert.EqualValues(t, http.StatusOK, w.Code)
+	assert.EqualValues(t, 1, receivedResponse.ID)
+	assert.EqualValues(t, "Johnn", receivedResponse.FirstName)
+	assert.EqualValues(t, "Doee", receivedResponse.LastName)
+	assert.EqualValues(t, "johndoe2@gmail.com", receivedResponse.Email)
+	assert.EqualValues(t, "", receivedResponse.Password)
 }
 
-func TestAddFavoritesSuccessMovieCached(t *testing.T) {
+func TestUpdatePartialSuccess(t *testing.T) {
 	exampleJsonReq, err := json.Marshal(
-		movies.MovieInfo{
-			Movie: tmdb.Movie{
-				ID:    1,
-				Title: "Example Movie Title",
-			},
-			CreatedAt: "",
+		users.User{
+			FirstName: "Johnn",
+			LastName:  "Doee",
 		},
 	)
 	if err != nil {
 		panic(err)
 	}
 
-	w := PrepareTest(exampleJsonReq, "POST")
+	w := PrepareTest(exampleJsonReq, "PATCH")
 
 	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
 	c.Request.Header.Set("Authorization", "token_1")
 
-	UsersController.AddFavorite(c)
+	runAsOwner(UsersController.Update)
 
 	c.Params = make([]gin.Param, 0)
 	c.Request.Header.Del("Authorization")
 
 	responseData, _ := ioutil.ReadAll(w.Body)
 
-	receivedResponse := string(responseData[:])
+	var receivedResponse users.User
+	err = json.Unmarshal(responseData, &receivedResponse)
 
-	assert.EqualValues(t, "", receivedResponse)
+	assert.Nil(t, err)
 	assert.EqualValues(t, http.StatusOK, w.Code)
-	assert.EqualValues(t, false, movies_service.MoviesService.(*movies_service_mock.MoviesServiceMock).AddedMovie)
+	assert.EqualValues(t, 1, receivedResponse.ID)
+	assert.EqualValues(t, "Johnn", receivedResponse.FirstName)
+	assert.EqualValues(t, "Doee", receivedResponse.LastName)
+	assert.EqualValues(t, "johndoe@gmail.com", receivedResponse.Email)
+	assert.EqualValues(t, "", receivedResponse.Password)
 }
 
-func TestAddFavoritesSuccessMovieNotCached(t *testing.T) {
-	exampleJsonReq, err := json.Marshal(
-		movies.MovieInfo{
-			Movie: tmdb.Movie{
-				ID:    1,
-				Title: "Example Movie Title",
-			},
-			CreatedAt: "",
+func TestUpdateWithProfile(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(map[string]interface{}{
+		"first_name": "Johnn",
+		"profile": map[string]interface{}{
+			"handle":          " JohnDoe ",
+			"bio":             "Horror movies all day",
+			"favorite_genres": []string{"Horror", "horror", "Comedy"},
 		},
-	)
+	})
 	if err != nil {
 		panic(err)
 	}
 
-	w := PrepareTest(exampleJsonReq, "POST")
+	w := PrepareTest(exampleJsonReq, "PATCH")
 
 	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
 	c.Request.Header.Set("Authorization", "token_1")
 
-	movies_service.MoviesService.(*movies_service_mock.MoviesServiceMock).HasMovieCached = false
+	runAsOwner(UsersController.Update)
 
-	UsersController.AddFavorite(c)
+	c.Params = make([]gin.Param, 0)
+	c.Request.Header.Del("Authorization")
 
-	movies_service.MoviesService.(*movies_service_mock.MoviesServiceMock).HasMovieCached = true
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var receivedResponse struct {
+		users.User
+		Profile user_profiles.UserProfile `json:"profile"`
+	}
+	err = json.Unmarshal(responseData, &receivedResponse)
+
+	usersServiceMock := users_service.UsersService.(*users_service_mock.UsersServiceMock)
+	updatedProfile := usersServiceMock.UpdatedProfiles[len(usersServiceMock.UpdatedProfiles)-1]
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusOK, w.Code)
+	assert.EqualValues(t, "Johnn", receivedResponse.FirstName)
+	assert.EqualValues(t, "johndoe", receivedResponse.Profile.Handle)
+	assert.EqualValues(t, []string{"Horror", "Comedy"}, receivedResponse.Profile.FavoriteGenres)
+	assert.EqualValues(t, 1, updatedProfile.UserID)
+}
+
+func TestUpdateWithInvalidProfile(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(map[string]interface{}{
+		"profile": map[string]interface{}{
+			"handle": "search",
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	w := PrepareTest(exampleJsonReq, "PATCH")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
+	c.Request.Header.Set("Authorization", "token_1")
+
+	runAsOwner(UsersController.Update)
 
 	c.Params = make([]gin.Param, 0)
 	c.Request.Header.Del("Authorization")
 
 	responseData, _ := ioutil.ReadAll(w.Body)
 
-	receivedResponse := string(responseData[:])
+	var receivedResponse rest_errors.RestErr
+	err = json.Unmarshal(responseData, &receivedResponse)
 
-	assert.EqualValues(t, "", receivedResponse)
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, w.Code)
+	assert.EqualValues(t, "Handle must be 3 to 30 characters long, start with a letter and contain only letters, digits and underscores", receivedResponse.Message)
+}
+
+func TestUpdateWithRejectedProfileKeepsUser(t *testing.T) {
+	for _, tc := range []struct {
+		profile map[string]interface{}
+		status  int
+		message string
+		name    string
+	}{
+		{map[string]interface{}{"handle": "taken"}, http.StatusBadRequest, "Handle is already taken", "John"},
+		{map[string]interface{}{"handle": "johnny", "avatar_url": "ftp://avatars.com/john.png"}, http.StatusBadRequest, "Avatar URL must be a valid http or https URL", "John"},
+		{map[string]interface{}{"handle": "johnny"}, http.StatusOK, "", "Changed"},
+	} {
+		exampleJsonReq, err := json.Marshal(map[string]interface{}{
+			"first_name": "Changed",
+			"profile":    tc.profile,
+		})
+		if err != nil {
+			panic(err)
+		}
+
+		w := PrepareTest(exampleJsonReq, "PATCH")
+
+		c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
+		c.Request.Header.Set("Authorization", "token_1")
+
+		runWithMemoryDB(func(db database.DatabaseClient) {
+			owner := users.User{FirstName: "John", LastName: "Doe", Email: "johndoe@gmail.com", Password: "Sup3rSecretPass", Status: users.StatusActive, Role: users.RoleUser}
+			assert.Nil(t, owner.Save(db))
+
+			other := users.User{FirstName: "Jane", LastName: "Doe", Email: "janedoe@gmail.com", Password: "Sup3rSecretPass", Status: users.StatusActive, Role: users.RoleUser}
+			assert.Nil(t, other.Save(db))
+
+			_, profileErr := user_profiles.UserProfile{UserID: 2, Handle: "taken"}.Update(user_profiles.UserProfile{Handle: "taken"}, false, db)
+			assert.Nil(t, profileErr)
+
+			runAsOwner(UsersController.Update)
+
+			savedUser, getErr := users.User{ID: 1}.GetById(db)
+			assert.Nil(t, getErr)
+			assert.EqualValues(t, tc.name, savedUser.(users.User).FirstName)
+		})
+
+		c.Params = make([]gin.Param, 0)
+		c.Request.Header.Del("Authorization")
+
+		assert.EqualValues(t, tc.status, w.Code)
+
+		if tc.status != http.StatusOK {
+			responseData, _ := ioutil.ReadAll(w.Body)
+
+			var receivedResponse rest_errors.RestErr
+			err = json.Unmarshal(responseData, &receivedResponse)
+
+			assert.Nil(t, err)
+			assert.EqualValues(t, tc.message, receivedResponse.Message)
+		}
+	}
+}
+
+func TestUpdateWithoutProfileOmitsIt(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(map[string]string{"first_name": "Johnn"})
+	if err != nil {
+		panic(err)
+	}
+
+	w := PrepareTest(exampleJsonReq, "PATCH")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
+	c.Request.Header.Set("Authorization", "token_1")
+
+	runAsOwner(UsersController.Update)
+
+	c.Params = make([]gin.Param, 0)
+	c.Request.Header.Del("Authorization")
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var receivedResponse map[string]interface{}
+	err = json.Unmarshal(responseData, &receivedResponse)
+
+	assert.Nil(t, err)
 	assert.EqualValues(t, http.StatusOK, w.Code)
-	assert.EqualValues(t, true, movies_service.MoviesService.(*movies_service_mock.MoviesServiceMock).AddedMovie)
+	assert.NotContains(t, receivedResponse, "profile")
+	assert.NotContains(t, receivedResponse, "password")
 }
 
-func TestAddFavoritesInvalidUserID(t *testing.T) {
+func TestUpdateInvalidUserID(t *testing.T) {
 	exampleJsonReq, err := json.Marshal(
-		movies.MovieInfo{
-			Movie: tmdb.Movie{
-				ID:    1,
-				Title: "Example Movie Title",
-			},
-			CreatedAt: "",
+		users.User{
+			FirstName: "Johnn",
+			LastName:  "Doee",
+			Email:     "johndoe2@gmail.com",
 		},
 	)
 	if err != nil {
@@ -863,10 +1011,9 @@ func TestAddFavoritesInvalidUserID(t *testing.T) {
 	}
 
 	w := PrepareTest(exampleJsonReq, "POST")
-
 	c.Request.Header.Set("Authorization", "token_1")
 
-	UsersController.AddFavorite(c)
+	runAsOwner(UsersController.Update)
 
 	c.Request.Header.Del("Authorization")
 
@@ -882,20 +1029,28 @@ func TestAddFavoritesInvalidUserID(t *testing.T) {
 	assert.EqualValues(t, http.StatusBadRequest, receivedResponse.Status)
 }
 
-func TestAddFavoritesInvalidJSONBody(t *testing.T) {
-	exampleJsonReq, err := json.Marshal(`{"invalid_key": "true"}`)
+func TestUpdateInvalidToken(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(
+		users.User{
+			FirstName: "Johnn",
+			LastName:  "Doee",
+			Email:     "johndoe2@gmail.com",
+		},
+	)
 	if err != nil {
 		panic(err)
 	}
 
 	w := PrepareTest(exampleJsonReq, "POST")
 
-	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
 	c.Request.Header.Set("Authorization", "token_1")
 
-	UsersController.AddFavorite(c)
+	authorization.AuthManager.(*authorization_mock.AuthorizationMock).Authorized = false
+
+	runAsOwner(UsersController.Update)
+
+	authorization.AuthManager.(*authorization_mock.AuthorizationMock).Authorized = true
 
-	c.Params = make([]gin.Param, 0)
 	c.Request.Header.Del("Authorization")
 
 	responseData, _ := ioutil.ReadAll(w.Body)
@@ -904,20 +1059,18 @@ func TestAddFavoritesInvalidJSONBody(t *testing.T) {
 	err = json.Unmarshal(responseData, &receivedResponse)
 
 	assert.Nil(t, err)
-	assert.EqualValues(t, http.StatusBadRequest, w.Code)
-	assert.EqualValues(t, "Invalid JSON body", receivedResponse.Message)
-	assert.EqualValues(t, "bad_request", receivedResponse.Err)
-	assert.EqualValues(t, http.StatusBadRequest, receivedResponse.Status)
+	assert.EqualValues(t, http.StatusUnauthorized, w.Code)
+	assert.EqualValues(t, http.StatusUnauthorized, receivedResponse.Status)
+	assert.EqualValues(t, "Invalid JWT token", receivedResponse.Message)
+	assert.EqualValues(t, "unauthorized", receivedResponse.Err)
 }
 
-func TestAddFavoritesGetMovieError(t *testing.T) {
+func TestUpdateWrongTokenID(t *testing.T) {
 	exampleJsonReq, err := json.Marshal(
-		movies.MovieInfo{
-			Movie: tmdb.Movie{
-				ID:    1,
-				Title: "Example Movie Title",
-			},
-			CreatedAt: "",
+		users.User{
+			FirstName: "Johnn",
+			LastName:  "Doee",
+			Email:     "johndoe2@gmail.com",
 		},
 	)
 	if err != nil {
@@ -927,16 +1080,14 @@ func TestAddFavoritesGetMovieError(t *testing.T) {
 	w := PrepareTest(exampleJsonReq, "POST")
 
 	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
-	c.Request.Header.Set("Authorization", "token_1")
 
-	movies_service.MoviesService.(*movies_service_mock.MoviesServiceMock).CanGetMovie = false
+	authorization.AuthManager.(*authorization_mock.AuthorizationMock).WrongID = true
 
-	UsersController.AddFavorite(c)
+	runAsOwner(UsersController.Update)
 
-	movies_service.MoviesService.(*movies_service_mock.MoviesServiceMock).CanGetMovie = true
+	authorization.AuthManager.(*authorization_mock.AuthorizationMock).WrongID = false
 
 	c.Params = make([]gin.Param, 0)
-	c.Request.Header.Del("Authorization")
 
 	responseData, _ := ioutil.ReadAll(w.Body)
 
@@ -944,22 +1095,14 @@ func TestAddFavoritesGetMovieError(t *testing.T) {
 	err = json.Unmarshal(responseData, &receivedResponse)
 
 	assert.Nil(t, err)
-	assert.EqualValues(t, http.StatusInternalServerError, w.Code)
-	assert.EqualValues(t, "Error when trying to get movie", receivedResponse.Message)
-	assert.EqualValues(t, "internal_server_error", receivedResponse.Err)
-	assert.EqualValues(t, http.StatusInternalServerError, receivedResponse.Status)
+	assert.EqualValues(t, http.StatusUnauthorized, w.Code)
+	assert.EqualValues(t, http.StatusUnauthorized, receivedResponse.Status)
+	assert.EqualValues(t, "User ID in the request does not match token user ID", receivedResponse.Message)
+	assert.EqualValues(t, "unauthorized", receivedResponse.Err)
 }
 
-func TestAddFavoritesAddMovieErrorWhenNotCached(t *testing.T) {
-	exampleJsonReq, err := json.Marshal(
-		movies.MovieInfo{
-			Movie: tmdb.Movie{
-				ID:    1,
-				Title: "Example Movie Title",
-			},
-			CreatedAt: "",
-		},
-	)
+func TestUpdateInvalidJSONBody(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(`{"invalid_key": "true"}`)
 	if err != nil {
 		panic(err)
 	}
@@ -969,13 +1112,41 @@ func TestAddFavoritesAddMovieErrorWhenNotCached(t *testing.T) {
 	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
 	c.Request.Header.Set("Authorization", "token_1")
 
-	movies_service.MoviesService.(*movies_service_mock.MoviesServiceMock).HasMovieCached = false
-	movies_service.MoviesService.(*movies_service_mock.MoviesServiceMock).CanAddMovie = false
+	runAsOwner(UsersController.Update)
 
-	UsersController.AddFavorite(c)
+	c.Params = make([]gin.Param, 0)
+	c.Request.Header.Del("Authorization")
 
-	movies_service.MoviesService.(*movies_service_mock.MoviesServiceMock).HasMovieCached = true
-	movies_service.MoviesService.(*movies_service_mock.MoviesServiceMock).CanAddMovie = true
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var receivedResponse rest_errors.RestErr
+	err = json.Unmarshal(responseData, &receivedResponse)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, w.Code)
+	assert.EqualValues(t, receivedResponse.Message, "Invalid JSON body")
+	assert.EqualValues(t, receivedResponse.Status, http.StatusBadRequest)
+	assert.EqualValues(t, receivedResponse.Err, "bad_request")
+}
+
+func TestUpdateSaveError(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(
+		users.User{
+			ID:        2,
+			FirstName: "Johnn",
+			LastName:  "Doee",
+		},
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	w := PrepareTest(exampleJsonReq, "POST")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "2"})
+	c.Request.Header.Set("Authorization", "token_2")
+
+	runAsOwner(UsersController.Update)
 
 	c.Params = make([]gin.Param, 0)
 	c.Request.Header.Del("Authorization")
@@ -987,35 +1158,82 @@ func TestAddFavoritesAddMovieErrorWhenNotCached(t *testing.T) {
 
 	assert.Nil(t, err)
 	assert.EqualValues(t, http.StatusInternalServerError, w.Code)
-	assert.EqualValues(t, "Error when trying to add movie", receivedResponse.Message)
+	assert.EqualValues(t, "Error when trying to update user", receivedResponse.Message)
 	assert.EqualValues(t, "internal_server_error", receivedResponse.Err)
 	assert.EqualValues(t, http.StatusInternalServerError, receivedResponse.Status)
 }
 
-func TestAddFavoritesAddFavoriteError(t *testing.T) {
+func TestDeleteSuccess(t *testing.T) {
 	exampleJsonReq, err := json.Marshal(
-		movies.MovieInfo{
-			Movie: tmdb.Movie{
-				ID:    1,
-				Title: "Example Movie Title",
-			},
-			CreatedAt: "",
+		users.User{
+			ID:        1,
+			FirstName: "Johnn",
+			LastName:  "Doee",
 		},
 	)
 	if err != nil {
 		panic(err)
 	}
 
-	w := PrepareTest(exampleJsonReq, "POST")
+	w := PrepareTest(exampleJsonReq, "DELETE")
 
 	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
 	c.Request.Header.Set("Authorization", "token_1")
 
-	users_service.UsersService.(*users_service_mock.UsersServiceMock).CanAddFavorite = false
+	runAsOwner(UsersController.Delete)
+
+	c.Params = make([]gin.Param, 0)
+	c.Request.Header.Del("Authorization")
 
-	UsersController.AddFavorite(c)
+	responseData, _ := ioutil.ReadAll(w.Body)
 
-	users_service.UsersService.(*users_service_mock.UsersServiceMock).CanAddFavorite = false
+	receivedResponse := string(responseData[:])
+
+	assert.EqualValues(t, "", receivedResponse)
+}
+
+func TestDeleteInvalidUserID(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(
+		users.User{
+			FirstName: "Johnn",
+			LastName:  "Doee",
+			Email:     "johndoe2@gmail.com",
+		},
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	w := PrepareTest(exampleJsonReq, "Delete")
+	c.Request.Header.Set("Authorization", "token_1")
+
+	runAsOwner(UsersController.Delete)
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+	c.Request.Header.Del("Authorization")
+
+	var receivedResponse rest_errors.RestErr
+	err = json.Unmarshal(responseData, &receivedResponse)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, w.Code)
+	assert.EqualValues(t, "User ID should be a number", receivedResponse.Message)
+	assert.EqualValues(t, "bad_request", receivedResponse.Err)
+	assert.EqualValues(t, http.StatusBadRequest, receivedResponse.Status)
+}
+
+func TestDeleteInvalidJSONBody(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(`{"invalid_key": "true"}`)
+	if err != nil {
+		panic(err)
+	}
+
+	w := PrepareTest(exampleJsonReq, "DELETE")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
+	c.Request.Header.Set("Authorization", "token_1")
+
+	runAsOwner(UsersController.Delete)
 
 	c.Params = make([]gin.Param, 0)
 	c.Request.Header.Del("Authorization")
@@ -1026,8 +1244,1963 @@ func TestAddFavoritesAddFavoriteError(t *testing.T) {
 	err = json.Unmarshal(responseData, &receivedResponse)
 
 	assert.Nil(t, err)
-	assert.EqualValues(t, http.StatusInternalServerError, w.Code)
-	assert.EqualValues(t, "Error when trying to add user favorite", receivedResponse.Message)
-	assert.EqualValues(t, "internal_server_error", receivedResponse.Err)
-	assert.EqualValues(t, http.StatusInternalServerError, receivedResponse.Status)
+	assert.EqualValues(t, http.StatusBadRequest, w.Code)
+	assert.EqualValues(t, receivedResponse.Message, "Invalid JSON body")
+	assert.EqualValues(t, receivedResponse.Status, http.StatusBadRequest)
+	assert.EqualValues(t, receivedResponse.Err, "bad_request")
+}
+
+func TestDeleteDeleteError(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(
+		users.User{
+			FirstName: "Johnn",
+			LastName:  "Doee",
+		},
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	w := PrepareTest(exampleJsonReq, "DELETE")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "2"})
+	c.Request.Header.Set("Authorization", "token_2")
+
+	runAsOwner(UsersController.Delete)
+
+	c.Params = make([]gin.Param, 0)
+	c.Request.Header.Del("Authorization")
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var receivedResponse rest_errors.RestErr
+	err = json.Unmarshal(responseData, &receivedResponse)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusInternalServerError, w.Code)
+	assert.EqualValues(t, "Error when trying to delete user", receivedResponse.Message)
+	assert.EqualValues(t, http.StatusInternalServerError, receivedResponse.Status)
+	assert.EqualValues(t, "internal_server_error", receivedResponse.Err)
+}
+
+func TestUpdateFavoritesVisibilitySuccess(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(map[string]string{"visibility": users.FavoritesUnlisted})
+	if err != nil {
+		panic(err)
+	}
+
+	w := PrepareTest(exampleJsonReq, "PUT")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
+	c.Request.Header.Set("Authorization", "token_1")
+
+	runAsOwner(UsersController.UpdateFavoritesVisibility)
+
+	c.Params = make([]gin.Param, 0)
+	c.Request.Header.Del("Authorization")
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var receivedResponse map[string]string
+	err = json.Unmarshal(responseData, &receivedResponse)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusOK, w.Code)
+	assert.EqualValues(t, users.FavoritesUnlisted, receivedResponse["favorites_visibility"])
+	assert.EqualValues(t, "share_token", receivedResponse["favorites_share_token"])
+}
+
+func TestUpdateFavoritesVisibilityInvalid(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(map[string]string{"visibility": "friends"})
+	if err != nil {
+		panic(err)
+	}
+
+	w := PrepareTest(exampleJsonReq, "PUT")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
+	c.Request.Header.Set("Authorization", "token_1")
+
+	runAsOwner(UsersController.UpdateFavoritesVisibility)
+
+	c.Params = make([]gin.Param, 0)
+	c.Request.Header.Del("Authorization")
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var receivedResponse rest_errors.RestErr
+	err = json.Unmarshal(responseData, &receivedResponse)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, w.Code)
+	assert.EqualValues(t, "Visibility should be one of: public, followers, private, unlisted", receivedResponse.Message)
+}
+
+func TestGetProfileSuccess(t *testing.T) {
+	w := PrepareTest(nil, "GET")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "JohnDoe"})
+	c.Request.URL = &url.URL{}
+
+	UsersController.GetProfile(c)
+
+	c.Params = make([]gin.Param, 0)
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var receivedResponse user_profiles.PublicProfile
+	err := json.Unmarshal(responseData, &receivedResponse)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusOK, w.Code)
+	assert.EqualValues(t, 1, receivedResponse.UserID)
+	assert.EqualValues(t, "johndoe", receivedResponse.Handle)
+	assert.EqualValues(t, "Johnny", receivedResponse.DisplayName)
+	assert.EqualValues(t, []int{1}, receivedResponse.Favorites.MoviesIDs)
+	assert.EqualValues(t, 1, len(receivedResponse.Favorites.MoviesData))
+}
+
+func TestGetProfileNotFound(t *testing.T) {
+	w := PrepareTest(nil, "GET")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "nobody"})
+
+	UsersController.GetProfile(c)
+
+	c.Params = make([]gin.Param, 0)
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var receivedResponse rest_errors.RestErr
+	err := json.Unmarshal(responseData, &receivedResponse)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusNotFound, w.Code)
+	assert.EqualValues(t, "Profile not found", receivedResponse.Message)
+}
+
+func TestGetFavoritesSuccessCached(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(
+		user_favorites.UserFavorites{
+			UserID:    1,
+			MoviesIDs: []int{},
+		},
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	w := PrepareTest(exampleJsonReq, "GET")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
+	c.Request.URL = &url.URL{}
+
+	UsersController.GetFavorites(c)
+
+	c.Params = make([]gin.Param, 0)
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var receivedResponse user_favorites.UserFavorites
+	err = json.Unmarshal(responseData, &receivedResponse)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, receivedResponse)
+	assert.EqualValues(t, http.StatusOK, w.Code)
+	assert.EqualValues(t, 1, len(receivedResponse.MoviesData))
+	assert.EqualValues(t, 1, receivedResponse.MoviesData[0].ID)
+}
+
+func TestGetFavoritesSuccessNotCached(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(
+		user_favorites.UserFavorites{
+			UserID:    1,
+			MoviesIDs: []int{},
+		},
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	w := PrepareTest(exampleJsonReq, "GET")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
+	c.Request.URL = &url.URL{}
+
+	users_service.UsersService.(*users_service_mock.UsersServiceMock).FavoriteCached = false
+
+	UsersController.GetFavorites(c)
+
+	users_service.UsersService.(*users_service_mock.UsersServiceMock).FavoriteCached = true
+
+	c.Params = make([]gin.Param, 0)
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var receivedResponse user_favorites.UserFavorites
+	err = json.Unmarshal(responseData, &receivedResponse)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, receivedResponse)
+	assert.EqualValues(t, http.StatusOK, w.Code)
+	assert.EqualValues(t, 1, len(receivedResponse.MoviesData))
+	assert.EqualValues(t, 1, receivedResponse.MoviesData[0].ID)
+	assert.EqualValues(t, true, movies_service.MoviesService.(*movies_service_mock.MoviesServiceMock).AddedMovie)
+
+	movies_service.MoviesService.(*movies_service_mock.MoviesServiceMock).AddedMovie = false
+}
+
+func TestGetFavoritesPartialFetchFailure(t *testing.T) {
+	w := PrepareTest(nil, "GET")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
+	c.Request.URL = &url.URL{}
+
+	users_service.UsersService.(*users_service_mock.UsersServiceMock).FavoriteCached = false
+	movies_service.MoviesService.(*movies_service_mock.MoviesServiceMock).FailedMovieIds = map[int]bool{1: true}
+
+	UsersController.GetFavorites(c)
+
+	users_service.UsersService.(*users_service_mock.UsersServiceMock).FavoriteCached = true
+	movies_service.MoviesService.(*movies_service_mock.MoviesServiceMock).FailedMovieIds = nil
+
+	c.Params = make([]gin.Param, 0)
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var receivedResponse user_favorites.UserFavorites
+	err := json.Unmarshal(responseData, &receivedResponse)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusOK, w.Code)
+	assert.EqualValues(t, 0, len(receivedResponse.MoviesData))
+	assert.EqualValues(t, 1, len(receivedResponse.Errors))
+	assert.EqualValues(t, 1, receivedResponse.Errors[0].MovieID)
+	assert.EqualValues(t, "Failed to get movie information", receivedResponse.Errors[0].Error.Message)
+	assert.EqualValues(t, http.StatusInternalServerError, receivedResponse.Errors[0].Error.Status)
+}
+
+func TestGetProfileHiddenFavorites(t *testing.T) {
+	w := PrepareTest(nil, "GET")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "johndoe"})
+	c.Request.URL = &url.URL{}
+
+	owner := users_service_mock.MockDbID[1]
+	owner.FavoritesVisibility = users.FavoritesPrivate
+	users_service_mock.MockDbID[1] = owner
+
+	UsersController.GetProfile(c)
+
+	owner.FavoritesVisibility = users.FavoritesPublic
+	users_service_mock.MockDbID[1] = owner
+
+	c.Params = make([]gin.Param, 0)
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var receivedResponse user_profiles.PublicProfile
+	err := json.Unmarshal(responseData, &receivedResponse)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusOK, w.Code)
+	assert.EqualValues(t, "johndoe", receivedResponse.Handle)
+	assert.Nil(t, receivedResponse.Favorites)
+}
+
+func TestGetFavoritesPrivate(t *testing.T) {
+	w := PrepareTest(nil, "GET")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "3"})
+	c.Request.URL = &url.URL{}
+	c.Request.Header.Set("Authorization", "token_1")
+
+	if authorization.OptionalAuthenticate()(c); !c.IsAborted() {
+		UsersController.GetFavorites(c)
+	}
+
+	c.Params = make([]gin.Param, 0)
+	c.Request.Header.Del("Authorization")
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var receivedResponse rest_errors.RestErr
+	err := json.Unmarshal(responseData, &receivedResponse)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusNotFound, w.Code)
+	assert.EqualValues(t, "Favorites not found", receivedResponse.Message)
+}
+
+func TestGetFavoritesPrivateAsOwner(t *testing.T) {
+	w := PrepareTest(nil, "GET")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "3"})
+	c.Request.URL = &url.URL{}
+	c.Request.Header.Set("Authorization", "token_3")
+
+	if authorization.OptionalAuthenticate()(c); !c.IsAborted() {
+		UsersController.GetFavorites(c)
+	}
+
+	c.Params = make([]gin.Param, 0)
+	c.Request.Header.Del("Authorization")
+
+	assert.EqualValues(t, http.StatusOK, w.Code)
+}
+
+func TestGetFavoritesUnlisted(t *testing.T) {
+	owner := users_service_mock.MockDbID[1]
+	owner.FavoritesVisibility = users.FavoritesUnlisted
+	owner.FavoritesShareToken = "share_token"
+	users_service_mock.MockDbID[1] = owner
+
+	for _, tc := range []struct {
+		rawQuery string
+		status   int
+	}{
+		{"", http.StatusNotFound},
+		{"share_token=wrong_token", http.StatusNotFound},
+		{"share_token=share_token", http.StatusOK},
+	} {
+		w := PrepareTest(nil, "GET")
+
+		c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
+		c.Request.URL = &url.URL{RawQuery: tc.rawQuery}
+
+		UsersController.GetFavorites(c)
+
+		c.Params = make([]gin.Param, 0)
+
+		assert.EqualValues(t, tc.status, w.Code, tc.rawQuery)
+	}
+
+	owner.FavoritesVisibility = users.FavoritesPublic
+	owner.FavoritesShareToken = ""
+	users_service_mock.MockDbID[1] = owner
+}
+
+func TestGetFavoritesSuspendedOwner(t *testing.T) {
+	owner := users_service_mock.MockDbID[3]
+	owner.FavoritesVisibility = users.FavoritesPublic
+	users_service_mock.MockDbID[3] = owner
+
+	w := PrepareTest(nil, "GET")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "3"})
+	c.Request.URL = &url.URL{}
+
+	UsersController.GetFavorites(c)
+
+	c.Params = make([]gin.Param, 0)
+
+	owner.FavoritesVisibility = users.FavoritesPrivate
+	users_service_mock.MockDbID[3] = owner
+
+	assert.EqualValues(t, http.StatusNotFound, w.Code)
+}
+
+func TestGetFavoritesInvalidUserID(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(
+		user_favorites.UserFavorites{
+			UserID:    1,
+			MoviesIDs: []int{},
+		},
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	w := PrepareTest(exampleJsonReq, "GET")
+
+	UsersController.GetFavorites(c)
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var receivedResponse rest_errors.RestErr
+	err = json.Unmarshal(responseData, &receivedResponse)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, w.Code)
+	assert.EqualValues(t, "User ID should be a number", receivedResponse.Message)
+	assert.EqualValues(t, "bad_request", receivedResponse.Err)
+	assert.EqualValues(t, http.StatusBadRequest, receivedResponse.Status)
+}
+
+func TestGetFavoritesFailure(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(
+		user_favorites.UserFavorites{
+			UserID:    1,
+			MoviesIDs: []int{},
+		},
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	w := PrepareTest(exampleJsonReq, "GET")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
+	c.Request.URL = &url.URL{}
+
+	users_service.UsersService.(*users_service_mock.UsersServiceMock).CanGetFavorites = false
+
+	UsersController.GetFavorites(c)
+
+	users_service.UsersService.(*users_service_mock.UsersServiceMock).CanGetFavorites = true
+
+	c.Params = make([]gin.Param, 0)
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var receivedResponse *rest_errors.RestErr
+	err = json.Unmarshal(responseData, &receivedResponse)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, receivedResponse)
+	assert.EqualValues(t, http.StatusInternalServerError, w.Code)
+	assert.EqualValues(t, "Error when trying to get user favorites", receivedResponse.Message)
+	assert.EqualValues(t, http.StatusInternalServerError, receivedResponse.Status)
+	assert.EqualValues(t, "internal_server_error", receivedResponse.Err)
+}
+
+func TestGetFavoritesFollowersOnly(t *testing.T) {
+	owner := users_service_mock.MockDbID[1]
+	owner.FavoritesVisibility = users.FavoritesFollowers
+	users_service_mock.MockDbID[1] = owner
+
+	usersServiceMock := users_service.UsersService.(*users_service_mock.UsersServiceMock)
+
+	for _, tc := range []struct {
+		follows []user_follows.UserFollow
+		status  int
+	}{
+		{nil, http.StatusNotFound},
+		{[]user_follows.UserFollow{{FollowerID: 3, FollowedID: 1}}, http.StatusOK},
+	} {
+		usersServiceMock.Follows = tc.follows
+
+		w := PrepareTest(nil, "GET")
+
+		c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
+		c.Request.URL = &url.URL{}
+		c.Request.Header.Set("Authorization", "token_3")
+
+		if authorization.OptionalAuthenticate()(c); !c.IsAborted() {
+			UsersController.GetFavorites(c)
+		}
+
+		c.Params = make([]gin.Param, 0)
+		c.Request.Header.Del("Authorization")
+
+		assert.EqualValues(t, tc.status, w.Code)
+	}
+
+	usersServiceMock.Follows = nil
+
+	owner.FavoritesVisibility = users.FavoritesPublic
+	users_service_mock.MockDbID[1] = owner
+}
+
+func TestAddFavoritesSuccessMovieCached(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(
+		movies.MovieInfo{
+			Movie: tmdb.Movie{
+				ID:    1,
+				Title: "Example Movie Title",
+			},
+			CreatedAt: "",
+		},
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	w := PrepareTest(exampleJsonReq, "POST")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
+	c.Request.Header.Set("Authorization", "token_1")
+
+	runAsOwner(UsersController.AddFavorite)
+
+	c.Params = make([]gin.Param, 0)
+	c.Request.Header.Del("Authorization")
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	receivedResponse := string(responseData[:])
+
+	assert.EqualValues(t, "", receivedResponse)
+	assert.EqualValues(t, http.StatusOK, w.Code)
+	assert.EqualValues(t, false, movies_service.MoviesService.(*movies_service_mock.MoviesServiceMock).AddedMovie)
+}
+
+func TestAddFavoritesSuccessMovieNotCached(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(
+		movies.MovieInfo{
+			Movie: tmdb.Movie{
+				ID:    1,
+				Title: "Example Movie Title",
+			},
+			CreatedAt: "",
+		},
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	w := PrepareTest(exampleJsonReq, "POST")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
+	c.Request.Header.Set("Authorization", "token_1")
+
+	movies_service.MoviesService.(*movies_service_mock.MoviesServiceMock).HasMovieCached = false
+
+	runAsOwner(UsersController.AddFavorite)
+
+	movies_service.MoviesService.(*movies_service_mock.MoviesServiceMock).HasMovieCached = true
+
+	c.Params = make([]gin.Param, 0)
+	c.Request.Header.Del("Authorization")
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	receivedResponse := string(responseData[:])
+
+	assert.EqualValues(t, "", receivedResponse)
+	assert.EqualValues(t, http.StatusOK, w.Code)
+	assert.EqualValues(t, true, movies_service.MoviesService.(*movies_service_mock.MoviesServiceMock).AddedMovie)
+}
+
+func TestAddFavoritesInvalidUserID(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(
+		movies.MovieInfo{
+			Movie: tmdb.Movie{
+				ID:    1,
+				Title: "Example Movie Title",
+			},
+			CreatedAt: "",
+		},
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	w := PrepareTest(exampleJsonReq, "POST")
+
+	c.Request.Header.Set("Authorization", "token_1")
+
+	runAsOwner(UsersController.AddFavorite)
+
+	c.Request.Header.Del("Authorization")
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var receivedResponse rest_errors.RestErr
+	err = json.Unmarshal(responseData, &receivedResponse)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, w.Code)
+	assert.EqualValues(t, "User ID should be a number", receivedResponse.Message)
+	assert.EqualValues(t, "bad_request", receivedResponse.Err)
+	assert.EqualValues(t, http.StatusBadRequest, receivedResponse.Status)
+}
+
+func TestAddFavoritesInvalidJSONBody(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(`{"invalid_key": "true"}`)
+	if err != nil {
+		panic(err)
+	}
+
+	w := PrepareTest(exampleJsonReq, "POST")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
+	c.Request.Header.Set("Authorization", "token_1")
+
+	runAsOwner(UsersController.AddFavorite)
+
+	c.Params = make([]gin.Param, 0)
+	c.Request.Header.Del("Authorization")
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var receivedResponse rest_errors.RestErr
+	err = json.Unmarshal(responseData, &receivedResponse)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, w.Code)
+	assert.EqualValues(t, "Invalid JSON body", receivedResponse.Message)
+	assert.EqualValues(t, "bad_request", receivedResponse.Err)
+	assert.EqualValues(t, http.StatusBadRequest, receivedResponse.Status)
+}
+
+func TestAddFavoritesGetMovieError(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(
+		movies.MovieInfo{
+			Movie: tmdb.Movie{
+				ID:    1,
+				Title: "Example Movie Title",
+			},
+			CreatedAt: "",
+		},
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	w := PrepareTest(exampleJsonReq, "POST")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
+	c.Request.Header.Set("Authorization", "token_1")
+
+	movies_service.MoviesService.(*movies_service_mock.MoviesServiceMock).CanGetMovie = false
+
+	runAsOwner(UsersController.AddFavorite)
+
+	movies_service.MoviesService.(*movies_service_mock.MoviesServiceMock).CanGetMovie = true
+
+	c.Params = make([]gin.Param, 0)
+	c.Request.Header.Del("Authorization")
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var receivedResponse rest_errors.RestErr
+	err = json.Unmarshal(responseData, &receivedResponse)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusInternalServerError, w.Code)
+	assert.EqualValues(t, "Error when trying to get movie", receivedResponse.Message)
+	assert.EqualValues(t, "internal_server_error", receivedResponse.Err)
+	assert.EqualValues(t, http.StatusInternalServerError, receivedResponse.Status)
+}
+
+func TestAddFavoritesAddMovieErrorWhenNotCached(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(
+		movies.MovieInfo{
+			Movie: tmdb.Movie{
+				ID:    1,
+				Title: "Example Movie Title",
+			},
+			CreatedAt: "",
+		},
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	w := PrepareTest(exampleJsonReq, "POST")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
+	c.Request.Header.Set("Authorization", "token_1")
+
+	movies_service.MoviesService.(*movies_service_mock.MoviesServiceMock).HasMovieCached = false
+	movies_service.MoviesService.(*movies_service_mock.MoviesServiceMock).CanAddMovie = false
+
+	runAsOwner(UsersController.AddFavorite)
+
+	movies_service.MoviesService.(*movies_service_mock.MoviesServiceMock).HasMovieCached = true
+	movies_service.MoviesService.(*movies_service_mock.MoviesServiceMock).CanAddMovie = true
+
+	c.Params = make([]gin.Param, 0)
+	c.Request.Header.Del("Authorization")
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var receivedResponse rest_errors.RestErr
+	err = json.Unmarshal(responseData, &receivedResponse)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusInternalServerError, w.Code)
+	assert.EqualValues(t, "Error when trying to add movie", receivedResponse.Message)
+	assert.EqualValues(t, "internal_server_error", receivedResponse.Err)
+	assert.EqualValues(t, http.StatusInternalServerError, receivedResponse.Status)
+}
+
+func TestAddFavoritesAddFavoriteError(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(
+		movies.MovieInfo{
+			Movie: tmdb.Movie{
+				ID:    1,
+				Title: "Example Movie Title",
+			},
+			CreatedAt: "",
+		},
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	w := PrepareTest(exampleJsonReq, "POST")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
+	c.Request.Header.Set("Authorization", "token_1")
+
+	users_service.UsersService.(*users_service_mock.UsersServiceMock).CanAddFavorite = false
+
+	runAsOwner(UsersController.AddFavorite)
+
+	users_service.UsersService.(*users_service_mock.UsersServiceMock).CanAddFavorite = false
+
+	c.Params = make([]gin.Param, 0)
+	c.Request.Header.Del("Authorization")
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var receivedResponse rest_errors.RestErr
+	err = json.Unmarshal(responseData, &receivedResponse)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusInternalServerError, w.Code)
+	assert.EqualValues(t, "Error when trying to add user favorite", receivedResponse.Message)
+	assert.EqualValues(t, "internal_server_error", receivedResponse.Err)
+	assert.EqualValues(t, http.StatusInternalServerError, receivedResponse.Status)
+}
+
+func TestReorderFavoritesSuccess(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(
+		user_favorites.UserFavorites{
+			MoviesIDs: []int{3, 1, 2},
+		},
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	w := PrepareTest(exampleJsonReq, "PUT")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
+	c.Request.Header.Set("Authorization", "token_1")
+
+	runAsOwner(UsersController.ReorderFavorites)
+
+	c.Params = make([]gin.Param, 0)
+	c.Request.Header.Del("Authorization")
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	receivedResponse := string(responseData[:])
+
+	assert.EqualValues(t, "", receivedResponse)
+	assert.EqualValues(t, http.StatusOK, w.Code)
+}
+
+func TestReorderFavoritesWrongTokenID(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(
+		user_favorites.UserFavorites{
+			MoviesIDs: []int{3, 1, 2},
+		},
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	w := PrepareTest(exampleJsonReq, "PUT")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
+	c.Request.Header.Set("Authorization", "token_1")
+
+	authorization.AuthManager.(*authorization_mock.AuthorizationMock).WrongID = true
+
+	runAsOwner(UsersController.ReorderFavorites)
+
+	authorization.AuthManager.(*authorization_mock.AuthorizationMock).WrongID = false
+
+	c.Params = make([]gin.Param, 0)
+	c.Request.Header.Del("Authorization")
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var receivedResponse rest_errors.RestErr
+	err = json.Unmarshal(responseData, &receivedResponse)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusUnauthorized, w.Code)
+	assert.EqualValues(t, "User ID in the request does not match token user ID", receivedResponse.Message)
+	assert.EqualValues(t, "unauthorized", receivedResponse.Err)
+	assert.EqualValues(t, http.StatusUnauthorized, receivedResponse.Status)
+}
+
+func TestReorderFavoritesReorderError(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(
+		user_favorites.UserFavorites{
+			MoviesIDs: []int{3, 1, 1},
+		},
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	w := PrepareTest(exampleJsonReq, "PUT")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
+	c.Request.Header.Set("Authorization", "token_1")
+
+	users_service.UsersService.(*users_service_mock.UsersServiceMock).CanReorder = false
+
+	runAsOwner(UsersController.ReorderFavorites)
+
+	users_service.UsersService.(*users_service_mock.UsersServiceMock).CanReorder = true
+
+	c.Params = make([]gin.Param, 0)
+	c.Request.Header.Del("Authorization")
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var receivedResponse rest_errors.RestErr
+	err = json.Unmarshal(responseData, &receivedResponse)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, w.Code)
+	assert.EqualValues(t, "New order must contain every favorite movie exactly once", receivedResponse.Message)
+	assert.EqualValues(t, "bad_request", receivedResponse.Err)
+	assert.EqualValues(t, http.StatusBadRequest, receivedResponse.Status)
+}
+
+func TestRemoveFavoriteSuccess(t *testing.T) {
+	exampleJsonReq, err := json.Marshal("")
+	if err != nil {
+		panic(err)
+	}
+
+	w := PrepareTest(exampleJsonReq, "DELETE")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
+	c.Params = append(c.Params, gin.Param{Key: "movie_id", Value: "1"})
+	c.Request.Header.Set("Authorization", "token_1")
+
+	runAsOwner(UsersController.RemoveFavorite)
+
+	c.Params = make([]gin.Param, 0)
+	c.Request.Header.Del("Authorization")
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	receivedResponse := string(responseData[:])
+
+	assert.EqualValues(t, "", receivedResponse)
+	assert.EqualValues(t, http.StatusOK, w.Code)
+}
+
+func TestRemoveFavoriteInvalidMovieID(t *testing.T) {
+	exampleJsonReq, err := json.Marshal("")
+	if err != nil {
+		panic(err)
+	}
+
+	w := PrepareTest(exampleJsonReq, "DELETE")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
+	c.Params = append(c.Params, gin.Param{Key: "movie_id", Value: "abc"})
+	c.Request.Header.Set("Authorization", "token_1")
+
+	runAsOwner(UsersController.RemoveFavorite)
+
+	c.Params = make([]gin.Param, 0)
+	c.Request.Header.Del("Authorization")
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var receivedResponse rest_errors.RestErr
+	err = json.Unmarshal(responseData, &receivedResponse)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, w.Code)
+	assert.EqualValues(t, "Movie ID should be a number", receivedResponse.Message)
+	assert.EqualValues(t, "bad_request", receivedResponse.Err)
+	assert.EqualValues(t, http.StatusBadRequest, receivedResponse.Status)
+}
+
+func TestRemoveFavoriteNotFound(t *testing.T) {
+	exampleJsonReq, err := json.Marshal("")
+	if err != nil {
+		panic(err)
+	}
+
+	w := PrepareTest(exampleJsonReq, "DELETE")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
+	c.Params = append(c.Params, gin.Param{Key: "movie_id", Value: "5"})
+	c.Request.Header.Set("Authorization", "token_1")
+
+	users_service.UsersService.(*users_service_mock.UsersServiceMock).CanRemove = false
+
+	runAsOwner(UsersController.RemoveFavorite)
+
+	users_service.UsersService.(*users_service_mock.UsersServiceMock).CanRemove = true
+
+	c.Params = make([]gin.Param, 0)
+	c.Request.Header.Del("Authorization")
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var receivedResponse rest_errors.RestErr
+	err = json.Unmarshal(responseData, &receivedResponse)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusNotFound, w.Code)
+	assert.EqualValues(t, "Movie is not in user favorites", receivedResponse.Message)
+	assert.EqualValues(t, "not_found", receivedResponse.Err)
+	assert.EqualValues(t, http.StatusNotFound, receivedResponse.Status)
+}
+
+func TestReplaceFavoriteSuccess(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(
+		movies.MovieInfo{
+			Movie: tmdb.Movie{
+				ID:    2,
+				Title: "Example Movie Title",
+			},
+			CreatedAt: "",
+		},
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	w := PrepareTest(exampleJsonReq, "PUT")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
+	c.Params = append(c.Params, gin.Param{Key: "rank", Value: "1"})
+	c.Request.Header.Set("Authorization", "token_1")
+
+	runAsOwner(UsersController.ReplaceFavorite)
+
+	c.Params = make([]gin.Param, 0)
+	c.Request.Header.Del("Authorization")
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	receivedResponse := string(responseData[:])
+
+	assert.EqualValues(t, "", receivedResponse)
+	assert.EqualValues(t, http.StatusOK, w.Code)
+}
+
+func TestReplaceFavoriteInvalidRank(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(
+		movies.MovieInfo{
+			Movie: tmdb.Movie{
+				ID:    2,
+				Title: "Example Movie Title",
+			},
+			CreatedAt: "",
+		},
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	w := PrepareTest(exampleJsonReq, "PUT")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
+	c.Params = append(c.Params, gin.Param{Key: "rank", Value: "11"})
+	c.Request.Header.Set("Authorization", "token_1")
+
+	runAsOwner(UsersController.ReplaceFavorite)
+
+	c.Params = make([]gin.Param, 0)
+	c.Request.Header.Del("Authorization")
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var receivedResponse rest_errors.RestErr
+	err = json.Unmarshal(responseData, &receivedResponse)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, w.Code)
+	assert.EqualValues(t, "Rank should be a number between 1 and 10", receivedResponse.Message)
+	assert.EqualValues(t, "bad_request", receivedResponse.Err)
+	assert.EqualValues(t, http.StatusBadRequest, receivedResponse.Status)
+}
+
+func TestReplaceFavoriteEmptyRank(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(
+		movies.MovieInfo{
+			Movie: tmdb.Movie{
+				ID:    2,
+				Title: "Example Movie Title",
+			},
+			CreatedAt: "",
+		},
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	w := PrepareTest(exampleJsonReq, "PUT")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
+	c.Params = append(c.Params, gin.Param{Key: "rank", Value: "3"})
+	c.Request.Header.Set("Authorization", "token_1")
+
+	users_service.UsersService.(*users_service_mock.UsersServiceMock).CanReplace = false
+
+	runAsOwner(UsersController.ReplaceFavorite)
+
+	users_service.UsersService.(*users_service_mock.UsersServiceMock).CanReplace = true
+
+	c.Params = make([]gin.Param, 0)
+	c.Request.Header.Del("Authorization")
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var receivedResponse rest_errors.RestErr
+	err = json.Unmarshal(responseData, &receivedResponse)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusNotFound, w.Code)
+	assert.EqualValues(t, "There is no favorite movie at position 3", receivedResponse.Message)
+	assert.EqualValues(t, "not_found", receivedResponse.Err)
+	assert.EqualValues(t, http.StatusNotFound, receivedResponse.Status)
+}
+
+func TestReplaceFavoriteGetMovieError(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(
+		movies.MovieInfo{
+			Movie: tmdb.Movie{
+				ID:    2,
+				Title: "Example Movie Title",
+			},
+			CreatedAt: "",
+		},
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	w := PrepareTest(exampleJsonReq, "PUT")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
+	c.Params = append(c.Params, gin.Param{Key: "rank", Value: "1"})
+	c.Request.Header.Set("Authorization", "token_1")
+
+	movies_service.MoviesService.(*movies_service_mock.MoviesServiceMock).CanGetMovie = false
+
+	runAsOwner(UsersController.ReplaceFavorite)
+
+	movies_service.MoviesService.(*movies_service_mock.MoviesServiceMock).CanGetMovie = true
+
+	c.Params = make([]gin.Param, 0)
+	c.Request.Header.Del("Authorization")
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var receivedResponse rest_errors.RestErr
+	err = json.Unmarshal(responseData, &receivedResponse)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusInternalServerError, w.Code)
+	assert.EqualValues(t, "Error when trying to get movie", receivedResponse.Message)
+	assert.EqualValues(t, "internal_server_error", receivedResponse.Err)
+	assert.EqualValues(t, http.StatusInternalServerError, receivedResponse.Status)
+}
+
+func TestListUsersSuccess(t *testing.T) {
+	w := PrepareTest(nil, "GET")
+
+	c.Request.URL = &url.URL{RawQuery: "limit=10&offset=0"}
+	c.Request.Header.Set("Authorization", "token_2")
+
+	authorization.AuthManager.(*authorization_mock.AuthorizationMock).Role = users.RoleModerator
+
+	runWithRole(UsersController.ListUsers, users.RoleModerator, users.RoleAdmin)
+
+	authorization.AuthManager.(*authorization_mock.AuthorizationMock).Role = ""
+
+	c.Request.Header.Del("Authorization")
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var listedUsers []users.User
+	err := json.Unmarshal(responseData, &listedUsers)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusOK, w.Code)
+	assert.EqualValues(t, 2, len(listedUsers))
+	assert.EqualValues(t, 1, listedUsers[0].ID)
+	assert.EqualValues(t, 3, listedUsers[1].ID)
+}
+
+func TestListUsersForbidden(t *testing.T) {
+	w := PrepareTest(nil, "GET")
+
+	c.Request.URL = &url.URL{}
+	c.Request.Header.Set("Authorization", "token_2")
+
+	authorization.AuthManager.(*authorization_mock.AuthorizationMock).Role = users.RoleUser
+
+	runWithRole(UsersController.ListUsers, users.RoleModerator, users.RoleAdmin)
+
+	authorization.AuthManager.(*authorization_mock.AuthorizationMock).Role = ""
+
+	c.Request.Header.Del("Authorization")
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var receivedResponse rest_errors.RestErr
+	err := json.Unmarshal(responseData, &receivedResponse)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusForbidden, w.Code)
+	assert.EqualValues(t, "User does not have permission to access this resource", receivedResponse.Message)
+}
+
+func TestListUsersInvalidLimit(t *testing.T) {
+	w := PrepareTest(nil, "GET")
+
+	c.Request.URL = &url.URL{RawQuery: "limit=1000"}
+
+	UsersController.ListUsers(c)
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var receivedResponse rest_errors.RestErr
+	err := json.Unmarshal(responseData, &receivedResponse)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, w.Code)
+	assert.EqualValues(t, "Limit should be a number between 1 and 100", receivedResponse.Message)
+}
+
+func TestUpdateUserStatusSuccess(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(map[string]string{"status": users.StatusSuspended})
+	if err != nil {
+		panic(err)
+	}
+
+	w := PrepareTest(exampleJsonReq, "PUT")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
+	c.Request.Header.Set("Authorization", "token_2")
+
+	authorization.AuthManager.(*authorization_mock.AuthorizationMock).Role = users.RoleAdmin
+
+	runWithRole(UsersController.UpdateUserStatus, users.RoleAdmin)
+
+	authorization.AuthManager.(*authorization_mock.AuthorizationMock).Role = ""
+
+	c.Params = make([]gin.Param, 0)
+	c.Request.Header.Del("Authorization")
+
+	assert.EqualValues(t, http.StatusOK, w.Code)
+}
+
+func TestUpdateUserStatusInvalidStatus(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(map[string]string{"status": "banned"})
+	if err != nil {
+		panic(err)
+	}
+
+	w := PrepareTest(exampleJsonReq, "PUT")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
+
+	UsersController.UpdateUserStatus(c)
+
+	c.Params = make([]gin.Param, 0)
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var receivedResponse rest_errors.RestErr
+	err = json.Unmarshal(responseData, &receivedResponse)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, w.Code)
+	assert.EqualValues(t, "Status should be one of: pending, active, suspended, deleted", receivedResponse.Message)
+}
+
+func TestUpdateUserStatusOwnAccount(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(map[string]string{"status": users.StatusSuspended})
+	if err != nil {
+		panic(err)
+	}
+
+	w := PrepareTest(exampleJsonReq, "PUT")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "2"})
+	c.Request.Header.Set("Authorization", "token_2")
+
+	authorization.AuthManager.(*authorization_mock.AuthorizationMock).Role = users.RoleAdmin
+
+	runWithRole(UsersController.UpdateUserStatus, users.RoleAdmin)
+
+	authorization.AuthManager.(*authorization_mock.AuthorizationMock).Role = ""
+
+	c.Params = make([]gin.Param, 0)
+	c.Request.Header.Del("Authorization")
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var receivedResponse rest_errors.RestErr
+	err = json.Unmarshal(responseData, &receivedResponse)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, w.Code)
+	assert.EqualValues(t, "Administrative actions cannot target your own account", receivedResponse.Message)
+}
+
+func TestUpdateUserRoleSuccess(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(map[string]string{"role": users.RoleModerator})
+	if err != nil {
+		panic(err)
+	}
+
+	w := PrepareTest(exampleJsonReq, "PUT")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
+	c.Request.Header.Set("Authorization", "token_2")
+
+	authorization.AuthManager.(*authorization_mock.AuthorizationMock).Role = users.RoleAdmin
+
+	runWithRole(UsersController.UpdateUserRole, users.RoleAdmin)
+
+	authorization.AuthManager.(*authorization_mock.AuthorizationMock).Role = ""
+
+	c.Params = make([]gin.Param, 0)
+	c.Request.Header.Del("Authorization")
+
+	assert.EqualValues(t, http.StatusOK, w.Code)
+}
+
+func TestUpdateUserRoleInvalidRole(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(map[string]string{"role": "superuser"})
+	if err != nil {
+		panic(err)
+	}
+
+	w := PrepareTest(exampleJsonReq, "PUT")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
+
+	UsersController.UpdateUserRole(c)
+
+	c.Params = make([]gin.Param, 0)
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var receivedResponse rest_errors.RestErr
+	err = json.Unmarshal(responseData, &receivedResponse)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, w.Code)
+	assert.EqualValues(t, "Role should be one of: user, moderator, admin", receivedResponse.Message)
+}
+
+func TestUpdateUserRoleNotFound(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(map[string]string{"role": users.RoleAdmin})
+	if err != nil {
+		panic(err)
+	}
+
+	w := PrepareTest(exampleJsonReq, "PUT")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "5"})
+
+	UsersController.UpdateUserRole(c)
+
+	c.Params = make([]gin.Param, 0)
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var receivedResponse rest_errors.RestErr
+	err = json.Unmarshal(responseData, &receivedResponse)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusNotFound, w.Code)
+	assert.EqualValues(t, "User not found", receivedResponse.Message)
+}
+
+func TestDeleteUserSuccess(t *testing.T) {
+	w := PrepareTest(nil, "DELETE")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
+	c.Request.Header.Set("Authorization", "token_2")
+
+	authorization.AuthManager.(*authorization_mock.AuthorizationMock).Role = users.RoleAdmin
+
+	runWithRole(UsersController.DeleteUser, users.RoleAdmin)
+
+	authorization.AuthManager.(*authorization_mock.AuthorizationMock).Role = ""
+
+	c.Params = make([]gin.Param, 0)
+	c.Request.Header.Del("Authorization")
+
+	assert.EqualValues(t, http.StatusOK, w.Code)
+}
+
+func TestDeleteUserForbidden(t *testing.T) {
+	w := PrepareTest(nil, "DELETE")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
+	c.Request.Header.Set("Authorization", "token_2")
+
+	authorization.AuthManager.(*authorization_mock.AuthorizationMock).Role = users.RoleModerator
+
+	runWithRole(UsersController.DeleteUser, users.RoleAdmin)
+
+	authorization.AuthManager.(*authorization_mock.AuthorizationMock).Role = ""
+
+	c.Params = make([]gin.Param, 0)
+	c.Request.Header.Del("Authorization")
+
+	assert.EqualValues(t, http.StatusForbidden, w.Code)
+}
+
+func TestUpdateUserStatusInvalidTransition(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(map[string]string{"status": users.StatusPending})
+	if err != nil {
+		panic(err)
+	}
+
+	w := PrepareTest(exampleJsonReq, "PUT")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
+
+	UsersController.UpdateUserStatus(c)
+
+	c.Params = make([]gin.Param, 0)
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var receivedResponse rest_errors.RestErr
+	err = json.Unmarshal(responseData, &receivedResponse)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, w.Code)
+	assert.EqualValues(t, "Cannot change user status from active to pending", receivedResponse.Message)
+}
+
+func TestLoginSuspendedUser(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(map[string]string{
+		"email":    "janedoe@gmail.com",
+		"password": "123456",
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	w := PrepareTest(exampleJsonReq, "POST")
+
+	UsersController.Login(c)
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var receivedResponse rest_errors.RestErr
+	err = json.Unmarshal(responseData, &receivedResponse)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusForbidden, w.Code)
+	assert.EqualValues(t, "User account is suspended", receivedResponse.Message)
+}
+
+func TestRefreshTokenSuspendedUser(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(map[string]string{"refresh_token": "refresh_token_3"})
+	if err != nil {
+		panic(err)
+	}
+
+	w := PrepareTest(exampleJsonReq, "POST")
+
+	authorization.AuthManager.(*authorization_mock.AuthorizationMock).RefreshUserID = 3
+
+	UsersController.RefreshToken(c)
+
+	authorization.AuthManager.(*authorization_mock.AuthorizationMock).RefreshUserID = 0
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var receivedResponse rest_errors.RestErr
+	err = json.Unmarshal(responseData, &receivedResponse)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusForbidden, w.Code)
+	assert.EqualValues(t, "User account is suspended", receivedResponse.Message)
+}
+
+func TestRefreshTokenPurgedUser(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(map[string]string{"refresh_token": "refresh_token_purged"})
+	if err != nil {
+		panic(err)
+	}
+
+	w := PrepareTest(exampleJsonReq, "POST")
+
+	runWithMemoryDB(func(db database.DatabaseClient) {
+		user := users.User{Email: "purged@gmail.com", Status: users.StatusDeleted, Role: users.RoleUser}
+		assert.Nil(t, user.Save(db))
+
+		saved, getErr := user.Get(db)
+		assert.Nil(t, getErr)
+
+		purged, purgeErr := users_service.UsersService.PurgeDeletedUsers(users.User{}, time.Now().Add(time.Hour))
+		assert.Nil(t, purgeErr)
+		assert.EqualValues(t, 1, purged)
+
+		authorization.AuthManager.(*authorization_mock.AuthorizationMock).RefreshUserID = saved.(users.User).ID
+
+		UsersController.RefreshToken(c)
+
+		authorization.AuthManager.(*authorization_mock.AuthorizationMock).RefreshUserID = 0
+	})
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var receivedResponse rest_errors.RestErr
+	err = json.Unmarshal(responseData, &receivedResponse)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusUnauthorized, w.Code)
+	assert.EqualValues(t, "Invalid refresh token", receivedResponse.Message)
+	assert.EqualValues(t, "unauthorized", receivedResponse.Err)
+}
+
+func TestVerifySuccess(t *testing.T) {
+	w := PrepareTest(nil, "GET")
+
+	c.Request.URL = &url.URL{RawQuery: "token=verification_token"}
+
+	UsersController.Verify(c)
+
+	assert.EqualValues(t, http.StatusOK, w.Code)
+}
+
+func TestVerifyMissingToken(t *testing.T) {
+	w := PrepareTest(nil, "GET")
+
+	c.Request.URL = &url.URL{}
+
+	UsersController.Verify(c)
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var receivedResponse rest_errors.RestErr
+	err := json.Unmarshal(responseData, &receivedResponse)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, w.Code)
+	assert.EqualValues(t, "Verification token is required", receivedResponse.Message)
+}
+
+func TestVerifyInvalidToken(t *testing.T) {
+	w := PrepareTest(nil, "GET")
+
+	c.Request.URL = &url.URL{RawQuery: "token=expired_token"}
+
+	users_service.UsersService.(*users_service_mock.UsersServiceMock).CanVerify = false
+
+	UsersController.Verify(c)
+
+	users_service.UsersService.(*users_service_mock.UsersServiceMock).CanVerify = true
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var receivedResponse rest_errors.RestErr
+	err := json.Unmarshal(responseData, &receivedResponse)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, w.Code)
+	assert.EqualValues(t, "Invalid or expired verification token", receivedResponse.Message)
+}
+
+func TestResendVerificationUnknownEmail(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(map[string]string{"email": "unknown@gmail.com"})
+	if err != nil {
+		panic(err)
+	}
+
+	w := PrepareTest(exampleJsonReq, "POST")
+
+	UsersController.ResendVerification(c)
+
+	assert.EqualValues(t, http.StatusOK, w.Code)
+	assert.NotContains(t, users_service.UsersService.(*users_service_mock.UsersServiceMock).VerificationsSent, "unknown@gmail.com")
+}
+
+func TestResendVerificationInvalidJSON(t *testing.T) {
+	w := PrepareTest([]byte("{}"), "POST")
+
+	UsersController.ResendVerification(c)
+
+	assert.EqualValues(t, http.StatusBadRequest, w.Code)
+}
+
+func TestChangePasswordSuccess(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(map[string]string{
+		"current_password": "123456",
+		"new_password":     "N3wSecretPass",
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	w := PrepareTest(exampleJsonReq, "POST")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
+	c.Request.Header.Set("Authorization", "token_1")
+
+	runAsOwner(UsersController.ChangePassword)
+
+	c.Params = make([]gin.Param, 0)
+	c.Request.Header.Del("Authorization")
+
+	assert.EqualValues(t, http.StatusOK, w.Code)
+	assert.Contains(t, users_service.UsersService.(*users_service_mock.UsersServiceMock).UpdatedPasswordsIDs, int64(1))
+}
+
+func TestChangePasswordWrongCurrentPassword(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(map[string]string{
+		"current_password": "12345",
+		"new_password":     "N3wSecretPass",
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	w := PrepareTest(exampleJsonReq, "POST")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
+	c.Request.Header.Set("Authorization", "token_1")
+
+	runAsOwner(UsersController.ChangePassword)
+
+	c.Params = make([]gin.Param, 0)
+	c.Request.Header.Del("Authorization")
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var receivedResponse rest_errors.RestErr
+	err = json.Unmarshal(responseData, &receivedResponse)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, w.Code)
+	assert.EqualValues(t, "Wrong password", receivedResponse.Message)
+}
+
+func TestChangePasswordInvalidNewPassword(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(map[string]string{
+		"current_password": "123456",
+		"new_password":     "   ",
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	w := PrepareTest(exampleJsonReq, "POST")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
+	c.Request.Header.Set("Authorization", "token_1")
+
+	runAsOwner(UsersController.ChangePassword)
+
+	c.Params = make([]gin.Param, 0)
+	c.Request.Header.Del("Authorization")
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var receivedResponse rest_errors.RestErr
+	err = json.Unmarshal(responseData, &receivedResponse)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, w.Code)
+	assert.EqualValues(t, "Invalid password", receivedResponse.Message)
+}
+
+func TestChangePasswordInvalidJSON(t *testing.T) {
+	w := PrepareTest([]byte("{}"), "POST")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
+	c.Request.Header.Set("Authorization", "token_1")
+
+	runAsOwner(UsersController.ChangePassword)
+
+	c.Params = make([]gin.Param, 0)
+	c.Request.Header.Del("Authorization")
+
+	assert.EqualValues(t, http.StatusBadRequest, w.Code)
+}
+
+func TestForgotPasswordSuccess(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(map[string]string{"email": "johndoe@gmail.com"})
+	if err != nil {
+		panic(err)
+	}
+
+	w := PrepareTest(exampleJsonReq, "POST")
+
+	UsersController.ForgotPassword(c)
+
+	assert.EqualValues(t, http.StatusOK, w.Code)
+	assert.Contains(t, users_service.UsersService.(*users_service_mock.UsersServiceMock).PasswordResetsSent, "johndoe@gmail.com")
+}
+
+func TestForgotPasswordSuspendedUser(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(map[string]string{"email": "janedoe@gmail.com"})
+	if err != nil {
+		panic(err)
+	}
+
+	w := PrepareTest(exampleJsonReq, "POST")
+
+	UsersController.ForgotPassword(c)
+
+	assert.EqualValues(t, http.StatusOK, w.Code)
+	assert.NotContains(t, users_service.UsersService.(*users_service_mock.UsersServiceMock).PasswordResetsSent, "janedoe@gmail.com")
+}
+
+func TestForgotPasswordUnknownEmail(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(map[string]string{"email": "unknown@gmail.com"})
+	if err != nil {
+		panic(err)
+	}
+
+	w := PrepareTest(exampleJsonReq, "POST")
+
+	UsersController.ForgotPassword(c)
+
+	assert.EqualValues(t, http.StatusOK, w.Code)
+	assert.NotContains(t, users_service.UsersService.(*users_service_mock.UsersServiceMock).PasswordResetsSent, "unknown@gmail.com")
+}
+
+func TestResetPasswordSuccess(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(map[string]string{
+		"token":        "reset_token",
+		"new_password": "N3wSecretPass",
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	w := PrepareTest(exampleJsonReq, "POST")
+
+	UsersController.ResetPassword(c)
+
+	assert.EqualValues(t, http.StatusOK, w.Code)
+}
+
+func TestResetPasswordInvalidToken(t *testing.T) {
+	exampleJsonReq, err := json.Marshal(map[string]string{
+		"token":        "expired_token",
+		"new_password": "N3wSecretPass",
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	w := PrepareTest(exampleJsonReq, "POST")
+
+	users_service.UsersService.(*users_service_mock.UsersServiceMock).CanResetPassword = false
+
+	UsersController.ResetPassword(c)
+
+	users_service.UsersService.(*users_service_mock.UsersServiceMock).CanResetPassword = true
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var receivedResponse rest_errors.RestErr
+	err = json.Unmarshal(responseData, &receivedResponse)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, w.Code)
+	assert.EqualValues(t, "Invalid or expired password reset token", receivedResponse.Message)
+}
+
+func TestResetPasswordInvalidJSON(t *testing.T) {
+	w := PrepareTest([]byte(`{"token": "reset_token"}`), "POST")
+
+	UsersController.ResetPassword(c)
+
+	assert.EqualValues(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSearchSuccess(t *testing.T) {
+	w := PrepareTest(nil, "GET")
+
+	c.Request.URL = &url.URL{RawQuery: "query=john+d"}
+
+	UsersController.Search(c)
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var receivedResponse struct {
+		Items []users.User `json:"items"`
+		Total int64        `json:"total"`
+		Limit int          `json:"limit"`
+	}
+	err := json.Unmarshal(responseData, &receivedResponse)
+
+	lastSearch := users_service.UsersService.(*users_service_mock.UsersServiceMock).LastSearch
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusOK, w.Code)
+	assert.EqualValues(t, 1, receivedResponse.Total)
+	assert.EqualValues(t, defaultListLimit, receivedResponse.Limit)
+	assert.EqualValues(t, 1, len(receivedResponse.Items))
+	assert.EqualValues(t, "", receivedResponse.Items[0].Password)
+	assert.EqualValues(t, "", receivedResponse.Items[0].Email)
+	assert.EqualValues(t, "John", receivedResponse.Items[0].FirstName)
+	assert.EqualValues(t, "john d", lastSearch.Query)
+	assert.EqualValues(t, users.SortByName, lastSearch.Sort)
+	assert.False(t, lastSearch.IncludeEmail)
+}
+
+func TestSearchAdminIncludesEmail(t *testing.T) {
+	w := PrepareTest(nil, "GET")
+
+	c.Request.URL = &url.URL{RawQuery: "query=john%40&sort=-date_created&limit=5"}
+	c.Request.Header.Set("Authorization", "token_2")
+
+	authorization.AuthManager.(*authorization_mock.AuthorizationMock).Role = users.RoleAdmin
+
+	if authorization.OptionalAuthenticate()(c); !c.IsAborted() {
+		UsersController.Search(c)
+	}
+
+	authorization.AuthManager.(*authorization_mock.AuthorizationMock).Role = ""
+
+	c.Request.Header.Del("Authorization")
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var receivedResponse struct {
+		Items []map[string]interface{} `json:"items"`
+	}
+	err := json.Unmarshal(responseData, &receivedResponse)
+
+	lastSearch := users_service.UsersService.(*users_service_mock.UsersServiceMock).LastSearch
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusOK, w.Code)
+	assert.EqualValues(t, "johndoe@gmail.com", receivedResponse.Items[0]["email"])
+	assert.NotContains(t, receivedResponse.Items[0], "password")
+	assert.EqualValues(t, "john@", lastSearch.Query)
+	assert.EqualValues(t, users.SortByDateCreatedDesc, lastSearch.Sort)
+	assert.EqualValues(t, 5, lastSearch.Limit)
+	assert.True(t, lastSearch.IncludeEmail)
+}
+
+func TestSearchInvalidSort(t *testing.T) {
+	w := PrepareTest(nil, "GET")
+
+	c.Request.URL = &url.URL{RawQuery: "query=john&sort=email"}
+
+	UsersController.Search(c)
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var receivedResponse rest_errors.RestErr
+	err := json.Unmarshal(responseData, &receivedResponse)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, w.Code)
+	assert.EqualValues(t, "Sort should be one of: name, -name, date_created, -date_created", receivedResponse.Message)
+}
+
+func TestSearchInvalidLimit(t *testing.T) {
+	w := PrepareTest(nil, "GET")
+
+	c.Request.URL = &url.URL{RawQuery: "query=john&limit=0"}
+
+	UsersController.Search(c)
+
+	assert.EqualValues(t, http.StatusBadRequest, w.Code)
+}
+
+func TestFollowAndUnfollowSuccess(t *testing.T) {
+	usersServiceMock := users_service.UsersService.(*users_service_mock.UsersServiceMock)
+
+	w := PrepareTest(nil, "POST")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
+	c.Request.Header.Set("Authorization", "token_3")
+
+	runAuthenticated(UsersController.Follow)
+
+	c.Params = make([]gin.Param, 0)
+	c.Request.Header.Del("Authorization")
+
+	assert.EqualValues(t, http.StatusOK, w.Code)
+	assert.EqualValues(t, []user_follows.UserFollow{{FollowerID: 3, FollowedID: 1}}, usersServiceMock.Follows)
+
+	w = PrepareTest(nil, "GET")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
+	c.Request.URL = &url.URL{}
+
+	UsersController.GetFollowers(c)
+
+	c.Params = make([]gin.Param, 0)
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var followers struct {
+		Items []users.PublicUser `json:"items"`
+		Total int64              `json:"total"`
+	}
+	err := json.Unmarshal(responseData, &followers)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusOK, w.Code)
+	assert.EqualValues(t, 1, followers.Total)
+	assert.EqualValues(t, 1, len(followers.Items))
+	assert.EqualValues(t, 3, followers.Items[0].ID)
+	assert.NotContains(t, string(responseData), "janedoe@gmail.com")
+
+	w = PrepareTest(nil, "DELETE")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
+	c.Request.Header.Set("Authorization", "token_3")
+
+	runAuthenticated(UsersController.Unfollow)
+
+	c.Params = make([]gin.Param, 0)
+	c.Request.Header.Del("Authorization")
+
+	assert.EqualValues(t, http.StatusOK, w.Code)
+	assert.Empty(t, usersServiceMock.Follows)
+}
+
+func TestFollowErrors(t *testing.T) {
+	for _, tc := range []struct {
+		userID  string
+		status  int
+		message string
+	}{
+		{"1", http.StatusBadRequest, "Users cannot follow themselves"},
+		{"3", http.StatusNotFound, "User not found"},
+		{"abc", http.StatusBadRequest, "User ID should be a number"},
+	} {
+		w := PrepareTest(nil, "POST")
+
+		c.Params = append(c.Params, gin.Param{Key: "user_id", Value: tc.userID})
+		c.Request.Header.Set("Authorization", "token_1")
+
+		runAuthenticated(UsersController.Follow)
+
+		c.Params = make([]gin.Param, 0)
+		c.Request.Header.Del("Authorization")
+
+		responseData, _ := ioutil.ReadAll(w.Body)
+
+		var receivedResponse rest_errors.RestErr
+		err := json.Unmarshal(responseData, &receivedResponse)
+
+		assert.Nil(t, err)
+		assert.EqualValues(t, tc.status, w.Code, tc.userID)
+		assert.EqualValues(t, tc.message, receivedResponse.Message)
+	}
+}
+
+func TestFollowUnauthorized(t *testing.T) {
+	w := PrepareTest(nil, "POST")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
+	c.Request.Header.Set("Authorization", "token_3")
+
+	authorization.AuthManager.(*authorization_mock.AuthorizationMock).Authorized = false
+
+	runAuthenticated(UsersController.Follow)
+
+	authorization.AuthManager.(*authorization_mock.AuthorizationMock).Authorized = true
+
+	c.Params = make([]gin.Param, 0)
+	c.Request.Header.Del("Authorization")
+
+	assert.EqualValues(t, http.StatusUnauthorized, w.Code)
+	assert.Empty(t, users_service.UsersService.(*users_service_mock.UsersServiceMock).Follows)
+}
+
+func TestUnfollowNotFollowing(t *testing.T) {
+	w := PrepareTest(nil, "DELETE")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "3"})
+	c.Request.Header.Set("Authorization", "token_1")
+
+	runAuthenticated(UsersController.Unfollow)
+
+	c.Params = make([]gin.Param, 0)
+	c.Request.Header.Del("Authorization")
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var receivedResponse rest_errors.RestErr
+	err := json.Unmarshal(responseData, &receivedResponse)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusNotFound, w.Code)
+	assert.EqualValues(t, "User is not being followed", receivedResponse.Message)
+}
+
+func TestGetFollowingInvalidLimit(t *testing.T) {
+	w := PrepareTest(nil, "GET")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
+	c.Request.URL = &url.URL{RawQuery: "limit=1000"}
+
+	UsersController.GetFollowing(c)
+
+	c.Params = make([]gin.Param, 0)
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var receivedResponse rest_errors.RestErr
+	err := json.Unmarshal(responseData, &receivedResponse)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, w.Code)
+	assert.EqualValues(t, "Limit should be a number between 1 and 100", receivedResponse.Message)
+}
+
+func TestGetFeedSuccess(t *testing.T) {
+	w := PrepareTest(nil, "GET")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
+	c.Request.URL = &url.URL{RawQuery: "limit=5&cursor=next_page"}
+	c.Request.Header.Set("Authorization", "token_1")
+
+	runAsOwner(UsersController.GetFeed)
+
+	c.Params = make([]gin.Param, 0)
+	c.Request.Header.Del("Authorization")
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var receivedResponse struct {
+		Items []user_favorites.FavoriteEvent `json:"items"`
+		Total int64                          `json:"total"`
+		Limit int                            `json:"limit"`
+	}
+	err := json.Unmarshal(responseData, &receivedResponse)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusOK, w.Code)
+	assert.EqualValues(t, 5, receivedResponse.Limit)
+	assert.EqualValues(t, 1, len(receivedResponse.Items))
+	assert.EqualValues(t, user_favorites.EventAdded, receivedResponse.Items[0].Type)
+	assert.EqualValues(t, "next_page", users_service.UsersService.(*users_service_mock.UsersServiceMock).LastFeedCursor)
+}
+
+func TestGetFeedFailure(t *testing.T) {
+	w := PrepareTest(nil, "GET")
+
+	c.Params = append(c.Params, gin.Param{Key: "user_id", Value: "1"})
+	c.Request.URL = &url.URL{}
+	c.Request.Header.Set("Authorization", "token_1")
+
+	users_service.UsersService.(*users_service_mock.UsersServiceMock).CanGetFeed = false
+
+	runAsOwner(UsersController.GetFeed)
+
+	users_service.UsersService.(*users_service_mock.UsersServiceMock).CanGetFeed = true
+
+	c.Params = make([]gin.Param, 0)
+	c.Request.Header.Del("Authorization")
+
+	responseData, _ := ioutil.ReadAll(w.Body)
+
+	var receivedResponse rest_errors.RestErr
+	err := json.Unmarshal(responseData, &receivedResponse)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusInternalServerError, w.Code)
+	assert.EqualValues(t, "Error when trying to get activity feed", receivedResponse.Message)
 }
@@ -0,0 +1,24 @@
+package cache
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrCacheMiss = errors.New("cache miss")
+)
+
+type CacheClient interface {
+	SetupCacheConnection()
+	CloseCacheConnection()
+	Get(key string) (string, error)
+	Set(key string, value interface{}, ttl time.Duration) error
+	Del(keys ...string) (int64, error)
+	MGet(keys ...string) (map[string]string, error)
+	// SAdd adds members to the set at key and resets the set ttl, as a single atomic operation
+	SAdd(key string, ttl time.Duration, members ...string) error
+	SRem(key string, members ...string) error
+	// SMembers returns an empty list when there is no set at key
+	SMembers(key string) ([]string, error)
+}
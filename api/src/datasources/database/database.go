@@ -1,6 +1,17 @@
 package database
 
-import "context"
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrNoRows is what SingleElementResult.Scan returns when the query matched no row, whatever the driver
+	ErrNoRows = errors.New("no rows in result set")
+
+	ErrNestedTransaction = errors.New("a transaction is already open")
+)
 
 type ModificationResult interface {
 	RowsAffected() int64
@@ -13,12 +24,60 @@ type SingleElementResult interface {
 type MultipleElementsResult interface {
 	Next() bool
 	Scan(...interface{}) error
+	Close()
 }
 
-type DatabaseClient interface {
-	SetupDbConnection()
-	CloseDbConnection(ctx context.Context)
+type PoolStats struct {
+	TotalConns           int32
+	IdleConns            int32
+	AcquiredConns        int32
+	ConstructingConns    int32
+	MaxConns             int32
+	AcquireCount         int64
+	EmptyAcquireCount    int64
+	CanceledAcquireCount int64
+	AcquireDuration      time.Duration
+}
+
+type Querier interface {
 	Query(ctx context.Context, query string, arguments ...interface{}) (MultipleElementsResult, error)
 	QueryRow(ctx context.Context, query string, arguments ...interface{}) (SingleElementResult, error)
 	Exec(ctx context.Context, query string, arguments ...interface{}) (ModificationResult, error)
 }
+
+type Transaction interface {
+	Querier
+	Commit(ctx context.Context) error
+	Rollback(ctx context.Context) error
+}
+
+type DatabaseClient interface {
+	Querier
+	SetupDbConnection()
+	CloseDbConnection(ctx context.Context)
+	BeginTx(ctx context.Context) (Transaction, error)
+	WithTx(ctx context.Context, fn func(tx Transaction) error) error
+}
+
+// txClient lets DAOs written against DatabaseClient run inside a transaction that is already
+// open. Their own WithTx calls join that transaction, so any failure rolls back all of it.
+type txClient struct {
+	Transaction
+}
+
+// InTransaction wraps tx as a DatabaseClient for use inside a WithTx callback
+func InTransaction(tx Transaction) DatabaseClient {
+	return txClient{Transaction: tx}
+}
+
+func (t txClient) SetupDbConnection() {}
+
+func (t txClient) CloseDbConnection(ctx context.Context) {}
+
+func (t txClient) BeginTx(ctx context.Context) (Transaction, error) {
+	return nil, ErrNestedTransaction
+}
+
+func (t txClient) WithTx(ctx context.Context, fn func(tx Transaction) error) error {
+	return fn(t.Transaction)
+}
@@ -0,0 +1,53 @@
+package filemailer
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ericbg27/top10movies-api/src/datasources/mailer"
+	"github.com/ericbg27/top10movies-api/src/utils/logger"
+)
+
+var (
+	nonFileCharacters = regexp.MustCompile(`[^a-z0-9@._-]+`)
+)
+
+// FileMailer writes every message to its own file under Path instead of
+// delivering it, so mail can be inspected offline. With an empty Path the
+// messages are only logged.
+type FileMailer struct {
+	Path string
+}
+
+func NewFileMailer(path string) *FileMailer {
+	return &FileMailer{
+		Path: path,
+	}
+}
+
+func messageFileName(message mailer.Message, sentAt time.Time) string {
+	recipient := nonFileCharacters.ReplaceAllString(strings.ToLower(message.To), "_")
+
+	return fmt.Sprintf("%d_%s.eml", sentAt.UnixNano(), recipient)
+}
+
+func (f *FileMailer) Send(message mailer.Message) error {
+	if f.Path == "" {
+		logger.Info(fmt.Sprintf("Mail to %s: %s\n%s", message.To, message.Subject, message.Body))
+
+		return nil
+	}
+
+	if err := os.MkdirAll(f.Path, 0755); err != nil {
+		return err
+	}
+
+	content := fmt.Sprintf("To: %s\nSubject: %s\n\n%s\n", message.To, message.Subject, message.Body)
+
+	return ioutil.WriteFile(filepath.Join(f.Path, messageFileName(message, time.Now())), []byte(content), 0644)
+}
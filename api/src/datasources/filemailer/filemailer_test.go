@@ -0,0 +1,46 @@
+package filemailer
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ericbg27/top10movies-api/src/datasources/mailer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessageFileName(t *testing.T) {
+	message := mailer.Message{To: "John Doe <JohnDoe@gmail.com>"}
+
+	assert.EqualValues(t, "0_john_doe_johndoe@gmail.com_.eml", messageFileName(message, time.Unix(0, 0)))
+}
+
+func TestSendWritesMessage(t *testing.T) {
+	path := t.TempDir()
+
+	var fileMailer mailer.Mailer = NewFileMailer(filepath.Join(path, "mail"))
+
+	err := fileMailer.Send(mailer.Message{
+		To:      "johndoe@gmail.com",
+		Subject: "Hello",
+		Body:    "Hello John",
+	})
+	assert.Nil(t, err)
+
+	files, err := ioutil.ReadDir(filepath.Join(path, "mail"))
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, len(files))
+
+	content, err := ioutil.ReadFile(filepath.Join(path, "mail", files[0].Name()))
+	assert.Nil(t, err)
+	assert.EqualValues(t, "To: johndoe@gmail.com\nSubject: Hello\n\nHello John\n", string(content))
+}
+
+func TestSendWithoutPathOnlyLogs(t *testing.T) {
+	fileMailer := NewFileMailer("")
+
+	err := fileMailer.Send(mailer.Message{To: "johndoe@gmail.com"})
+
+	assert.Nil(t, err)
+}
@@ -0,0 +1,99 @@
+package fixtureprovider
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ericbg27/top10movies-api/src/datasources/movieprovider"
+	"github.com/ryanbradynd05/go-tmdb"
+)
+
+const (
+	searchDir  = "search"
+	moviesDir  = "movies"
+	creditsDir = "credits"
+	imagesDir  = "images"
+)
+
+var (
+	nonSlugCharacters = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+type FixtureMovieProvider struct {
+	Path string
+}
+
+func NewFixtureMovieProvider(path string) *FixtureMovieProvider {
+	return &FixtureMovieProvider{
+		Path: path,
+	}
+}
+
+func searchFixtureName(query string) string {
+	return strings.Trim(nonSlugCharacters.ReplaceAllString(strings.ToLower(query), "_"), "_")
+}
+
+func (f *FixtureMovieProvider) load(dir string, name string, target interface{}) error {
+	content, err := ioutil.ReadFile(filepath.Join(f.Path, dir, name+".json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w: no %s fixture named %s", movieprovider.ErrMovieNotFound, dir, name)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(content, target)
+}
+
+func (f *FixtureMovieProvider) SearchMovies(query string, options map[string]string) (*tmdb.MovieSearchResults, error) {
+	var result tmdb.MovieSearchResults
+
+	err := f.load(searchDir, searchFixtureName(query), &result)
+	if errors.Is(err, movieprovider.ErrMovieNotFound) {
+		return &tmdb.MovieSearchResults{Page: 1}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (f *FixtureMovieProvider) GetMovieById(movieId int) (*tmdb.Movie, error) {
+	var result tmdb.Movie
+
+	if err := f.load(moviesDir, strconv.Itoa(movieId), &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (f *FixtureMovieProvider) GetMovieCredits(movieId int) (*tmdb.MovieCredits, error) {
+	var result tmdb.MovieCredits
+
+	if err := f.load(creditsDir, strconv.Itoa(movieId), &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (f *FixtureMovieProvider) GetMovieImages(movieId int) (*tmdb.MovieImages, error) {
+	var result tmdb.MovieImages
+
+	if err := f.load(imagesDir, strconv.Itoa(movieId), &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
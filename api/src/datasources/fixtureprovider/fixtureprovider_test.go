@@ -0,0 +1,78 @@
+package fixtureprovider
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ericbg27/top10movies-api/src/datasources/movieprovider"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	fixturesPath = "../../../fixtures/tmdb"
+)
+
+var (
+	provider movieprovider.MovieProvider = NewFixtureMovieProvider(fixturesPath)
+)
+
+func TestSearchFixtureName(t *testing.T) {
+	assert.EqualValues(t, "fight_club", searchFixtureName("Fight Club"))
+	assert.EqualValues(t, "the_matrix_1999", searchFixtureName("  The Matrix (1999) "))
+}
+
+func TestSearchMovies(t *testing.T) {
+	result, err := provider.SearchMovies("Fight Club", nil)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, result.TotalResults)
+	assert.EqualValues(t, 550, result.Results[0].ID)
+	assert.EqualValues(t, "Fight Club", result.Results[0].Title)
+}
+
+func TestSearchMoviesNoFixture(t *testing.T) {
+	result, err := provider.SearchMovies("Unrecorded Movie", nil)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, result.Page)
+	assert.EqualValues(t, 0, len(result.Results))
+}
+
+func TestGetMovieById(t *testing.T) {
+	result, err := provider.GetMovieById(550)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, 550, result.ID)
+	assert.EqualValues(t, "Fight Club", result.Title)
+	assert.EqualValues(t, "tt0137523", result.ImdbID)
+}
+
+func TestGetMovieByIdNotFound(t *testing.T) {
+	result, err := provider.GetMovieById(1)
+
+	assert.Nil(t, result)
+	assert.True(t, errors.Is(err, movieprovider.ErrMovieNotFound))
+}
+
+func TestGetMovieCredits(t *testing.T) {
+	result, err := provider.GetMovieCredits(550)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, 2, len(result.Cast))
+	assert.EqualValues(t, "Edward Norton", result.Cast[0].Name)
+	assert.EqualValues(t, "Director", result.Crew[0].Job)
+}
+
+func TestGetMovieImages(t *testing.T) {
+	result, err := provider.GetMovieImages(550)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, len(result.Backdrops))
+	assert.EqualValues(t, "/pB8BM7pdSp6B6Ih7QZ4DrQ3PmJK.jpg", result.Posters[0].FilePath)
+}
+
+func TestGetMovieImagesNotFound(t *testing.T) {
+	_, err := provider.GetMovieImages(1)
+
+	assert.True(t, errors.Is(err, movieprovider.ErrMovieNotFound))
+}
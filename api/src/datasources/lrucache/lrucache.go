@@ -0,0 +1,227 @@
+package lrucache
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ericbg27/top10movies-api/src/datasources/cache"
+	"github.com/ericbg27/top10movies-api/src/utils/logger"
+)
+
+const (
+	defaultCapacity = 10000
+)
+
+type LRUCacheClient struct {
+	Capacity int
+	// Now is the clock used for expirations, time.Now when not set
+	Now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+type entry struct {
+	key       string
+	value     string
+	members   []string
+	expiresAt time.Time
+}
+
+func (l *LRUCacheClient) SetupCacheConnection() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.Capacity <= 0 {
+		l.Capacity = defaultCapacity
+	}
+
+	l.entries = make(map[string]*list.Element)
+	l.order = list.New()
+
+	if l.Now == nil {
+		l.Now = time.Now
+	}
+
+	logger.Info(fmt.Sprintf("Using in-process LRU cache with capacity %d", l.Capacity))
+}
+
+func (l *LRUCacheClient) CloseCacheConnection() {}
+
+func (l *LRUCacheClient) lookup(key string) (*entry, bool) {
+	element, ok := l.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	cached := element.Value.(*entry)
+	if !cached.expiresAt.IsZero() && !l.Now().Before(cached.expiresAt) {
+		l.order.Remove(element)
+		delete(l.entries, key)
+
+		return nil, false
+	}
+
+	l.order.MoveToFront(element)
+
+	return cached, true
+}
+
+func (l *LRUCacheClient) Get(key string) (string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	cached, ok := l.lookup(key)
+	if !ok {
+		return "", cache.ErrCacheMiss
+	}
+
+	return cached.value, nil
+}
+
+func (l *LRUCacheClient) Set(key string, value interface{}, ttl time.Duration) error {
+	var stringValue string
+
+	switch v := value.(type) {
+	case string:
+		stringValue = v
+	case []byte:
+		stringValue = string(v)
+	default:
+		stringValue = fmt.Sprint(v)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	cached := l.entryFor(key)
+	cached.value = stringValue
+	cached.members = nil
+	cached.expiresAt = l.expiration(ttl)
+
+	return nil
+}
+
+// entryFor returns the live entry at key, adding an empty one when there is none
+func (l *LRUCacheClient) entryFor(key string) *entry {
+	if cached, ok := l.lookup(key); ok {
+		return cached
+	}
+
+	cached := &entry{key: key}
+	l.entries[key] = l.order.PushFront(cached)
+
+	for l.order.Len() > l.Capacity {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.entries, oldest.Value.(*entry).key)
+	}
+
+	return cached
+}
+
+func (l *LRUCacheClient) expiration(ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+
+	return l.Now().Add(ttl)
+}
+
+func (l *LRUCacheClient) Del(keys ...string) (int64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var deleted int64
+	for _, key := range keys {
+		if _, ok := l.lookup(key); ok {
+			l.order.Remove(l.entries[key])
+			delete(l.entries, key)
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
+
+func (l *LRUCacheClient) MGet(keys ...string) (map[string]string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	values := make(map[string]string)
+	for _, key := range keys {
+		if cached, ok := l.lookup(key); ok {
+			values[key] = cached.value
+		}
+	}
+
+	return values, nil
+}
+
+func (l *LRUCacheClient) SAdd(key string, ttl time.Duration, members ...string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	cached := l.entryFor(key)
+	for _, member := range members {
+		if !containsMember(cached.members, member) {
+			cached.members = append(cached.members, member)
+		}
+	}
+
+	cached.expiresAt = l.expiration(ttl)
+
+	return nil
+}
+
+func (l *LRUCacheClient) SRem(key string, members ...string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	cached, ok := l.lookup(key)
+	if !ok {
+		return nil
+	}
+
+	var kept []string
+	for _, member := range cached.members {
+		if !containsMember(members, member) {
+			kept = append(kept, member)
+		}
+	}
+
+	cached.members = kept
+
+	// Like Redis, a set left without members is removed
+	if len(kept) == 0 {
+		l.order.Remove(l.entries[key])
+		delete(l.entries, key)
+	}
+
+	return nil
+}
+
+func (l *LRUCacheClient) SMembers(key string) ([]string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	members := make([]string, 0)
+	if cached, ok := l.lookup(key); ok {
+		members = append(members, cached.members...)
+	}
+
+	return members, nil
+}
+
+func containsMember(members []string, member string) bool {
+	for _, current := range members {
+		if current == member {
+			return true
+		}
+	}
+
+	return false
+}
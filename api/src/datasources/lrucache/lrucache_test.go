@@ -0,0 +1,147 @@
+package lrucache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ericbg27/top10movies-api/src/datasources/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestCache(capacity int) (*LRUCacheClient, *time.Time) {
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	l := &LRUCacheClient{
+		Capacity: capacity,
+		Now: func() time.Time {
+			return now
+		},
+	}
+	l.SetupCacheConnection()
+
+	return l, &now
+}
+
+func TestSetAndGet(t *testing.T) {
+	l, _ := newTestCache(10)
+
+	assert.Nil(t, l.Set("key", []byte("value"), 0))
+
+	value, err := l.Get("key")
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, "value", value)
+}
+
+func TestGetMiss(t *testing.T) {
+	l, _ := newTestCache(10)
+
+	value, err := l.Get("missing")
+
+	assert.Equal(t, cache.ErrCacheMiss, err)
+	assert.EqualValues(t, "", value)
+}
+
+func TestExpiration(t *testing.T) {
+	l, now := newTestCache(10)
+
+	assert.Nil(t, l.Set("key", "value", time.Minute))
+
+	*now = now.Add(59 * time.Second)
+	_, err := l.Get("key")
+	assert.Nil(t, err)
+
+	*now = now.Add(time.Second)
+	_, err = l.Get("key")
+	assert.Equal(t, cache.ErrCacheMiss, err)
+}
+
+func TestEvictsLeastRecentlyUsed(t *testing.T) {
+	l, _ := newTestCache(2)
+
+	assert.Nil(t, l.Set("a", "1", 0))
+	assert.Nil(t, l.Set("b", "2", 0))
+
+	_, err := l.Get("a")
+	assert.Nil(t, err)
+
+	assert.Nil(t, l.Set("c", "3", 0))
+
+	_, err = l.Get("b")
+	assert.Equal(t, cache.ErrCacheMiss, err)
+
+	_, err = l.Get("a")
+	assert.Nil(t, err)
+
+	_, err = l.Get("c")
+	assert.Nil(t, err)
+}
+
+func TestDel(t *testing.T) {
+	l, _ := newTestCache(10)
+
+	assert.Nil(t, l.Set("a", "1", 0))
+	assert.Nil(t, l.Set("b", "2", 0))
+
+	deleted, err := l.Del("a", "b", "c")
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, 2, deleted)
+
+	_, err = l.Get("a")
+	assert.Equal(t, cache.ErrCacheMiss, err)
+}
+
+func TestMGet(t *testing.T) {
+	l, _ := newTestCache(10)
+
+	assert.Nil(t, l.Set("a", "1", 0))
+	assert.Nil(t, l.Set("c", "3", 0))
+
+	values, err := l.MGet("a", "b", "c")
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, map[string]string{"a": "1", "c": "3"}, values)
+}
+
+func TestSets(t *testing.T) {
+	l, _ := newTestCache(10)
+
+	assert.Nil(t, l.SAdd("set", time.Minute, "a", "b"))
+	assert.Nil(t, l.SAdd("set", time.Minute, "b", "c"))
+
+	members, err := l.SMembers("set")
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"a", "b", "c"}, members)
+
+	assert.Nil(t, l.SRem("set", "a", "c"))
+
+	members, err = l.SMembers("set")
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"b"}, members)
+
+	assert.Nil(t, l.SRem("set", "b"))
+
+	members, err = l.SMembers("set")
+	assert.Nil(t, err)
+	assert.EqualValues(t, 0, len(members))
+}
+
+func TestSetAddResetsExpiration(t *testing.T) {
+	l, now := newTestCache(10)
+
+	assert.Nil(t, l.SAdd("set", time.Minute, "a"))
+
+	*now = now.Add(50 * time.Second)
+	assert.Nil(t, l.SAdd("set", time.Minute, "b"))
+
+	*now = now.Add(50 * time.Second)
+	members, err := l.SMembers("set")
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"a", "b"}, members)
+
+	*now = now.Add(10 * time.Second)
+	members, err = l.SMembers("set")
+	assert.Nil(t, err)
+	assert.EqualValues(t, 0, len(members))
+}
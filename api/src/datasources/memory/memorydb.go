@@ -0,0 +1,264 @@
+package memorydb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/ericbg27/top10movies-api/src/datasources/database"
+	"github.com/ericbg27/top10movies-api/src/utils/logger"
+)
+
+var (
+	ErrNoRows            = database.ErrNoRows
+	ErrUnsupportedQuery  = errors.New("query not supported by the in-memory database")
+	ErrUniqueViolation   = errors.New("duplicate key value violates unique constraint")
+	ErrForeignKey        = errors.New("insert violates foreign key constraint")
+	ErrTransactionClosed = errors.New("transaction already closed")
+)
+
+type queryHandler func(s *state, arguments []interface{}) ([][]interface{}, int64, error)
+
+var (
+	handlers = make(map[string]queryHandler)
+)
+
+func register(query string, handler queryHandler) {
+	handlers[query] = handler
+}
+
+type MemoryDBClient struct {
+	mu    sync.Mutex
+	state *state
+}
+
+type memoryTransaction struct {
+	client   *MemoryDBClient
+	snapshot *state
+	closed   bool
+}
+
+type modificationResult struct {
+	affectedRows int64
+}
+
+type singleElementResult struct {
+	row []interface{}
+	err error
+}
+
+type multipleElementsResult struct {
+	rows  [][]interface{}
+	index int
+}
+
+func (m *MemoryDBClient) SetupDbConnection() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.state == nil {
+		m.state = newState()
+	}
+
+	logger.Info("Using in-memory database")
+}
+
+func (m *MemoryDBClient) CloseDbConnection(ctx context.Context) {}
+
+func (m *MemoryDBClient) run(query string, arguments []interface{}) ([][]interface{}, int64, error) {
+	handler, ok := handlers[query]
+	if !ok {
+		return nil, 0, fmt.Errorf("%w: %s", ErrUnsupportedQuery, query)
+	}
+
+	return handler(m.state, arguments)
+}
+
+func (m *MemoryDBClient) Query(ctx context.Context, query string, arguments ...interface{}) (database.MultipleElementsResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.query(query, arguments)
+}
+
+func (m *MemoryDBClient) QueryRow(ctx context.Context, query string, arguments ...interface{}) (database.SingleElementResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.queryRow(query, arguments)
+}
+
+func (m *MemoryDBClient) Exec(ctx context.Context, query string, arguments ...interface{}) (database.ModificationResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.exec(query, arguments)
+}
+
+func (m *MemoryDBClient) query(query string, arguments []interface{}) (database.MultipleElementsResult, error) {
+	rows, _, err := m.run(query, arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	return &multipleElementsResult{rows: rows}, nil
+}
+
+func (m *MemoryDBClient) queryRow(query string, arguments []interface{}) (database.SingleElementResult, error) {
+	rows, _, err := m.run(query, arguments)
+	if err != nil {
+		return &singleElementResult{err: err}, nil
+	}
+
+	if len(rows) == 0 {
+		return &singleElementResult{err: ErrNoRows}, nil
+	}
+
+	return &singleElementResult{row: rows[0]}, nil
+}
+
+func (m *MemoryDBClient) exec(query string, arguments []interface{}) (database.ModificationResult, error) {
+	_, affectedRows, err := m.run(query, arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	return modificationResult{affectedRows: affectedRows}, nil
+}
+
+// BeginTx holds the client lock until the transaction is committed or rolled
+// back, so transactions are fully serialized.
+func (m *MemoryDBClient) BeginTx(ctx context.Context) (database.Transaction, error) {
+	m.mu.Lock()
+
+	return &memoryTransaction{
+		client:   m,
+		snapshot: m.state.clone(),
+	}, nil
+}
+
+func (m *MemoryDBClient) WithTx(ctx context.Context, fn func(tx database.Transaction) error) error {
+	tx, err := m.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback(ctx)
+			panic(r)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		tx.Rollback(ctx)
+
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (t *memoryTransaction) Query(ctx context.Context, query string, arguments ...interface{}) (database.MultipleElementsResult, error) {
+	if t.closed {
+		return nil, ErrTransactionClosed
+	}
+
+	return t.client.query(query, arguments)
+}
+
+func (t *memoryTransaction) QueryRow(ctx context.Context, query string, arguments ...interface{}) (database.SingleElementResult, error) {
+	if t.closed {
+		return nil, ErrTransactionClosed
+	}
+
+	return t.client.queryRow(query, arguments)
+}
+
+func (t *memoryTransaction) Exec(ctx context.Context, query string, arguments ...interface{}) (database.ModificationResult, error) {
+	if t.closed {
+		return nil, ErrTransactionClosed
+	}
+
+	return t.client.exec(query, arguments)
+}
+
+func (t *memoryTransaction) Commit(ctx context.Context) error {
+	if t.closed {
+		return ErrTransactionClosed
+	}
+
+	t.closed = true
+	t.client.mu.Unlock()
+
+	return nil
+}
+
+func (t *memoryTransaction) Rollback(ctx context.Context) error {
+	if t.closed {
+		return ErrTransactionClosed
+	}
+
+	t.closed = true
+	t.client.state = t.snapshot
+	t.client.mu.Unlock()
+
+	return nil
+}
+
+func (m modificationResult) RowsAffected() int64 {
+	return m.affectedRows
+}
+
+func (s *singleElementResult) Scan(arguments ...interface{}) error {
+	if s.err != nil {
+		return s.err
+	}
+
+	return scanRow(s.row, arguments)
+}
+
+func (r *multipleElementsResult) Next() bool {
+	return r.index < len(r.rows)
+}
+
+func (r *multipleElementsResult) Scan(arguments ...interface{}) error {
+	if !r.Next() {
+		return ErrNoRows
+	}
+
+	row := r.rows[r.index]
+	r.index++
+
+	return scanRow(row, arguments)
+}
+
+func (r *multipleElementsResult) Close() {
+	r.index = len(r.rows)
+}
+
+func scanRow(row []interface{}, arguments []interface{}) error {
+	if len(arguments) != len(row) {
+		return fmt.Errorf("expected %d scan destinations, got %d", len(row), len(arguments))
+	}
+
+	for index, argument := range arguments {
+		destination := reflect.ValueOf(argument)
+		if destination.Kind() != reflect.Ptr || destination.IsNil() {
+			return fmt.Errorf("scan destination %d is not a pointer", index)
+		}
+
+		value := reflect.ValueOf(row[index])
+		target := destination.Elem()
+
+		if !value.Type().ConvertibleTo(target.Type()) {
+			return fmt.Errorf("cannot scan %s into %s", value.Type(), target.Type())
+		}
+
+		target.Set(value.Convert(target.Type()))
+	}
+
+	return nil
+}
@@ -0,0 +1,771 @@
+package memorydb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ericbg27/top10movies-api/src/datasources/database"
+	"github.com/ericbg27/top10movies-api/src/domain/movies"
+	"github.com/ericbg27/top10movies-api/src/domain/user_favorites"
+	"github.com/ericbg27/top10movies-api/src/domain/user_follows"
+	"github.com/ericbg27/top10movies-api/src/domain/user_profiles"
+	"github.com/ericbg27/top10movies-api/src/domain/users"
+	cache_mock "github.com/ericbg27/top10movies-api/src/mocks/cache"
+	user_favorites_queries "github.com/ericbg27/top10movies-api/src/queries/user_favorites"
+	"github.com/ericbg27/top10movies-api/src/utils/rest_errors"
+	"github.com/ryanbradynd05/go-tmdb"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	db database.DatabaseClient
+)
+
+func TestMain(m *testing.M) {
+	db = &MemoryDBClient{}
+
+	db.SetupDbConnection()
+	defer db.CloseDbConnection(context.Background())
+
+	os.Exit(m.Run())
+}
+
+func newTestUser(t *testing.T, email string) users.User {
+	user := users.User{
+		FirstName:   "John",
+		LastName:    "Doe",
+		Email:       email,
+		DateCreated: "2021-01-01",
+		Status:      users.StatusActive,
+		Password:    "1234",
+		Role:        users.RoleUser,
+	}
+
+	assert.Nil(t, user.Save(db))
+
+	result, err := user.Get(db)
+	assert.Nil(t, err)
+
+	return result.(users.User)
+}
+
+func favoriteIds(t *testing.T, userID int64) []int {
+	result, err := db.Query(context.Background(), user_favorites_queries.QueryGetUserFavoritesIds, userID)
+	assert.Nil(t, err)
+
+	var ids []int
+	for result.Next() {
+		var movieId int
+		assert.Nil(t, result.Scan(&movieId))
+		ids = append(ids, movieId)
+	}
+
+	return ids
+}
+
+func TestUserLifecycle(t *testing.T) {
+	user := newTestUser(t, "lifecycle@gmail.com")
+
+	assert.NotZero(t, user.ID)
+	assert.EqualValues(t, "John", user.FirstName)
+	assert.EqualValues(t, "1234", user.Password)
+
+	update := users.User{FirstName: "Johnny"}
+	result, err := user.Update(update, true, db)
+	assert.Nil(t, err)
+	assert.EqualValues(t, "Johnny", result.(users.User).FirstName)
+
+	fetched, err := user.GetById(db)
+	assert.Nil(t, err)
+	assert.EqualValues(t, "Johnny", fetched.(users.User).FirstName)
+
+	criteria := users.SearchCriteria{Query: "johnny d", Sort: users.SortByName, Limit: 100}
+
+	found, err := users.User{}.Search(criteria, db)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, found.Items)
+
+	assert.Nil(t, user.Delete(db))
+
+	deleted, err := user.GetById(db)
+	assert.Nil(t, err)
+	assert.EqualValues(t, users.StatusDeleted, deleted.(users.User).Status)
+
+	found, err = users.User{}.Search(criteria, db)
+	assert.Nil(t, err)
+	for _, foundUser := range found.Items.([]users.UserInterface) {
+		assert.NotEqual(t, user.ID, foundUser.(users.User).ID)
+	}
+
+	purged, err := users.User{}.PurgeDeleted(time.Now().Add(time.Hour), db)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, purged)
+
+	_, err = user.GetById(db)
+	assert.NotNil(t, err)
+}
+
+func TestSearchPagination(t *testing.T) {
+	for index, firstName := range []string{"Carol", "Alice", "Bob"} {
+		user := users.User{
+			FirstName:   firstName,
+			LastName:    "Pager",
+			Email:       firstName + "@pager.com",
+			DateCreated: fmt.Sprintf("2021-01-0%d", index+1),
+			Status:      users.StatusActive,
+			Password:    "1234",
+			Role:        users.RoleUser,
+		}
+		assert.Nil(t, user.Save(db))
+	}
+
+	criteria := users.SearchCriteria{Query: "pager", Sort: users.SortByName, Limit: 2}
+
+	firstPage, err := users.User{}.Search(criteria, db)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 3, firstPage.Total)
+	assert.NotEmpty(t, firstPage.NextCursor)
+
+	firstItems := firstPage.Items.([]users.UserInterface)
+	assert.EqualValues(t, 2, len(firstItems))
+	assert.EqualValues(t, "Alice", firstItems[0].(users.User).FirstName)
+	assert.EqualValues(t, "Bob", firstItems[1].(users.User).FirstName)
+	assert.EqualValues(t, "", firstItems[0].(users.User).Password)
+
+	criteria.Cursor = firstPage.NextCursor
+
+	secondPage, err := users.User{}.Search(criteria, db)
+	assert.Nil(t, err)
+	assert.Empty(t, secondPage.NextCursor)
+
+	secondItems := secondPage.Items.([]users.UserInterface)
+	assert.EqualValues(t, 1, len(secondItems))
+	assert.EqualValues(t, "Carol", secondItems[0].(users.User).FirstName)
+
+	newest, err := users.User{}.Search(users.SearchCriteria{Query: "pager", Sort: users.SortByDateCreatedDesc, Limit: 1}, db)
+	assert.Nil(t, err)
+	assert.EqualValues(t, "Bob", newest.Items.([]users.UserInterface)[0].(users.User).FirstName)
+	assert.EqualValues(t, "2021-01-03", newest.Items.([]users.UserInterface)[0].(users.User).DateCreated)
+
+	_, err = users.User{}.Search(users.SearchCriteria{Query: "pager", Sort: users.SortByNameDesc, Limit: 2, Cursor: firstPage.NextCursor}, db)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status)
+	assert.EqualValues(t, "Invalid cursor", err.Message)
+
+	byEmail := users.SearchCriteria{Query: "alice@", Sort: users.SortByName, Limit: 10}
+
+	hidden, err := users.User{}.Search(byEmail, db)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 0, hidden.Total)
+	assert.Empty(t, hidden.Items)
+
+	byEmail.IncludeEmail = true
+
+	shown, err := users.User{}.Search(byEmail, db)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, shown.Total)
+
+	wildcard, err := users.User{}.Search(users.SearchCriteria{Query: "%", Sort: users.SortByName, Limit: 10}, db)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 0, wildcard.Total)
+}
+
+func TestSearchOnlyListsActiveUsers(t *testing.T) {
+	for _, status := range []string{users.StatusPending, users.StatusSuspended} {
+		user := users.User{
+			FirstName:   "Hidden",
+			LastName:    "Searchstatus",
+			Email:       status + "@searchstatus.com",
+			DateCreated: "2021-01-01",
+			Status:      status,
+			Password:    "1234",
+			Role:        users.RoleUser,
+		}
+		assert.Nil(t, user.Save(db))
+	}
+
+	criteria := users.SearchCriteria{Query: "searchstatus", Sort: users.SortByName, Limit: 10}
+
+	found, err := users.User{}.Search(criteria, db)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 0, found.Total)
+	assert.Empty(t, found.Items)
+
+	criteria.IncludeEmail = true
+
+	found, err = users.User{}.Search(criteria, db)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 2, found.Total)
+	assert.EqualValues(t, 2, len(found.Items.([]users.UserInterface)))
+}
+
+func TestUserProfiles(t *testing.T) {
+	user := newTestUser(t, "profile@gmail.com")
+	otherUser := newTestUser(t, "otherprofile@gmail.com")
+
+	_, err := user_profiles.UserProfile{UserID: user.ID}.Get(db)
+	assert.EqualValues(t, http.StatusNotFound, err.Status)
+
+	created, err := user_profiles.UserProfile{UserID: user.ID}.Update(user_profiles.UserProfile{
+		UserID:         user.ID,
+		Handle:         "ProfileUser",
+		Bio:            "Horror movies all day",
+		FavoriteGenres: []string{"Horror"},
+	}, true, db)
+	assert.Nil(t, err)
+	assert.EqualValues(t, "profileuser", created.(user_profiles.UserProfile).Handle)
+
+	updated, err := created.Update(user_profiles.UserProfile{UserID: user.ID, DisplayName: "Profile"}, true, db)
+	assert.Nil(t, err)
+	assert.EqualValues(t, "Horror movies all day", updated.(user_profiles.UserProfile).Bio)
+
+	found, err := user_profiles.UserProfile{Handle: "PROFILEUSER"}.GetByHandle(db)
+	assert.Nil(t, err)
+	assert.EqualValues(t, user.ID, found.(user_profiles.UserProfile).UserID)
+	assert.EqualValues(t, "Profile", found.(user_profiles.UserProfile).DisplayName)
+	assert.EqualValues(t, []string{"Horror"}, found.(user_profiles.UserProfile).FavoriteGenres)
+
+	_, err = user_profiles.UserProfile{UserID: otherUser.ID}.Update(user_profiles.UserProfile{UserID: otherUser.ID, Handle: "profileuser"}, true, db)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status)
+	assert.EqualValues(t, "Handle is already taken", err.Message)
+
+	assert.Nil(t, user.UpdateStatus(users.StatusSuspended, db))
+
+	_, err = user_profiles.UserProfile{Handle: "profileuser"}.GetByHandle(db)
+	assert.EqualValues(t, http.StatusNotFound, err.Status)
+}
+
+func TestFollows(t *testing.T) {
+	follower := newTestUser(t, "follower@gmail.com")
+	followed := newTestUser(t, "followed@gmail.com")
+
+	follow := user_follows.UserFollow{FollowerID: follower.ID, FollowedID: followed.ID}
+	assert.Nil(t, follow.Follow(db))
+	assert.Nil(t, follow.Follow(db))
+
+	followers, err := follow.GetFollowers(100, "", db)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, followers.Total)
+	assert.Empty(t, followers.NextCursor)
+	assert.EqualValues(t, 1, len(followers.Items.([]users.UserInterface)))
+	assert.EqualValues(t, follower.ID, followers.Items.([]users.UserInterface)[0].(users.User).ID)
+
+	following, err := follow.GetFollowing(100, "", db)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, len(following.Items.([]users.UserInterface)))
+	assert.EqualValues(t, followed.ID, following.Items.([]users.UserInterface)[0].(users.User).ID)
+
+	err = user_follows.UserFollow{FollowerID: follower.ID, FollowedID: -1}.Follow(db)
+	assert.EqualValues(t, http.StatusNotFound, err.Status)
+
+	err = user_follows.UserFollow{FollowerID: follower.ID, FollowedID: follower.ID}.Follow(db)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status)
+
+	assert.Nil(t, follow.Unfollow(db))
+
+	err = follow.Unfollow(db)
+	assert.EqualValues(t, http.StatusNotFound, err.Status)
+
+	followers, err = follow.GetFollowers(100, "", db)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 0, followers.Total)
+	assert.Empty(t, followers.Items)
+}
+
+func TestFollowersPages(t *testing.T) {
+	followed := newTestUser(t, "pagedfollowed@gmail.com")
+
+	var followerIDs []int64
+	for _, email := range []string{"pagedfollowera@gmail.com", "pagedfollowerb@gmail.com", "pagedfollowerc@gmail.com"} {
+		follower := newTestUser(t, email)
+		assert.Nil(t, user_follows.UserFollow{FollowerID: follower.ID, FollowedID: followed.ID}.Follow(db))
+
+		followerIDs = append(followerIDs, follower.ID)
+	}
+
+	follow := user_follows.UserFollow{FollowedID: followed.ID}
+
+	firstPage, err := follow.GetFollowers(2, "", db)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 3, firstPage.Total)
+	assert.NotEmpty(t, firstPage.NextCursor)
+
+	firstItems := firstPage.Items.([]users.UserInterface)
+	assert.EqualValues(t, 2, len(firstItems))
+	assert.EqualValues(t, followerIDs[2], firstItems[0].(users.User).ID)
+	assert.EqualValues(t, followerIDs[1], firstItems[1].(users.User).ID)
+
+	secondPage, err := follow.GetFollowers(2, firstPage.NextCursor, db)
+	assert.Nil(t, err)
+	assert.Empty(t, secondPage.NextCursor)
+
+	secondItems := secondPage.Items.([]users.UserInterface)
+	assert.EqualValues(t, 1, len(secondItems))
+	assert.EqualValues(t, followerIDs[0], secondItems[0].(users.User).ID)
+
+	_, err = follow.GetFollowers(2, "not-a-cursor", db)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status)
+	assert.EqualValues(t, "Invalid cursor", err.Message)
+}
+
+func TestFollowersOnlyFavorites(t *testing.T) {
+	owner := newTestUser(t, "followersonly@gmail.com")
+	follower := newTestUser(t, "followersonlyfan@gmail.com")
+	stranger := newTestUser(t, "followersonlystranger@gmail.com")
+
+	owner.FavoritesVisibility = users.FavoritesFollowers
+	_, err := owner.UpdateFavoritesVisibility(db)
+	assert.Nil(t, err)
+
+	assert.Nil(t, user_follows.UserFollow{FollowerID: follower.ID, FollowedID: owner.ID}.Follow(db))
+
+	fetched, err := owner.GetById(db)
+	assert.Nil(t, err)
+
+	visible, err := fetched.CanViewFavorites(follower.ID, "", db)
+	assert.Nil(t, err)
+	assert.True(t, visible)
+
+	visible, err = fetched.CanViewFavorites(stranger.ID, "", db)
+	assert.Nil(t, err)
+	assert.False(t, visible)
+
+	visible, err = fetched.CanViewFavorites(0, "", db)
+	assert.Nil(t, err)
+	assert.False(t, visible)
+
+	// Following needs no approval from the owner, so followers-only is not access control: any
+	// signed in user sees the list right after following
+	assert.Nil(t, user_follows.UserFollow{FollowerID: stranger.ID, FollowedID: owner.ID}.Follow(db))
+
+	visible, err = fetched.CanViewFavorites(stranger.ID, "", db)
+	assert.Nil(t, err)
+	assert.True(t, visible)
+}
+
+func TestFavoritesFeed(t *testing.T) {
+	follower := newTestUser(t, "feedfollower@gmail.com")
+	followed := newTestUser(t, "feedfollowed@gmail.com")
+	hidden := newTestUser(t, "feedhidden@gmail.com")
+
+	for _, user := range []users.User{followed, hidden} {
+		assert.Nil(t, user_follows.UserFollow{FollowerID: follower.ID, FollowedID: user.ID}.Follow(db))
+	}
+
+	hidden.FavoritesVisibility = users.FavoritesPrivate
+	_, err := hidden.UpdateFavoritesVisibility(db)
+	assert.Nil(t, err)
+
+	for _, movieId := range []int{10, 20, 30} {
+		assert.Nil(t, user_favorites.UserFavorites{UserID: followed.ID, MoviesIDs: []int{movieId}}.AddFavorite(db))
+	}
+	assert.Nil(t, user_favorites.UserFavorites{UserID: hidden.ID, MoviesIDs: []int{10}}.AddFavorite(db))
+
+	assert.Nil(t, user_favorites.UserFavorites{UserID: followed.ID, MoviesIDs: []int{30, 10, 20}}.ReorderFavorites(db))
+	assert.Nil(t, user_favorites.UserFavorites{UserID: followed.ID, MoviesIDs: []int{40}}.ReplaceFavorite(3, db))
+	assert.Nil(t, user_favorites.UserFavorites{UserID: followed.ID, MoviesIDs: []int{30}}.RemoveFavorite(db))
+
+	// A rejected change does not show up in the feed
+	err = user_favorites.UserFavorites{UserID: followed.ID, MoviesIDs: []int{99}}.RemoveFavorite(db)
+	assert.EqualValues(t, http.StatusNotFound, err.Status)
+
+	feed, err := user_favorites.UserFavorites{UserID: follower.ID}.GetFeed(100, "", db)
+	assert.Nil(t, err)
+
+	var summary []string
+	for _, event := range feed.Items.([]user_favorites.FavoriteEvent) {
+		assert.EqualValues(t, followed.ID, event.UserID)
+		assert.EqualValues(t, "John", event.FirstName)
+		summary = append(summary, fmt.Sprintf("%s %d %d->%d", event.Type, event.MovieID, event.PreviousRank, event.Rank))
+	}
+
+	assert.EqualValues(t, []string{
+		"removed 30 0->1",
+		"added 40 0->3",
+		"removed 20 0->3",
+		"reranked 20 2->3",
+		"reranked 10 1->2",
+		"reranked 30 3->1",
+		"added 30 0->3",
+		"added 20 0->2",
+		"added 10 0->1",
+	}, summary)
+	assert.EqualValues(t, 9, feed.Total)
+	assert.Empty(t, feed.NextCursor)
+
+	firstPage, err := user_favorites.UserFavorites{UserID: follower.ID}.GetFeed(4, "", db)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 4, len(firstPage.Items.([]user_favorites.FavoriteEvent)))
+	assert.NotEmpty(t, firstPage.NextCursor)
+
+	secondPage, err := user_favorites.UserFavorites{UserID: follower.ID}.GetFeed(10, firstPage.NextCursor, db)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 5, len(secondPage.Items.([]user_favorites.FavoriteEvent)))
+	assert.EqualValues(t, "reranked", secondPage.Items.([]user_favorites.FavoriteEvent)[0].Type)
+
+	_, err = user_favorites.UserFavorites{UserID: follower.ID}.GetFeed(10, "not a cursor", db)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status)
+
+	assert.Nil(t, user_follows.UserFollow{FollowerID: follower.ID, FollowedID: followed.ID}.Unfollow(db))
+
+	feed, err = user_favorites.UserFavorites{UserID: follower.ID}.GetFeed(100, "", db)
+	assert.Nil(t, err)
+	assert.Empty(t, feed.Items)
+}
+
+func TestPurgeKeepsUsersWithinGracePeriod(t *testing.T) {
+	user := newTestUser(t, "grace@gmail.com")
+
+	assert.Nil(t, user.Delete(db))
+
+	purged, err := users.User{}.PurgeDeleted(time.Now().Add(-time.Hour), db)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 0, purged)
+
+	restored, err := user.GetById(db)
+	assert.Nil(t, err)
+	assert.Nil(t, restored.(users.User).UpdateStatus(users.StatusActive, db))
+
+	fetched, err := user.GetById(db)
+	assert.Nil(t, err)
+	assert.EqualValues(t, users.StatusActive, fetched.(users.User).Status)
+}
+
+func TestUpdateStatusFromStaleStatus(t *testing.T) {
+	user := newTestUser(t, "stalestatus@gmail.com")
+
+	assert.Nil(t, user.UpdateStatus(users.StatusSuspended, db))
+
+	// user still holds the active status, so deleting it from there must not apply
+	err := user.UpdateStatus(users.StatusDeleted, db)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusConflict, err.Status)
+	assert.EqualValues(t, "User status was changed by another request", err.Message)
+
+	fetched, getErr := user.GetById(db)
+	assert.Nil(t, getErr)
+	assert.EqualValues(t, users.StatusSuspended, fetched.(users.User).Status)
+}
+
+func TestUserStatusAndRole(t *testing.T) {
+	user := newTestUser(t, "admin@gmail.com")
+
+	assert.EqualValues(t, users.RoleUser, user.Role)
+
+	assert.Nil(t, user.UpdateStatus(users.StatusSuspended, db))
+
+	user.Role = users.RoleAdmin
+	assert.Nil(t, user.UpdateRole(db))
+
+	fetched, err := user.GetById(db)
+	assert.Nil(t, err)
+	assert.EqualValues(t, users.StatusSuspended, fetched.(users.User).Status)
+	assert.EqualValues(t, users.RoleAdmin, fetched.(users.User).Role)
+
+	missing := users.User{ID: -1, Role: users.RoleAdmin}
+	err = missing.UpdateRole(db)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusNotFound, err.Status)
+}
+
+func TestFavoritesVisibility(t *testing.T) {
+	user := newTestUser(t, "visibility@gmail.com")
+
+	assert.EqualValues(t, users.FavoritesPublic, user.FavoritesVisibility)
+	assert.EqualValues(t, "", user.FavoritesShareToken)
+
+	user.FavoritesVisibility = users.FavoritesUnlisted
+	result, err := user.UpdateFavoritesVisibility(db)
+	assert.Nil(t, err)
+
+	shareToken := result.(users.User).FavoritesShareToken
+	assert.NotEmpty(t, shareToken)
+
+	fetched, err := user.GetById(db)
+	assert.Nil(t, err)
+	assert.EqualValues(t, users.FavoritesUnlisted, fetched.(users.User).FavoritesVisibility)
+	assert.EqualValues(t, shareToken, fetched.(users.User).FavoritesShareToken)
+	visible, err := fetched.CanViewFavorites(0, shareToken, db)
+	assert.Nil(t, err)
+	assert.True(t, visible)
+
+	user.FavoritesVisibility = users.FavoritesPrivate
+	_, err = user.UpdateFavoritesVisibility(db)
+	assert.Nil(t, err)
+
+	fetched, err = user.GetById(db)
+	assert.Nil(t, err)
+	assert.EqualValues(t, "", fetched.(users.User).FavoritesShareToken)
+	visible, err = fetched.CanViewFavorites(0, shareToken, db)
+	assert.Nil(t, err)
+	assert.False(t, visible)
+
+	missing := users.User{ID: -1, FavoritesVisibility: users.FavoritesPublic}
+	_, err = missing.UpdateFavoritesVisibility(db)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusNotFound, err.Status)
+}
+
+func TestPasswordReset(t *testing.T) {
+	user := newTestUser(t, "reset@gmail.com")
+
+	cacheClient := &cache_mock.CacheClientMock{CanGet: true, CanSet: true, CanDel: true}
+	cacheClient.SetupCacheConnection()
+
+	token, tokenErr := user.CreatePasswordResetToken(cacheClient)
+	assert.Nil(t, tokenErr)
+
+	result, err := users.User{Password: "5678"}.ResetPassword(token, db, cacheClient)
+	assert.Nil(t, err)
+	assert.EqualValues(t, user.ID, result.(users.User).ID)
+
+	fetched, err := user.GetById(db)
+	assert.Nil(t, err)
+	assert.EqualValues(t, "5678", fetched.(users.User).Password)
+
+	missing := users.User{ID: -1, Password: "5678"}
+	err = missing.UpdatePassword(db)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusNotFound, err.Status)
+}
+
+func TestVerify(t *testing.T) {
+	cacheClient := &cache_mock.CacheClientMock{CanGet: true, CanSet: true, CanDel: true}
+	cacheClient.SetupCacheConnection()
+
+	for _, tc := range []struct {
+		email    string
+		status   string
+		verified bool
+		result   string
+	}{
+		{"verifypending@gmail.com", users.StatusPending, true, users.StatusActive},
+		{"verifyactive@gmail.com", users.StatusActive, true, users.StatusActive},
+		{"verifysuspended@gmail.com", users.StatusSuspended, false, users.StatusSuspended},
+		{"verifydeleted@gmail.com", users.StatusDeleted, false, users.StatusDeleted},
+	} {
+		user := users.User{
+			FirstName:   "John",
+			LastName:    "Doe",
+			Email:       tc.email,
+			DateCreated: "2021-01-01",
+			Status:      tc.status,
+			Password:    "1234",
+			Role:        users.RoleUser,
+		}
+		assert.Nil(t, user.Save(db))
+
+		token, err := user.CreateVerificationToken(cacheClient)
+		assert.Nil(t, err)
+
+		err = users.User{}.Verify(token, db, cacheClient)
+		if tc.verified {
+			assert.Nil(t, err, tc.status)
+		} else {
+			assert.NotNil(t, err, tc.status)
+			assert.EqualValues(t, "Invalid or expired verification token", err.Message)
+			assert.EqualValues(t, http.StatusBadRequest, err.Status)
+		}
+
+		fetched, err := user.Get(db)
+		assert.Nil(t, err)
+		assert.EqualValues(t, tc.result, fetched.(users.User).Status, tc.status)
+	}
+}
+
+func TestListUsers(t *testing.T) {
+	first := newTestUser(t, "list1@gmail.com")
+	second := newTestUser(t, "list2@gmail.com")
+
+	listed, err := users.User{}.List(100, 0, db)
+	assert.Nil(t, err)
+
+	var ids []int64
+	for _, listedUser := range listed {
+		assert.EqualValues(t, "", listedUser.(users.User).Password)
+		ids = append(ids, listedUser.(users.User).ID)
+	}
+
+	assert.Contains(t, ids, first.ID)
+	assert.Contains(t, ids, second.ID)
+
+	limited, err := users.User{}.List(1, 0, db)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, len(limited))
+}
+
+func TestGetUnknownUser(t *testing.T) {
+	result, err := users.User{Email: "missing@gmail.com"}.Get(db)
+
+	assert.Nil(t, result)
+	assert.EqualValues(t, http.StatusNotFound, err.Status)
+	assert.EqualValues(t, "User not found", err.Message)
+
+	result, err = users.User{ID: 999999}.GetById(db)
+
+	assert.Nil(t, result)
+	assert.EqualValues(t, http.StatusNotFound, err.Status)
+	assert.EqualValues(t, "User not found", err.Message)
+}
+
+func TestSaveDuplicatedEmail(t *testing.T) {
+	newTestUser(t, "duplicated@gmail.com")
+
+	user := users.User{Email: "duplicated@gmail.com"}
+	err := user.Save(db)
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusInternalServerError, err.Status)
+}
+
+func TestFavoritesRanking(t *testing.T) {
+	user := newTestUser(t, "favorites@gmail.com")
+
+	for _, movieId := range []int{10, 20, 30} {
+		favorite := user_favorites.UserFavorites{UserID: user.ID, MoviesIDs: []int{movieId}}
+		assert.Nil(t, favorite.AddFavorite(db))
+	}
+
+	assert.EqualValues(t, []int{10, 20, 30}, favoriteIds(t, user.ID))
+
+	reorder := user_favorites.UserFavorites{UserID: user.ID, MoviesIDs: []int{30, 10, 20}}
+	assert.Nil(t, reorder.ReorderFavorites(db))
+	assert.EqualValues(t, []int{30, 10, 20}, favoriteIds(t, user.ID))
+
+	remove := user_favorites.UserFavorites{UserID: user.ID, MoviesIDs: []int{10}}
+	assert.Nil(t, remove.RemoveFavorite(db))
+	assert.EqualValues(t, []int{30, 20}, favoriteIds(t, user.ID))
+
+	replace := user_favorites.UserFavorites{UserID: user.ID, MoviesIDs: []int{40}}
+	assert.Nil(t, replace.ReplaceFavorite(2, db))
+	assert.EqualValues(t, []int{30, 40}, favoriteIds(t, user.ID))
+
+	err := remove.RemoveFavorite(db)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusNotFound, err.Status)
+}
+
+func TestFavoritesLimit(t *testing.T) {
+	user := newTestUser(t, "limit@gmail.com")
+
+	for movieId := 1; movieId <= user_favorites.MaxFavorites; movieId++ {
+		favorite := user_favorites.UserFavorites{UserID: user.ID, MoviesIDs: []int{movieId}}
+		assert.Nil(t, favorite.AddFavorite(db))
+	}
+
+	favorite := user_favorites.UserFavorites{UserID: user.ID, MoviesIDs: []int{100}}
+	err := favorite.AddFavorite(db)
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status)
+	assert.EqualValues(t, user_favorites.MaxFavorites, len(favoriteIds(t, user.ID)))
+}
+
+func TestFavoritesConcurrentAdds(t *testing.T) {
+	user := newTestUser(t, "concurrent@gmail.com")
+
+	var wg sync.WaitGroup
+	errs := make(chan *rest_errors.RestErr, user_favorites.MaxFavorites+2)
+	for movieId := 1; movieId <= user_favorites.MaxFavorites+2; movieId++ {
+		wg.Add(1)
+		go func(movieId int) {
+			defer wg.Done()
+
+			errs <- user_favorites.UserFavorites{UserID: user.ID, MoviesIDs: []int{movieId}}.AddFavorite(db)
+		}(movieId)
+	}
+
+	wg.Wait()
+	close(errs)
+
+	rejected := 0
+	for err := range errs {
+		if err != nil {
+			assert.EqualValues(t, http.StatusBadRequest, err.Status)
+			rejected++
+		}
+	}
+
+	assert.EqualValues(t, 2, rejected)
+	assert.EqualValues(t, user_favorites.MaxFavorites, len(favoriteIds(t, user.ID)))
+}
+
+func TestFavoritesMissingOwner(t *testing.T) {
+	err := user_favorites.UserFavorites{UserID: -1, MoviesIDs: []int{1}}.AddFavorite(db)
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusNotFound, err.Status)
+	assert.EqualValues(t, "User not found", err.Message)
+}
+
+func TestGetFavoritesPartialCache(t *testing.T) {
+	user := newTestUser(t, "cached@gmail.com")
+
+	for _, movieId := range []int{1, 2, 3} {
+		favorite := user_favorites.UserFavorites{UserID: user.ID, MoviesIDs: []int{movieId}}
+		assert.Nil(t, favorite.AddFavorite(db))
+	}
+
+	c := &cache_mock.CacheClientMock{CanGet: true, CanSet: true}
+	c.SetupCacheConnection()
+
+	movie := movies.MovieInfo{Movie: tmdb.Movie{ID: 3, Title: "Cached Movie"}}
+	assert.Nil(t, movie.AddMovie(c))
+	c.Values[movies.CacheKey(2)] = "not json"
+
+	result, cachedIds, err := user_favorites.UserFavorites{UserID: user.ID}.GetFavorites(db, c)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, map[int]bool{3: true}, cachedIds)
+
+	favorites := result.(user_favorites.UserFavorites)
+	assert.EqualValues(t, []int{1, 2, 3}, favorites.MoviesIDs)
+	assert.EqualValues(t, 1, len(favorites.MoviesData))
+	assert.EqualValues(t, "Cached Movie", favorites.MoviesData[0].Title)
+}
+
+func TestGetFavoritesCacheErrorIsMiss(t *testing.T) {
+	user := newTestUser(t, "cacheerror@gmail.com")
+
+	favorite := user_favorites.UserFavorites{UserID: user.ID, MoviesIDs: []int{1}}
+	assert.Nil(t, favorite.AddFavorite(db))
+
+	c := &cache_mock.CacheClientMock{CanGet: false}
+	c.SetupCacheConnection()
+
+	result, cachedIds, err := user_favorites.UserFavorites{UserID: user.ID}.GetFavorites(db, c)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, 0, len(cachedIds))
+	assert.EqualValues(t, []int{1}, result.(user_favorites.UserFavorites).MoviesIDs)
+}
+
+func TestWithTxRollback(t *testing.T) {
+	user := newTestUser(t, "rollback@gmail.com")
+
+	favorite := user_favorites.UserFavorites{UserID: user.ID, MoviesIDs: []int{1}}
+	assert.Nil(t, favorite.AddFavorite(db))
+
+	rollbackErr := errors.New("rollback")
+
+	err := db.WithTx(context.Background(), func(tx database.Transaction) error {
+		_, execErr := tx.Exec(context.Background(), user_favorites_queries.QueryRemoveUserFavorite, user.ID, 1)
+		assert.Nil(t, execErr)
+
+		return rollbackErr
+	})
+
+	assert.Equal(t, rollbackErr, err)
+	assert.EqualValues(t, []int{1}, favoriteIds(t, user.ID))
+}
+
+func TestUnsupportedQuery(t *testing.T) {
+	_, err := db.Exec(context.Background(), "DROP TABLE users;")
+
+	assert.True(t, errors.Is(err, ErrUnsupportedQuery))
+}
@@ -0,0 +1,162 @@
+package memorydb
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+type userRow struct {
+	id          int64
+	firstName   string
+	lastName    string
+	email       string
+	dateCreated string
+	status      string
+	password    string
+	role        string
+	deletedAt   time.Time
+
+	favoritesVisibility string
+	favoritesShareToken string
+}
+
+type favoriteRow struct {
+	userID  int64
+	movieID int
+	rank    int
+}
+
+type profileRow struct {
+	userID         int64
+	handle         string
+	displayName    string
+	bio            string
+	avatarUrl      string
+	favoriteGenres []string
+}
+
+// followRow slices are kept in follow order, the newest follow last
+type followRow struct {
+	followerID  int64
+	followedID  int64
+	dateCreated string
+}
+
+type favoriteEventRow struct {
+	id           int64
+	userID       int64
+	movieID      int
+	eventType    string
+	rank         int
+	previousRank int
+	dateCreated  string
+}
+
+type state struct {
+	nextUserID  int64
+	nextEventID int64
+	users       map[int64]*userRow
+	favorites   []*favoriteRow
+	profiles    map[int64]*profileRow
+	follows     []*followRow
+	events      []*favoriteEventRow
+}
+
+func newState() *state {
+	return &state{
+		nextUserID:  1,
+		nextEventID: 1,
+		users:       make(map[int64]*userRow),
+		profiles:    make(map[int64]*profileRow),
+	}
+}
+
+func (s *state) clone() *state {
+	cloned := &state{
+		nextUserID:  s.nextUserID,
+		nextEventID: s.nextEventID,
+		users:       make(map[int64]*userRow, len(s.users)),
+		favorites:   make([]*favoriteRow, 0, len(s.favorites)),
+		profiles:    make(map[int64]*profileRow, len(s.profiles)),
+		follows:     make([]*followRow, 0, len(s.follows)),
+		events:      make([]*favoriteEventRow, 0, len(s.events)),
+	}
+
+	for id, user := range s.users {
+		userCopy := *user
+		cloned.users[id] = &userCopy
+	}
+
+	for _, favorite := range s.favorites {
+		favoriteCopy := *favorite
+		cloned.favorites = append(cloned.favorites, &favoriteCopy)
+	}
+
+	for userID, profile := range s.profiles {
+		profileCopy := *profile
+		profileCopy.favoriteGenres = append([]string(nil), profile.favoriteGenres...)
+		cloned.profiles[userID] = &profileCopy
+	}
+
+	for _, follow := range s.follows {
+		followCopy := *follow
+		cloned.follows = append(cloned.follows, &followCopy)
+	}
+
+	for _, event := range s.events {
+		eventCopy := *event
+		cloned.events = append(cloned.events, &eventCopy)
+	}
+
+	return cloned
+}
+
+func toInt64(argument interface{}) (int64, error) {
+	value := reflect.ValueOf(argument)
+
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return value.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(value.Uint()), nil
+	default:
+		return 0, fmt.Errorf("cannot use %T as an integer argument", argument)
+	}
+}
+
+func toInt(argument interface{}) (int, error) {
+	value, err := toInt64(argument)
+
+	return int(value), err
+}
+
+func toString(argument interface{}) (string, error) {
+	value, ok := argument.(string)
+	if !ok {
+		return "", fmt.Errorf("cannot use %T as a text argument", argument)
+	}
+
+	return value, nil
+}
+
+func toTime(argument interface{}) (time.Time, error) {
+	if argument == nil {
+		return time.Time{}, nil
+	}
+
+	value, ok := argument.(time.Time)
+	if !ok {
+		return time.Time{}, fmt.Errorf("cannot use %T as a timestamp argument", argument)
+	}
+
+	return value, nil
+}
+
+func expectArguments(arguments []interface{}, count int) error {
+	if len(arguments) != count {
+		return fmt.Errorf("expected %d arguments, got %d", count, len(arguments))
+	}
+
+	return nil
+}
@@ -0,0 +1,379 @@
+package memorydb
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	user_favorites_queries "github.com/ericbg27/top10movies-api/src/queries/user_favorites"
+)
+
+func init() {
+	register(user_favorites_queries.QueryGetUserFavoritesIds, getUserFavoritesIds)
+	register(user_favorites_queries.QueryLockUserFavorites, lockUserFavorites)
+	register(user_favorites_queries.QueryCountUserFavorites, countUserFavorites)
+	register(user_favorites_queries.QueryAddUserFavorite, addUserFavorite)
+	register(user_favorites_queries.QueryRemoveUserFavorite, removeUserFavorite)
+	register(user_favorites_queries.QueryShiftUserFavoritesRanks, shiftUserFavoritesRanks)
+	register(user_favorites_queries.QueryReplaceUserFavorite, replaceUserFavorite)
+	register(user_favorites_queries.QueryReorderUserFavorites, reorderUserFavorites)
+	register(user_favorites_queries.QueryAddFavoriteEvent, addFavoriteEvent)
+	register(user_favorites_queries.QueryCountFavoritesFeed, countFavoritesFeed)
+	register(user_favorites_queries.QueryGetFavoritesFeed, getFavoritesFeed)
+}
+
+// eventDateLayout matches the to_char format of the feed query
+const (
+	eventDateLayout = "2006-01-02T15:04:05"
+)
+
+func (s *state) userFavorites(userID int64) []*favoriteRow {
+	var favorites []*favoriteRow
+	for _, favorite := range s.favorites {
+		if favorite.userID == userID {
+			favorites = append(favorites, favorite)
+		}
+	}
+
+	sort.Slice(favorites, func(i, j int) bool {
+		return favorites[i].rank < favorites[j].rank
+	})
+
+	return favorites
+}
+
+func (s *state) deleteFavorites(userID int64) {
+	var kept []*favoriteRow
+
+	for _, favorite := range s.favorites {
+		if favorite.userID == userID {
+			continue
+		}
+
+		kept = append(kept, favorite)
+	}
+
+	s.favorites = kept
+}
+
+func userIdArgument(arguments []interface{}, count int) (int64, error) {
+	if err := expectArguments(arguments, count); err != nil {
+		return 0, err
+	}
+
+	return toInt64(arguments[0])
+}
+
+func getUserFavoritesIds(s *state, arguments []interface{}) ([][]interface{}, int64, error) {
+	userID, err := userIdArgument(arguments, 1)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var rows [][]interface{}
+	for _, favorite := range s.userFavorites(userID) {
+		rows = append(rows, []interface{}{favorite.movieID})
+	}
+
+	return rows, 0, nil
+}
+
+// lockUserFavorites only checks the owner exists, transactions already hold the whole database
+func lockUserFavorites(s *state, arguments []interface{}) ([][]interface{}, int64, error) {
+	userID, err := userIdArgument(arguments, 1)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if _, ok := s.users[userID]; !ok {
+		return nil, 0, nil
+	}
+
+	return [][]interface{}{{userID}}, 0, nil
+}
+
+func countUserFavorites(s *state, arguments []interface{}) ([][]interface{}, int64, error) {
+	userID, err := userIdArgument(arguments, 1)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return [][]interface{}{{int64(len(s.userFavorites(userID)))}}, 0, nil
+}
+
+func addUserFavorite(s *state, arguments []interface{}) ([][]interface{}, int64, error) {
+	userID, err := userIdArgument(arguments, 3)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	movieID, err := toInt(arguments[1])
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rank, err := toInt(arguments[2])
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if _, ok := s.users[userID]; !ok {
+		return nil, 0, ErrForeignKey
+	}
+
+	if rank < 1 || rank > 10 {
+		return nil, 0, fmt.Errorf("rank %d violates check constraint", rank)
+	}
+
+	for _, favorite := range s.userFavorites(userID) {
+		if favorite.movieID == movieID || favorite.rank == rank {
+			return nil, 0, ErrUniqueViolation
+		}
+	}
+
+	s.favorites = append(s.favorites, &favoriteRow{
+		userID:  userID,
+		movieID: movieID,
+		rank:    rank,
+	})
+
+	return nil, 1, nil
+}
+
+func removeUserFavorite(s *state, arguments []interface{}) ([][]interface{}, int64, error) {
+	userID, err := userIdArgument(arguments, 2)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	movieID, err := toInt(arguments[1])
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var kept []*favoriteRow
+	var deleted int64
+
+	for _, favorite := range s.favorites {
+		if favorite.userID == userID && favorite.movieID == movieID {
+			deleted++
+			continue
+		}
+
+		kept = append(kept, favorite)
+	}
+
+	s.favorites = kept
+
+	return nil, deleted, nil
+}
+
+func shiftUserFavoritesRanks(s *state, arguments []interface{}) ([][]interface{}, int64, error) {
+	userID, err := userIdArgument(arguments, 2)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	fromRank, err := toInt(arguments[1])
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var updated int64
+	for _, favorite := range s.userFavorites(userID) {
+		if favorite.rank > fromRank {
+			favorite.rank--
+			updated++
+		}
+	}
+
+	return nil, updated, nil
+}
+
+func replaceUserFavorite(s *state, arguments []interface{}) ([][]interface{}, int64, error) {
+	userID, err := userIdArgument(arguments, 3)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rank, err := toInt(arguments[1])
+	if err != nil {
+		return nil, 0, err
+	}
+
+	movieID, err := toInt(arguments[2])
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var target *favoriteRow
+	for _, favorite := range s.userFavorites(userID) {
+		if favorite.movieID == movieID && favorite.rank != rank {
+			return nil, 0, ErrUniqueViolation
+		}
+
+		if favorite.rank == rank {
+			target = favorite
+		}
+	}
+
+	if target == nil {
+		return nil, 0, nil
+	}
+
+	target.movieID = movieID
+
+	return nil, 1, nil
+}
+
+func reorderUserFavorites(s *state, arguments []interface{}) ([][]interface{}, int64, error) {
+	userID, err := userIdArgument(arguments, 2)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	order, ok := arguments[1].([]int)
+	if !ok {
+		return nil, 0, fmt.Errorf("cannot use %T as an integer array argument", arguments[1])
+	}
+
+	newRanks := make(map[int]int)
+	for index, movieID := range order {
+		newRanks[movieID] = index + 1
+	}
+
+	var updated int64
+	for _, favorite := range s.userFavorites(userID) {
+		if rank, ok := newRanks[favorite.movieID]; ok {
+			favorite.rank = rank
+			updated++
+		}
+	}
+
+	return nil, updated, nil
+}
+
+func (s *state) deleteFavoriteEvents(userID int64) {
+	var kept []*favoriteEventRow
+	for _, event := range s.events {
+		if event.userID == userID {
+			continue
+		}
+
+		kept = append(kept, event)
+	}
+
+	s.events = kept
+}
+
+// feedEvents returns the events the follower sees in their feed, newest first
+func (s *state) feedEvents(followerID int64) []*favoriteEventRow {
+	var events []*favoriteEventRow
+	for index := len(s.events) - 1; index >= 0; index-- {
+		event := s.events[index]
+
+		user, ok := s.users[event.userID]
+		if !ok || user.status != statusActive || !s.isFollowing(followerID, event.userID) {
+			continue
+		}
+
+		if user.favoritesVisibility != favoritesPublic && user.favoritesVisibility != favoritesFollowers {
+			continue
+		}
+
+		events = append(events, event)
+	}
+
+	return events
+}
+
+func addFavoriteEvent(s *state, arguments []interface{}) ([][]interface{}, int64, error) {
+	userID, err := userIdArgument(arguments, 5)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	movieID, err := toInt(arguments[1])
+	if err != nil {
+		return nil, 0, err
+	}
+
+	eventType, err := toString(arguments[2])
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var ranks [2]int
+	for index, argument := range arguments[3:5] {
+		rank, err := toInt(argument)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		ranks[index] = rank
+	}
+
+	if _, ok := s.users[userID]; !ok {
+		return nil, 0, ErrForeignKey
+	}
+
+	s.events = append(s.events, &favoriteEventRow{
+		id:           s.nextEventID,
+		userID:       userID,
+		movieID:      movieID,
+		eventType:    eventType,
+		rank:         ranks[0],
+		previousRank: ranks[1],
+		dateCreated:  time.Now().UTC().Format(eventDateLayout),
+	})
+	s.nextEventID++
+
+	return nil, 1, nil
+}
+
+func countFavoritesFeed(s *state, arguments []interface{}) ([][]interface{}, int64, error) {
+	followerID, err := userIdArgument(arguments, 1)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return [][]interface{}{{int64(len(s.feedEvents(followerID)))}}, 0, nil
+}
+
+func getFavoritesFeed(s *state, arguments []interface{}) ([][]interface{}, int64, error) {
+	followerID, err := userIdArgument(arguments, 4)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	hasCursor, ok := arguments[1].(bool)
+	if !ok {
+		return nil, 0, fmt.Errorf("cannot use %T as a boolean argument", arguments[1])
+	}
+
+	cursorID, err := toInt64(arguments[2])
+	if err != nil {
+		return nil, 0, err
+	}
+
+	limit, err := toInt(arguments[3])
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var rows [][]interface{}
+	for _, event := range s.feedEvents(followerID) {
+		if len(rows) == limit {
+			break
+		}
+
+		if hasCursor && event.id >= cursorID {
+			continue
+		}
+
+		user := s.users[event.userID]
+		rows = append(rows, []interface{}{event.id, event.userID, user.firstName, user.lastName, event.movieID, event.eventType, event.rank, event.previousRank, event.dateCreated})
+	}
+
+	return rows, 0, nil
+}
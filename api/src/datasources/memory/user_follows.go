@@ -0,0 +1,249 @@
+package memorydb
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	user_follows_queries "github.com/ericbg27/top10movies-api/src/queries/user_follows"
+)
+
+func init() {
+	register(user_follows_queries.QueryCountFollowableUser, countFollowableUser)
+	register(user_follows_queries.QueryFollowUser, followUser)
+	register(user_follows_queries.QueryUnfollowUser, unfollowUser)
+	register(user_follows_queries.QueryIsFollowing, isFollowing)
+	register(user_follows_queries.QueryCountFollowers, countFollows(true))
+	register(user_follows_queries.QueryGetFollowers, listFollows(true))
+	register(user_follows_queries.QueryCountFollowing, countFollows(false))
+	register(user_follows_queries.QueryGetFollowing, listFollows(false))
+}
+
+// followDateLayout matches the to_char format of the follow list queries
+const (
+	followDateLayout = "2006-01-02T15:04:05.000000"
+)
+
+// listedFollow is a user in a follow list along with the date of the follow
+type listedFollow struct {
+	user        *userRow
+	dateCreated string
+}
+
+func followBefore(firstDate string, firstID int64, secondDate string, secondID int64) bool {
+	if firstDate != secondDate {
+		return firstDate > secondDate
+	}
+
+	return firstID > secondID
+}
+
+// followList returns the active followers of a user when followers is set and the active users they
+// follow otherwise, newest follow first
+func (s *state) followList(userID int64, followers bool) []listedFollow {
+	var listed []listedFollow
+	for _, follow := range s.follows {
+		listedID, matchedID := follow.followedID, follow.followerID
+		if followers {
+			listedID, matchedID = follow.followerID, follow.followedID
+		}
+
+		if matchedID != userID {
+			continue
+		}
+
+		user, ok := s.users[listedID]
+		if !ok || user.status != statusActive {
+			continue
+		}
+
+		listed = append(listed, listedFollow{user: user, dateCreated: follow.dateCreated})
+	}
+
+	sort.Slice(listed, func(i, j int) bool {
+		return followBefore(listed[i].dateCreated, listed[i].user.id, listed[j].dateCreated, listed[j].user.id)
+	})
+
+	return listed
+}
+
+func (s *state) follow(followerID int64, followedID int64) *followRow {
+	for _, follow := range s.follows {
+		if follow.followerID == followerID && follow.followedID == followedID {
+			return follow
+		}
+	}
+
+	return nil
+}
+
+func (s *state) isFollowing(followerID int64, followedID int64) bool {
+	return s.follow(followerID, followedID) != nil
+}
+
+func (s *state) deleteFollows(userID int64) {
+	var kept []*followRow
+	for _, follow := range s.follows {
+		if follow.followerID == userID || follow.followedID == userID {
+			continue
+		}
+
+		kept = append(kept, follow)
+	}
+
+	s.follows = kept
+}
+
+func followArguments(arguments []interface{}) (int64, int64, error) {
+	followerID, err := userIdArgument(arguments, 2)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	followedID, err := toInt64(arguments[1])
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return followerID, followedID, nil
+}
+
+func countFollowableUser(s *state, arguments []interface{}) ([][]interface{}, int64, error) {
+	userID, err := userIdArgument(arguments, 1)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var count int64
+	if user, ok := s.users[userID]; ok && user.status == statusActive {
+		count = 1
+	}
+
+	return [][]interface{}{{count}}, 0, nil
+}
+
+func followUser(s *state, arguments []interface{}) ([][]interface{}, int64, error) {
+	followerID, followedID, err := followArguments(arguments)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if _, ok := s.users[followerID]; !ok {
+		return nil, 0, ErrForeignKey
+	}
+
+	if _, ok := s.users[followedID]; !ok {
+		return nil, 0, ErrForeignKey
+	}
+
+	if followerID == followedID {
+		return nil, 0, fmt.Errorf("user %d following itself violates check constraint", followerID)
+	}
+
+	if s.isFollowing(followerID, followedID) {
+		return nil, 0, nil
+	}
+
+	s.follows = append(s.follows, &followRow{
+		followerID:  followerID,
+		followedID:  followedID,
+		dateCreated: time.Now().UTC().Format(followDateLayout),
+	})
+
+	return nil, 1, nil
+}
+
+func unfollowUser(s *state, arguments []interface{}) ([][]interface{}, int64, error) {
+	followerID, followedID, err := followArguments(arguments)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var kept []*followRow
+	var deleted int64
+
+	for _, follow := range s.follows {
+		if follow.followerID == followerID && follow.followedID == followedID {
+			deleted++
+			continue
+		}
+
+		kept = append(kept, follow)
+	}
+
+	s.follows = kept
+
+	return nil, deleted, nil
+}
+
+func isFollowing(s *state, arguments []interface{}) ([][]interface{}, int64, error) {
+	followerID, followedID, err := followArguments(arguments)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var count int64
+	if s.isFollowing(followerID, followedID) {
+		count = 1
+	}
+
+	return [][]interface{}{{count}}, 0, nil
+}
+
+func countFollows(followers bool) queryHandler {
+	return func(s *state, arguments []interface{}) ([][]interface{}, int64, error) {
+		userID, err := userIdArgument(arguments, 1)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		return [][]interface{}{{int64(len(s.followList(userID, followers)))}}, 0, nil
+	}
+}
+
+// listFollows returns a page of the followers of a user when followers is set and of the users they
+// follow otherwise
+func listFollows(followers bool) queryHandler {
+	return func(s *state, arguments []interface{}) ([][]interface{}, int64, error) {
+		userID, err := userIdArgument(arguments, 5)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		hasCursor, ok := arguments[1].(bool)
+		if !ok {
+			return nil, 0, fmt.Errorf("cannot use %T as a boolean argument", arguments[1])
+		}
+
+		cursorDate, err := toString(arguments[2])
+		if err != nil {
+			return nil, 0, err
+		}
+
+		cursorID, err := toInt64(arguments[3])
+		if err != nil {
+			return nil, 0, err
+		}
+
+		limit, err := toInt(arguments[4])
+		if err != nil {
+			return nil, 0, err
+		}
+
+		var rows [][]interface{}
+		for _, listed := range s.followList(userID, followers) {
+			if len(rows) == limit {
+				break
+			}
+
+			if hasCursor && !followBefore(cursorDate, cursorID, listed.dateCreated, listed.user.id) {
+				continue
+			}
+
+			user := listed.user
+			rows = append(rows, []interface{}{user.id, user.firstName, user.lastName, user.favoritesVisibility, listed.dateCreated})
+		}
+
+		return rows, 0, nil
+	}
+}
@@ -0,0 +1,128 @@
+package memorydb
+
+import (
+	"fmt"
+
+	user_profiles_queries "github.com/ericbg27/top10movies-api/src/queries/user_profiles"
+)
+
+// statusActive mirrors the literal used by the profile by handle, follow and feed queries.
+const (
+	statusActive = "active"
+)
+
+func init() {
+	register(user_profiles_queries.QueryGetUserProfile, getUserProfile)
+	register(user_profiles_queries.QueryGetUserProfileByHandle, getUserProfileByHandle)
+	register(user_profiles_queries.QueryGetHandleOwner, getHandleOwner)
+	register(user_profiles_queries.QuerySaveUserProfile, saveUserProfile)
+}
+
+func (p *profileRow) values() []interface{} {
+	return []interface{}{p.userID, p.handle, p.displayName, p.bio, p.avatarUrl, append([]string(nil), p.favoriteGenres...)}
+}
+
+func (s *state) profileByHandle(handle string) *profileRow {
+	for _, profile := range s.profiles {
+		if profile.handle == handle {
+			return profile
+		}
+	}
+
+	return nil
+}
+
+func getUserProfile(s *state, arguments []interface{}) ([][]interface{}, int64, error) {
+	userID, err := userIdArgument(arguments, 1)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	profile, ok := s.profiles[userID]
+	if !ok {
+		return nil, 0, nil
+	}
+
+	return [][]interface{}{profile.values()}, 0, nil
+}
+
+func getUserProfileByHandle(s *state, arguments []interface{}) ([][]interface{}, int64, error) {
+	if err := expectArguments(arguments, 1); err != nil {
+		return nil, 0, err
+	}
+
+	handle, err := toString(arguments[0])
+	if err != nil {
+		return nil, 0, err
+	}
+
+	profile := s.profileByHandle(handle)
+	if profile == nil {
+		return nil, 0, nil
+	}
+
+	if user, ok := s.users[profile.userID]; !ok || user.status != statusActive {
+		return nil, 0, nil
+	}
+
+	return [][]interface{}{profile.values()}, 0, nil
+}
+
+func getHandleOwner(s *state, arguments []interface{}) ([][]interface{}, int64, error) {
+	if err := expectArguments(arguments, 1); err != nil {
+		return nil, 0, err
+	}
+
+	handle, err := toString(arguments[0])
+	if err != nil {
+		return nil, 0, err
+	}
+
+	profile := s.profileByHandle(handle)
+	if profile == nil {
+		return nil, 0, nil
+	}
+
+	return [][]interface{}{{profile.userID}}, 0, nil
+}
+
+func saveUserProfile(s *state, arguments []interface{}) ([][]interface{}, int64, error) {
+	userID, err := userIdArgument(arguments, 6)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var fields [4]string
+	for index, argument := range arguments[1:5] {
+		value, err := toString(argument)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		fields[index] = value
+	}
+
+	genres, ok := arguments[5].([]string)
+	if !ok {
+		return nil, 0, fmt.Errorf("cannot use %T as a text array argument", arguments[5])
+	}
+
+	if _, ok := s.users[userID]; !ok {
+		return nil, 0, ErrForeignKey
+	}
+
+	if owner := s.profileByHandle(fields[0]); owner != nil && owner.userID != userID {
+		return nil, 0, ErrUniqueViolation
+	}
+
+	s.profiles[userID] = &profileRow{
+		userID:         userID,
+		handle:         fields[0],
+		displayName:    fields[1],
+		bio:            fields[2],
+		avatarUrl:      fields[3],
+		favoriteGenres: append([]string(nil), genres...),
+	}
+
+	return nil, 1, nil
+}
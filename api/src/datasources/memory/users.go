@@ -0,0 +1,457 @@
+package memorydb
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	user_queries "github.com/ericbg27/top10movies-api/src/queries/users"
+)
+
+// statusDeleted mirrors the literal used by the soft-delete aware user queries, favoritesPublic
+// the default of the favorites_visibility column and favoritesFollowers the other visibility the
+// activity feed shows.
+const (
+	statusDeleted      = "deleted"
+	favoritesPublic    = "public"
+	favoritesFollowers = "followers"
+)
+
+func init() {
+	register(user_queries.QueryInsertUser, insertUser)
+	register(user_queries.QueryGetUser, getUser)
+	register(user_queries.QueryGetUserById, getUserById)
+	register(user_queries.QueryUpdateUser, updateUser)
+	register(user_queries.QueryCountSearchUsers, countSearchUsers)
+	register(user_queries.QuerySearchUsersByName, searchUsers(searchByName, false))
+	register(user_queries.QuerySearchUsersByNameDesc, searchUsers(searchByName, true))
+	register(user_queries.QuerySearchUsersByDate, searchUsers(searchByDate, false))
+	register(user_queries.QuerySearchUsersByDateDesc, searchUsers(searchByDate, true))
+	register(user_queries.QueryListUsers, listUsers)
+	register(user_queries.QueryUpdateUserStatus, updateUserStatus)
+	register(user_queries.QueryUpdateUserRole, updateUserRole)
+	register(user_queries.QueryUpdateUserPassword, updateUserPassword)
+	register(user_queries.QueryUpdateFavoritesVisibility, updateFavoritesVisibility)
+	register(user_queries.QueryPurgeDeletedUsers, purgeDeletedUsers)
+}
+
+func (u *userRow) values() []interface{} {
+	return []interface{}{u.id, u.firstName, u.lastName, u.email, u.status, u.password, u.role, u.favoritesVisibility, u.favoritesShareToken}
+}
+
+// profileValues leaves the password out for queries that never need it
+func (u *userRow) profileValues() []interface{} {
+	return []interface{}{u.id, u.firstName, u.lastName, u.email, u.status, u.role, u.favoritesVisibility}
+}
+
+func (u *userRow) searchValues() []interface{} {
+	return append(u.profileValues(), u.dateCreated)
+}
+
+func searchByName(user *userRow) string {
+	return strings.ToLower(user.firstName + " " + user.lastName)
+}
+
+func searchByDate(user *userRow) string {
+	return user.dateCreated
+}
+
+func (s *state) userByEmail(email string) *userRow {
+	for _, user := range s.users {
+		if user.email == email {
+			return user
+		}
+	}
+
+	return nil
+}
+
+func insertUser(s *state, arguments []interface{}) ([][]interface{}, int64, error) {
+	if err := expectArguments(arguments, 7); err != nil {
+		return nil, 0, err
+	}
+
+	var fields [7]string
+	for index, argument := range arguments {
+		value, err := toString(argument)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		fields[index] = value
+	}
+
+	if s.userByEmail(fields[2]) != nil {
+		return nil, 0, ErrUniqueViolation
+	}
+
+	user := &userRow{
+		id:          s.nextUserID,
+		firstName:   fields[0],
+		lastName:    fields[1],
+		email:       fields[2],
+		dateCreated: fields[3],
+		status:      fields[4],
+		password:    fields[5],
+		role:        fields[6],
+
+		favoritesVisibility: favoritesPublic,
+	}
+
+	s.users[user.id] = user
+	s.nextUserID++
+
+	return nil, 1, nil
+}
+
+func getUser(s *state, arguments []interface{}) ([][]interface{}, int64, error) {
+	if err := expectArguments(arguments, 1); err != nil {
+		return nil, 0, err
+	}
+
+	email, err := toString(arguments[0])
+	if err != nil {
+		return nil, 0, err
+	}
+
+	user := s.userByEmail(email)
+	if user == nil {
+		return nil, 0, nil
+	}
+
+	return [][]interface{}{user.values()}, 0, nil
+}
+
+func getUserById(s *state, arguments []interface{}) ([][]interface{}, int64, error) {
+	if err := expectArguments(arguments, 1); err != nil {
+		return nil, 0, err
+	}
+
+	id, err := toInt64(arguments[0])
+	if err != nil {
+		return nil, 0, err
+	}
+
+	user, ok := s.users[id]
+	if !ok {
+		return nil, 0, nil
+	}
+
+	return [][]interface{}{user.values()}, 0, nil
+}
+
+func updateUser(s *state, arguments []interface{}) ([][]interface{}, int64, error) {
+	if err := expectArguments(arguments, 4); err != nil {
+		return nil, 0, err
+	}
+
+	var fields [3]string
+	for index, argument := range arguments[:3] {
+		value, err := toString(argument)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		fields[index] = value
+	}
+
+	id, err := toInt64(arguments[3])
+	if err != nil {
+		return nil, 0, err
+	}
+
+	user, ok := s.users[id]
+	if !ok {
+		return nil, 0, nil
+	}
+
+	if other := s.userByEmail(fields[2]); other != nil && other.id != id {
+		return nil, 0, ErrUniqueViolation
+	}
+
+	user.firstName = fields[0]
+	user.lastName = fields[1]
+	user.email = fields[2]
+
+	return nil, 1, nil
+}
+
+func purgeDeletedUsers(s *state, arguments []interface{}) ([][]interface{}, int64, error) {
+	if err := expectArguments(arguments, 1); err != nil {
+		return nil, 0, err
+	}
+
+	before, err := toTime(arguments[0])
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var purged int64
+	for id, user := range s.users {
+		if user.status != statusDeleted || !user.deletedAt.Before(before) {
+			continue
+		}
+
+		delete(s.users, id)
+		delete(s.profiles, id)
+		s.deleteFavorites(id)
+		s.deleteFollows(id)
+		s.deleteFavoriteEvents(id)
+		purged++
+	}
+
+	return nil, purged, nil
+}
+
+// unescapeLike reverts the escaping applied to search terms before they are used in a LIKE pattern
+func unescapeLike(term string) string {
+	var unescaped strings.Builder
+
+	escaped := false
+	for _, char := range term {
+		if char == '\\' && !escaped {
+			escaped = true
+			continue
+		}
+
+		escaped = false
+		unescaped.WriteRune(char)
+	}
+
+	return unescaped.String()
+}
+
+func (s *state) searchMatches(arguments []interface{}) ([]*userRow, error) {
+	term, err := toString(arguments[0])
+	if err != nil {
+		return nil, err
+	}
+
+	includeEmail, ok := arguments[1].(bool)
+	if !ok {
+		return nil, fmt.Errorf("cannot use %T as a boolean argument", arguments[1])
+	}
+
+	term = unescapeLike(term)
+
+	var found []*userRow
+	for _, user := range s.users {
+		if user.status == statusDeleted || (!includeEmail && user.status != statusActive) {
+			continue
+		}
+
+		if strings.Contains(searchByName(user), term) ||
+			(includeEmail && strings.HasPrefix(strings.ToLower(user.email), term)) {
+			found = append(found, user)
+		}
+	}
+
+	return found, nil
+}
+
+func countSearchUsers(s *state, arguments []interface{}) ([][]interface{}, int64, error) {
+	if err := expectArguments(arguments, 2); err != nil {
+		return nil, 0, err
+	}
+
+	found, err := s.searchMatches(arguments)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return [][]interface{}{{int64(len(found))}}, 0, nil
+}
+
+func searchUsers(sortKey func(*userRow) string, descending bool) queryHandler {
+	less := func(firstKey string, firstID int64, secondKey string, secondID int64) bool {
+		if firstKey != secondKey {
+			return firstKey < secondKey
+		}
+
+		return firstID < secondID
+	}
+
+	return func(s *state, arguments []interface{}) ([][]interface{}, int64, error) {
+		if err := expectArguments(arguments, 6); err != nil {
+			return nil, 0, err
+		}
+
+		found, err := s.searchMatches(arguments)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		hasCursor, ok := arguments[2].(bool)
+		if !ok {
+			return nil, 0, fmt.Errorf("cannot use %T as a boolean argument", arguments[2])
+		}
+
+		cursorValue, err := toString(arguments[3])
+		if err != nil {
+			return nil, 0, err
+		}
+
+		cursorID, err := toInt64(arguments[4])
+		if err != nil {
+			return nil, 0, err
+		}
+
+		limit, err := toInt(arguments[5])
+		if err != nil {
+			return nil, 0, err
+		}
+
+		sort.Slice(found, func(i, j int) bool {
+			if descending {
+				return less(sortKey(found[j]), found[j].id, sortKey(found[i]), found[i].id)
+			}
+
+			return less(sortKey(found[i]), found[i].id, sortKey(found[j]), found[j].id)
+		})
+
+		var rows [][]interface{}
+		for _, user := range found {
+			if len(rows) == limit {
+				break
+			}
+
+			if hasCursor {
+				afterCursor := less(cursorValue, cursorID, sortKey(user), user.id)
+				if descending {
+					afterCursor = less(sortKey(user), user.id, cursorValue, cursorID)
+				}
+
+				if !afterCursor {
+					continue
+				}
+			}
+
+			rows = append(rows, user.searchValues())
+		}
+
+		return rows, 0, nil
+	}
+}
+
+func listUsers(s *state, arguments []interface{}) ([][]interface{}, int64, error) {
+	if err := expectArguments(arguments, 2); err != nil {
+		return nil, 0, err
+	}
+
+	limit, err := toInt(arguments[0])
+	if err != nil {
+		return nil, 0, err
+	}
+
+	offset, err := toInt(arguments[1])
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var listed []*userRow
+	for _, user := range s.users {
+		listed = append(listed, user)
+	}
+
+	sort.Slice(listed, func(i, j int) bool {
+		return listed[i].id < listed[j].id
+	})
+
+	var rows [][]interface{}
+	for index := offset; index < len(listed) && index < offset+limit; index++ {
+		rows = append(rows, listed[index].profileValues())
+	}
+
+	return rows, 0, nil
+}
+
+func updateUserField(s *state, arguments []interface{}, update func(user *userRow, value string)) ([][]interface{}, int64, error) {
+	if err := expectArguments(arguments, 2); err != nil {
+		return nil, 0, err
+	}
+
+	id, err := toInt64(arguments[0])
+	if err != nil {
+		return nil, 0, err
+	}
+
+	value, err := toString(arguments[1])
+	if err != nil {
+		return nil, 0, err
+	}
+
+	user, ok := s.users[id]
+	if !ok {
+		return nil, 0, nil
+	}
+
+	update(user, value)
+
+	return nil, 1, nil
+}
+
+func updateUserStatus(s *state, arguments []interface{}) ([][]interface{}, int64, error) {
+	if err := expectArguments(arguments, 4); err != nil {
+		return nil, 0, err
+	}
+
+	deletedAt, err := toTime(arguments[2])
+	if err != nil {
+		return nil, 0, err
+	}
+
+	id, err := toInt64(arguments[0])
+	if err != nil {
+		return nil, 0, err
+	}
+
+	oldStatus, err := toString(arguments[3])
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if user, ok := s.users[id]; !ok || user.status != oldStatus {
+		return nil, 0, nil
+	}
+
+	return updateUserField(s, arguments[:2], func(user *userRow, status string) {
+		user.status = status
+		user.deletedAt = deletedAt
+	})
+}
+
+func updateUserRole(s *state, arguments []interface{}) ([][]interface{}, int64, error) {
+	return updateUserField(s, arguments, func(user *userRow, role string) {
+		user.role = role
+	})
+}
+
+func updateUserPassword(s *state, arguments []interface{}) ([][]interface{}, int64, error) {
+	return updateUserField(s, arguments, func(user *userRow, password string) {
+		user.password = password
+	})
+}
+
+func updateFavoritesVisibility(s *state, arguments []interface{}) ([][]interface{}, int64, error) {
+	if err := expectArguments(arguments, 3); err != nil {
+		return nil, 0, err
+	}
+
+	shareToken, err := toString(arguments[2])
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if shareToken != "" {
+		for _, user := range s.users {
+			if user.favoritesShareToken == shareToken {
+				return nil, 0, ErrUniqueViolation
+			}
+		}
+	}
+
+	return updateUserField(s, arguments[:2], func(user *userRow, visibility string) {
+		user.favoritesVisibility = visibility
+		user.favoritesShareToken = shareToken
+	})
+}
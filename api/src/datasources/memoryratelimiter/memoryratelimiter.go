@@ -0,0 +1,89 @@
+package memoryratelimiter
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/ericbg27/top10movies-api/src/datasources/ratelimiter"
+)
+
+const (
+	sweepInterval = time.Minute
+)
+
+type MemoryRateLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+	limit     ratelimiter.Limit
+}
+
+func NewMemoryRateLimiter() *MemoryRateLimiter {
+	return &MemoryRateLimiter{
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+func (b *bucket) refill(now time.Time) {
+	elapsed := now.Sub(b.updatedAt).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(float64(b.limit.Burst), b.tokens+elapsed*b.limit.Rate)
+	}
+
+	b.updatedAt = now
+}
+
+// sweep drops the buckets that refilled completely, they behave exactly like missing ones
+func (m *MemoryRateLimiter) sweep(now time.Time) {
+	for key, b := range m.buckets {
+		b.refill(now)
+
+		if b.tokens >= float64(b.limit.Burst) {
+			delete(m.buckets, key)
+		}
+	}
+
+	m.lastSweep = now
+}
+
+func (m *MemoryRateLimiter) Allow(key string, limit ratelimiter.Limit) (bool, time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+
+	if now.Sub(m.lastSweep) >= sweepInterval {
+		m.sweep(now)
+	}
+
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{
+			tokens:    float64(limit.Burst),
+			updatedAt: now,
+		}
+		m.buckets[key] = b
+	}
+
+	b.limit = limit
+	b.refill(now)
+
+	if b.tokens >= 1 {
+		b.tokens--
+
+		return true, 0, nil
+	}
+
+	retryAfter := time.Duration((1 - b.tokens) / limit.Rate * float64(time.Second))
+
+	return false, retryAfter, nil
+}
@@ -0,0 +1,78 @@
+package memoryratelimiter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ericbg27/top10movies-api/src/datasources/ratelimiter"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestLimiter(now *time.Time) *MemoryRateLimiter {
+	limiter := NewMemoryRateLimiter()
+	limiter.now = func() time.Time {
+		return *now
+	}
+	limiter.lastSweep = *now
+
+	return limiter
+}
+
+func TestAllowConsumesBurst(t *testing.T) {
+	now := time.Now()
+	limiter := newTestLimiter(&now)
+	limit := ratelimiter.Limit{Rate: 1, Burst: 3}
+
+	for i := 0; i < 3; i++ {
+		allowed, retryAfter, err := limiter.Allow("key", limit)
+
+		assert.Nil(t, err)
+		assert.True(t, allowed)
+		assert.EqualValues(t, 0, retryAfter)
+	}
+
+	allowed, retryAfter, err := limiter.Allow("key", limit)
+
+	assert.Nil(t, err)
+	assert.False(t, allowed)
+	assert.EqualValues(t, time.Second, retryAfter)
+
+	allowed, _, _ = limiter.Allow("other_key", limit)
+	assert.True(t, allowed)
+}
+
+func TestAllowRefillsOverTime(t *testing.T) {
+	now := time.Now()
+	limiter := newTestLimiter(&now)
+	limit := ratelimiter.Limit{Rate: 2, Burst: 1}
+
+	allowed, _, _ := limiter.Allow("key", limit)
+	assert.True(t, allowed)
+
+	now = now.Add(250 * time.Millisecond)
+
+	allowed, retryAfter, _ := limiter.Allow("key", limit)
+	assert.False(t, allowed)
+	assert.EqualValues(t, 250*time.Millisecond, retryAfter)
+
+	now = now.Add(250 * time.Millisecond)
+
+	allowed, _, _ = limiter.Allow("key", limit)
+	assert.True(t, allowed)
+}
+
+func TestSweepRemovesFullBuckets(t *testing.T) {
+	now := time.Now()
+	limiter := newTestLimiter(&now)
+
+	limiter.Allow("slow", ratelimiter.Limit{Rate: 0.001, Burst: 1})
+	limiter.Allow("fast", ratelimiter.Limit{Rate: 10, Burst: 1})
+
+	now = now.Add(sweepInterval)
+
+	limiter.Allow("new", ratelimiter.Limit{Rate: 10, Burst: 1})
+
+	assert.Contains(t, limiter.buckets, "slow")
+	assert.NotContains(t, limiter.buckets, "fast")
+	assert.Contains(t, limiter.buckets, "new")
+}
@@ -0,0 +1,18 @@
+package movieprovider
+
+import (
+	"errors"
+
+	"github.com/ryanbradynd05/go-tmdb"
+)
+
+var (
+	ErrMovieNotFound = errors.New("movie not found")
+)
+
+type MovieProvider interface {
+	SearchMovies(query string, options map[string]string) (*tmdb.MovieSearchResults, error)
+	GetMovieById(movieId int) (*tmdb.Movie, error)
+	GetMovieCredits(movieId int) (*tmdb.MovieCredits, error)
+	GetMovieImages(movieId int) (*tmdb.MovieImages, error)
+}
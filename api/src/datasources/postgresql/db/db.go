@@ -2,27 +2,52 @@ package postgresdb
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/ericbg27/top10movies-api/src/datasources/database"
 	"github.com/ericbg27/top10movies-api/src/utils/config"
 	"github.com/ericbg27/top10movies-api/src/utils/logger"
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
+	"go.uber.org/zap"
 )
 
 type PostgresDBClient struct {
-	Client *pgxpool.Conn
+	Client *pgxpool.Pool
+
+	stopStats chan struct{}
+}
+
+// postgresRow reports a missing row as database.ErrNoRows so DAOs do not depend on pgx
+type postgresRow struct {
+	row pgx.Row
+}
+
+func (r postgresRow) Scan(destinations ...interface{}) error {
+	err := r.row.Scan(destinations...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return database.ErrNoRows
+	}
+
+	return err
 }
 
 var (
-	host     = config.GetConfig().Database.Host
-	port     = config.GetConfig().Database.Port
-	user     = config.GetConfig().Database.User
-	password = config.GetConfig().Database.Password
-	dbname   = config.GetConfig().Database.DbName
-	loglevel = config.GetConfig().Database.LogLevel
+	host              = config.GetConfig().Database.Host
+	port              = config.GetConfig().Database.Port
+	user              = config.GetConfig().Database.User
+	password          = config.GetConfig().Database.Password
+	dbname            = config.GetConfig().Database.DbName
+	loglevel          = config.GetConfig().Database.LogLevel
+	maxConns          = config.GetConfig().Database.MaxConns
+	minConns          = config.GetConfig().Database.MinConns
+	maxConnLifetime   = config.GetConfig().Database.MaxConnLifetime
+	maxConnIdleTime   = config.GetConfig().Database.MaxConnIdleTime
+	healthCheckPeriod = config.GetConfig().Database.HealthCheckPeriod
+	statsInterval     = config.GetConfig().Database.StatsInterval
 )
 
 func (p *PostgresDBClient) SetupDbConnection() {
@@ -40,21 +65,84 @@ func (p *PostgresDBClient) SetupDbConnection() {
 	}
 	config.ConnConfig.LogLevel = level
 
-	clientPool, err := pgxpool.ConnectConfig(context.Background(), config)
+	if maxConns > 0 {
+		config.MaxConns = maxConns
+	}
+	if minConns > 0 {
+		config.MinConns = minConns
+	}
+	if maxConnLifetime > 0 {
+		config.MaxConnLifetime = maxConnLifetime
+	}
+	if maxConnIdleTime > 0 {
+		config.MaxConnIdleTime = maxConnIdleTime
+	}
+	if healthCheckPeriod > 0 {
+		config.HealthCheckPeriod = healthCheckPeriod
+	}
+
+	p.Client, err = pgxpool.ConnectConfig(context.Background(), config)
 	if err != nil {
 		logger.Error(fmt.Sprintf("Unable to connect to database: %v\n", err.Error()), err)
 		panic(err)
 	}
 
-	p.Client, err = clientPool.Acquire(context.Background())
-	if err != nil {
-		logger.Error("Unable to acquire connection to database", err)
-		panic(err)
+	logger.Info(fmt.Sprintf("Connected to database at %s:%d with a pool of up to %d connections", host, port, config.MaxConns))
+
+	if statsInterval > 0 {
+		p.stopStats = make(chan struct{})
+		go p.reportStats(statsInterval)
 	}
 }
 
 func (p *PostgresDBClient) CloseDbConnection(ctx context.Context) {
-	p.Client.Conn().Close(context.Background())
+	if p.stopStats != nil {
+		close(p.stopStats)
+		p.stopStats = nil
+	}
+
+	p.Client.Close()
+}
+
+func (p *PostgresDBClient) Stats() database.PoolStats {
+	stat := p.Client.Stat()
+
+	return database.PoolStats{
+		TotalConns:           stat.TotalConns(),
+		IdleConns:            stat.IdleConns(),
+		AcquiredConns:        stat.AcquiredConns(),
+		ConstructingConns:    stat.ConstructingConns(),
+		MaxConns:             stat.MaxConns(),
+		AcquireCount:         stat.AcquireCount(),
+		EmptyAcquireCount:    stat.EmptyAcquireCount(),
+		CanceledAcquireCount: stat.CanceledAcquireCount(),
+		AcquireDuration:      stat.AcquireDuration(),
+	}
+}
+
+func (p *PostgresDBClient) reportStats(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	stop := p.stopStats
+
+	for {
+		select {
+		case <-ticker.C:
+			stats := p.Stats()
+			logger.Info("Database pool stats",
+				zap.Int32("total_conns", stats.TotalConns),
+				zap.Int32("idle_conns", stats.IdleConns),
+				zap.Int32("acquired_conns", stats.AcquiredConns),
+				zap.Int32("max_conns", stats.MaxConns),
+				zap.Int64("acquire_count", stats.AcquireCount),
+				zap.Int64("empty_acquire_count", stats.EmptyAcquireCount),
+				zap.Duration("acquire_duration", stats.AcquireDuration),
+			)
+		case <-stop:
+			return
+		}
+	}
 }
 
 func (p *PostgresDBClient) Query(ctx context.Context, query string, arguments ...interface{}) (database.MultipleElementsResult, error) {
@@ -69,7 +157,7 @@ func (p *PostgresDBClient) Query(ctx context.Context, query string, arguments ..
 func (p *PostgresDBClient) QueryRow(ctx context.Context, query string, arguments ...interface{}) (database.SingleElementResult, error) {
 	result := p.Client.QueryRow(ctx, query, arguments...)
 
-	return result, nil
+	return postgresRow{row: result}, nil
 }
 
 func (p *PostgresDBClient) Exec(ctx context.Context, query string, arguments ...interface{}) (database.ModificationResult, error) {
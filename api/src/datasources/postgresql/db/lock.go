@@ -0,0 +1,37 @@
+package postgresdb
+
+import (
+	"context"
+
+	"github.com/ericbg27/top10movies-api/src/utils/logger"
+)
+
+const (
+	queryAdvisoryLock   = "SELECT pg_advisory_lock($1);"
+	queryAdvisoryUnlock = "SELECT pg_advisory_unlock($1);"
+)
+
+// WithAdvisoryLock runs fn while a dedicated connection holds the session advisory lock key, so
+// processes running fn with the same key take turns. fn may use the pool as usual.
+func (p *PostgresDBClient) WithAdvisoryLock(ctx context.Context, key int64, fn func() error) error {
+	conn, err := p.Client.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, queryAdvisoryLock, key); err != nil {
+		return err
+	}
+
+	defer func() {
+		// Closing the connection ends the session, which drops the lock the unlock failed to release
+		if _, err := conn.Exec(context.Background(), queryAdvisoryUnlock, key); err != nil {
+			logger.Error("Error when trying to release database advisory lock", err)
+			conn.Conn().Close(context.Background())
+		}
+	}()
+
+	return fn()
+}
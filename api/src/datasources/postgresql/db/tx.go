@@ -0,0 +1,75 @@
+package postgresdb
+
+import (
+	"context"
+
+	"github.com/ericbg27/top10movies-api/src/datasources/database"
+	"github.com/jackc/pgx/v4"
+)
+
+type postgresTransaction struct {
+	tx pgx.Tx
+}
+
+func (p *PostgresDBClient) BeginTx(ctx context.Context) (database.Transaction, error) {
+	tx, err := p.Client.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &postgresTransaction{tx: tx}, nil
+}
+
+func (p *PostgresDBClient) WithTx(ctx context.Context, fn func(tx database.Transaction) error) error {
+	tx, err := p.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback(ctx)
+			panic(r)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		tx.Rollback(ctx)
+
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (t *postgresTransaction) Query(ctx context.Context, query string, arguments ...interface{}) (database.MultipleElementsResult, error) {
+	result, err := t.tx.Query(ctx, query, arguments...)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (t *postgresTransaction) QueryRow(ctx context.Context, query string, arguments ...interface{}) (database.SingleElementResult, error) {
+	result := t.tx.QueryRow(ctx, query, arguments...)
+
+	return postgresRow{row: result}, nil
+}
+
+func (t *postgresTransaction) Exec(ctx context.Context, query string, arguments ...interface{}) (database.ModificationResult, error) {
+	result, err := t.tx.Exec(ctx, query, arguments...)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (t *postgresTransaction) Commit(ctx context.Context) error {
+	return t.tx.Commit(ctx)
+}
+
+func (t *postgresTransaction) Rollback(ctx context.Context) error {
+	return t.tx.Rollback(ctx)
+}
@@ -0,0 +1,293 @@
+package migrations
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ericbg27/top10movies-api/src/datasources/database"
+	schema_migrations_queries "github.com/ericbg27/top10movies-api/src/queries/schema_migrations"
+	"github.com/ericbg27/top10movies-api/src/utils/logger"
+)
+
+const (
+	upDirection   = "up"
+	downDirection = "down"
+
+	// lockKey is the advisory lock key that serializes migration runs, "top10mig" in ASCII
+	lockKey int64 = 0x746f7031306d6967
+)
+
+var (
+	//go:embed sql/*.sql
+	migrationFiles embed.FS
+
+	migrationFileRegex = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+	ErrDatabaseAhead = errors.New("database schema is ahead of this binary")
+	ErrNothingToUndo = errors.New("no applied migrations to roll back")
+	ErrCannotLock    = errors.New("database client cannot lock migration runs")
+)
+
+// Locker runs fn while holding a lock shared by every process connected to the same database
+type Locker interface {
+	WithAdvisoryLock(ctx context.Context, key int64, fn func() error) error
+}
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+type Migrator struct {
+	db         database.DatabaseClient
+	locker     Locker
+	migrations []Migration
+}
+
+func NewMigrator(db database.DatabaseClient) (*Migrator, error) {
+	locker, ok := db.(Locker)
+	if !ok {
+		return nil, ErrCannotLock
+	}
+
+	migrations, err := loadMigrations(migrationFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		db:         db,
+		locker:     locker,
+		migrations: migrations,
+	}, nil
+}
+
+func loadMigrations(files fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(files, "sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+
+	for _, entry := range entries {
+		matches := migrationFileRegex.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			return nil, fmt.Errorf("invalid migration file name: %s", entry.Name())
+		}
+
+		version, _ := strconv.Atoi(matches[1])
+
+		content, err := fs.ReadFile(files, path.Join("sql", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: matches[2]}
+			byVersion[version] = migration
+		} else if migration.Name != matches[2] {
+			return nil, fmt.Errorf("migration version %d has conflicting names", version)
+		}
+
+		if matches[3] == upDirection {
+			migration.Up = string(content)
+		} else if matches[3] == downDirection {
+			migration.Down = string(content)
+		}
+	}
+
+	var migrations []Migration
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s must have both up and down files", migration.Version, migration.Name)
+		}
+
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+func (m *Migrator) LatestVersion() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+func (m *Migrator) appliedMigrations(ctx context.Context) (map[int]time.Time, error) {
+	if _, err := m.db.Exec(ctx, schema_migrations_queries.QueryCreateSchemaMigrations); err != nil {
+		return nil, err
+	}
+
+	result, err := m.db.Query(ctx, schema_migrations_queries.QueryGetAppliedMigrations)
+	if err != nil {
+		return nil, err
+	}
+
+	defer result.Close()
+
+	applied := make(map[int]time.Time)
+	for result.Next() {
+		var version int
+		var appliedAt time.Time
+
+		if err := result.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+
+		applied[version] = appliedAt
+	}
+
+	return applied, nil
+}
+
+func (m *Migrator) CheckVersion(ctx context.Context) error {
+	applied, err := m.appliedMigrations(ctx)
+	if err != nil {
+		return err
+	}
+
+	return m.checkAhead(applied)
+}
+
+func (m *Migrator) checkAhead(applied map[int]time.Time) error {
+	for version := range applied {
+		if version > m.LatestVersion() {
+			return fmt.Errorf("%w: database is at version %d, latest known is %d", ErrDatabaseAhead, version, m.LatestVersion())
+		}
+	}
+
+	return nil
+}
+
+// Up applies every pending migration. Concurrent runs, such as several instances starting with
+// auto migrate on, wait for each other instead of applying the same migrations twice.
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.locker.WithAdvisoryLock(ctx, lockKey, func() error {
+		return m.up(ctx)
+	})
+}
+
+func (m *Migrator) up(ctx context.Context) error {
+	applied, err := m.appliedMigrations(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := m.checkAhead(applied); err != nil {
+		return err
+	}
+
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		err := m.db.WithTx(ctx, func(tx database.Transaction) error {
+			if _, err := tx.Exec(ctx, migration.Up); err != nil {
+				return err
+			}
+
+			_, err := tx.Exec(ctx, schema_migrations_queries.QueryInsertMigration, migration.Version, migration.Name)
+
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("applying migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+
+		logger.Info(fmt.Sprintf("Applied migration %d_%s", migration.Version, migration.Name))
+	}
+
+	return nil
+}
+
+// Down rolls back the latest applied migration, holding the same lock as Up
+func (m *Migrator) Down(ctx context.Context) error {
+	return m.locker.WithAdvisoryLock(ctx, lockKey, func() error {
+		return m.down(ctx)
+	})
+}
+
+func (m *Migrator) down(ctx context.Context) error {
+	applied, err := m.appliedMigrations(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := m.checkAhead(applied); err != nil {
+		return err
+	}
+
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+
+		err := m.db.WithTx(ctx, func(tx database.Transaction) error {
+			if _, err := tx.Exec(ctx, migration.Down); err != nil {
+				return err
+			}
+
+			_, err := tx.Exec(ctx, schema_migrations_queries.QueryDeleteMigration, migration.Version)
+
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("rolling back migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+
+		logger.Info(fmt.Sprintf("Rolled back migration %d_%s", migration.Version, migration.Name))
+
+		return nil
+	}
+
+	return ErrNothingToUndo
+}
+
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := m.appliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []MigrationStatus
+	for _, migration := range m.migrations {
+		appliedAt, ok := applied[migration.Version]
+
+		statuses = append(statuses, MigrationStatus{
+			Version:   migration.Version,
+			Name:      migration.Name,
+			Applied:   ok,
+			AppliedAt: appliedAt,
+		})
+	}
+
+	return statuses, nil
+}
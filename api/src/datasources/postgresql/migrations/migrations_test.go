@@ -0,0 +1,106 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	database_mock "github.com/ericbg27/top10movies-api/src/mocks/database"
+	"github.com/stretchr/testify/assert"
+)
+
+// lockerMock refuses the lock, so the migrator must not touch the database
+type lockerMock struct {
+	keys []int64
+}
+
+func (l *lockerMock) WithAdvisoryLock(ctx context.Context, key int64, fn func() error) error {
+	l.keys = append(l.keys, key)
+
+	return errors.New("unable to lock")
+}
+
+func TestLoadEmbeddedMigrations(t *testing.T) {
+	migrations, err := loadMigrations(migrationFiles)
+
+	assert.Nil(t, err)
+	assert.NotEmpty(t, migrations)
+
+	for index, migration := range migrations {
+		assert.EqualValues(t, index+1, migration.Version)
+		assert.NotEmpty(t, migration.Up)
+		assert.NotEmpty(t, migration.Down)
+	}
+}
+
+func TestLoadMigrationsOrdered(t *testing.T) {
+	files := fstest.MapFS{
+		"sql/0002_second.up.sql":   {Data: []byte("CREATE TABLE b ();")},
+		"sql/0002_second.down.sql": {Data: []byte("DROP TABLE b;")},
+		"sql/0001_first.up.sql":    {Data: []byte("CREATE TABLE a ();")},
+		"sql/0001_first.down.sql":  {Data: []byte("DROP TABLE a;")},
+	}
+
+	migrations, err := loadMigrations(files)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, 2, len(migrations))
+	assert.EqualValues(t, 1, migrations[0].Version)
+	assert.EqualValues(t, "first", migrations[0].Name)
+	assert.EqualValues(t, "CREATE TABLE a ();", migrations[0].Up)
+	assert.EqualValues(t, "DROP TABLE a;", migrations[0].Down)
+	assert.EqualValues(t, 2, migrations[1].Version)
+	assert.EqualValues(t, "second", migrations[1].Name)
+}
+
+func TestLoadMigrationsInvalidName(t *testing.T) {
+	files := fstest.MapFS{
+		"sql/first.up.sql": {Data: []byte("CREATE TABLE a ();")},
+	}
+
+	migrations, err := loadMigrations(files)
+
+	assert.Nil(t, migrations)
+	assert.NotNil(t, err)
+}
+
+func TestLoadMigrationsMissingDown(t *testing.T) {
+	files := fstest.MapFS{
+		"sql/0001_first.up.sql": {Data: []byte("CREATE TABLE a ();")},
+	}
+
+	migrations, err := loadMigrations(files)
+
+	assert.Nil(t, migrations)
+	assert.NotNil(t, err)
+}
+
+func TestCheckAhead(t *testing.T) {
+	migrator := &Migrator{
+		migrations: []Migration{{Version: 1}, {Version: 2}},
+	}
+
+	assert.Nil(t, migrator.checkAhead(map[int]time.Time{1: {}, 2: {}}))
+	assert.ErrorIs(t, migrator.checkAhead(map[int]time.Time{1: {}, 3: {}}), ErrDatabaseAhead)
+}
+
+func TestNewMigratorRequiresLocker(t *testing.T) {
+	migrator, err := NewMigrator(&database_mock.DatabaseClientMock{})
+
+	assert.Nil(t, migrator)
+	assert.ErrorIs(t, err, ErrCannotLock)
+}
+
+func TestUpAndDownHoldLock(t *testing.T) {
+	locker := &lockerMock{}
+	migrator := &Migrator{
+		locker:     locker,
+		migrations: []Migration{{Version: 1}},
+	}
+
+	assert.EqualError(t, migrator.Up(context.Background()), "unable to lock")
+	assert.EqualError(t, migrator.Down(context.Background()), "unable to lock")
+	assert.EqualValues(t, []int64{lockKey, lockKey}, locker.keys)
+}
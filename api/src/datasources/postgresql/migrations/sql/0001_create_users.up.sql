@@ -0,0 +1,9 @@
+CREATE TABLE users (
+    id           BIGSERIAL PRIMARY KEY,
+    first_name   VARCHAR(255) NOT NULL,
+    last_name    VARCHAR(255) NOT NULL,
+    email        VARCHAR(255) NOT NULL UNIQUE,
+    date_created DATE NOT NULL DEFAULT CURRENT_DATE,
+    status       VARCHAR(20) NOT NULL,
+    password     VARCHAR(255) NOT NULL
+);
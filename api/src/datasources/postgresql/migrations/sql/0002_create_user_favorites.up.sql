@@ -0,0 +1,7 @@
+CREATE TABLE user_favorites (
+    user_id  BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    movie_id INTEGER NOT NULL,
+    rank     SMALLINT NOT NULL CHECK (rank BETWEEN 1 AND 10),
+    PRIMARY KEY (user_id, movie_id),
+    CONSTRAINT user_favorites_rank_key UNIQUE (user_id, rank) DEFERRABLE INITIALLY DEFERRED
+);
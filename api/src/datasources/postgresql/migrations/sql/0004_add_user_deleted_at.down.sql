@@ -0,0 +1,5 @@
+DROP INDEX users_deleted_at_idx;
+
+ALTER TABLE users
+    DROP CONSTRAINT users_status_check,
+    DROP COLUMN deleted_at;
@@ -0,0 +1,6 @@
+ALTER TABLE users
+    ADD COLUMN deleted_at TIMESTAMPTZ NULL,
+    ADD CONSTRAINT users_status_check
+        CHECK (status IN ('pending', 'active', 'suspended', 'deleted'));
+
+CREATE INDEX users_deleted_at_idx ON users (deleted_at) WHERE status = 'deleted';
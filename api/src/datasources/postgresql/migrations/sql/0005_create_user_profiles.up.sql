@@ -0,0 +1,8 @@
+CREATE TABLE user_profiles (
+    user_id         BIGINT PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    handle          VARCHAR(30) NOT NULL UNIQUE,
+    display_name    VARCHAR(50) NOT NULL DEFAULT '',
+    bio             VARCHAR(500) NOT NULL DEFAULT '',
+    avatar_url      VARCHAR(2048) NOT NULL DEFAULT '',
+    favorite_genres TEXT[] NOT NULL DEFAULT '{}'
+);
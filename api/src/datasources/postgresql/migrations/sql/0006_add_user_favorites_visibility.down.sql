@@ -0,0 +1,3 @@
+ALTER TABLE users
+    DROP COLUMN favorites_share_token,
+    DROP COLUMN favorites_visibility;
@@ -0,0 +1,4 @@
+ALTER TABLE users
+    ADD COLUMN favorites_visibility VARCHAR(20) NOT NULL DEFAULT 'public'
+    CHECK (favorites_visibility IN ('public', 'followers', 'private', 'unlisted')),
+    ADD COLUMN favorites_share_token VARCHAR(64) NULL UNIQUE;
@@ -0,0 +1,2 @@
+DROP TABLE favorite_events;
+DROP TABLE user_follows;
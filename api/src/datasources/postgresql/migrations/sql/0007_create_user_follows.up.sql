@@ -0,0 +1,21 @@
+CREATE TABLE user_follows (
+    follower_id  BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    followed_id  BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    date_created TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (follower_id, followed_id),
+    CHECK (follower_id <> followed_id)
+);
+
+CREATE INDEX user_follows_followed_id_idx ON user_follows (followed_id);
+
+CREATE TABLE favorite_events (
+    id            BIGSERIAL PRIMARY KEY,
+    user_id       BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    movie_id      INTEGER NOT NULL,
+    event_type    VARCHAR(20) NOT NULL CHECK (event_type IN ('added', 'removed', 'reranked')),
+    rank          SMALLINT NOT NULL,
+    previous_rank SMALLINT NOT NULL DEFAULT 0,
+    date_created  TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX favorite_events_user_id_idx ON favorite_events (user_id, id);
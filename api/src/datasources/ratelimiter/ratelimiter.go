@@ -0,0 +1,15 @@
+package ratelimiter
+
+import (
+	"time"
+)
+
+// Limit describes a token bucket refilled with Rate tokens per second that holds at most Burst tokens
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+type RateLimiter interface {
+	Allow(key string, limit Limit) (bool, time.Duration, error)
+}
@@ -0,0 +1,77 @@
+package redisdb
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/ericbg27/top10movies-api/src/datasources/ratelimiter"
+	"github.com/go-redis/redis"
+)
+
+// tokenBucketScript refills and takes a token atomically so every instance shares the same buckets.
+// It returns whether the request is allowed and, when it is not, how many milliseconds to wait.
+var tokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local ttl = tonumber(ARGV[4])
+
+local state = redis.call("HMGET", KEYS[1], "tokens", "updated_at")
+local tokens = tonumber(state[1])
+local updatedAt = tonumber(state[2])
+
+if tokens == nil or updatedAt == nil then
+	tokens = burst
+	updatedAt = now
+end
+
+local elapsed = math.max(0, now - updatedAt)
+tokens = math.min(burst, tokens + elapsed * rate / 1000)
+
+local allowed = 0
+local retryAfter = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retryAfter = math.ceil((1 - tokens) * 1000 / rate)
+end
+
+redis.call("HMSET", KEYS[1], "tokens", tostring(tokens), "updated_at", tostring(now))
+redis.call("PEXPIRE", KEYS[1], ttl)
+
+return {allowed, retryAfter}
+`)
+
+type RedisRateLimiter struct {
+	Client *redis.Client
+}
+
+func NewRedisRateLimiter(client *redis.Client) *RedisRateLimiter {
+	return &RedisRateLimiter{
+		Client: client,
+	}
+}
+
+func (r *RedisRateLimiter) Allow(key string, limit ratelimiter.Limit) (bool, time.Duration, error) {
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+
+	// An idle bucket is full again after burst / rate seconds, there is no point in keeping it longer
+	ttl := int64(math.Ceil(float64(limit.Burst)/limit.Rate*1000)) + 1000
+
+	result, err := tokenBucketScript.Run(r.Client, []string{key}, limit.Rate, limit.Burst, now, ttl).Result()
+	if err != nil {
+		return false, 0, err
+	}
+
+	values, ok := result.([]interface{})
+	if !ok || len(values) != 2 {
+		return false, 0, fmt.Errorf("unexpected rate limiter result: %v", result)
+	}
+
+	allowed, _ := values[0].(int64)
+	retryAfter, _ := values[1].(int64)
+
+	return allowed == 1, time.Duration(retryAfter) * time.Millisecond, nil
+}
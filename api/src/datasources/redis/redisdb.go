@@ -3,7 +3,9 @@ package redisdb
 import (
 	"fmt"
 	"os"
+	"time"
 
+	"github.com/ericbg27/top10movies-api/src/datasources/cache"
 	"github.com/ericbg27/top10movies-api/src/utils/logger"
 	"github.com/go-redis/redis"
 )
@@ -12,22 +14,21 @@ const (
 	localRedis = "localhost:6379"
 )
 
-var (
-	Client   *redis.Client
-	RedisNil = redis.Nil
-)
+type RedisCacheClient struct {
+	Client *redis.Client
+}
 
-func SetupRedisConnection() {
+func (r *RedisCacheClient) SetupCacheConnection() {
 	dsn := os.Getenv("REDIS_DSN")
 	if len(dsn) == 0 {
 		dsn = localRedis
 	}
 
-	Client = redis.NewClient(&redis.Options{
+	r.Client = redis.NewClient(&redis.Options{
 		Addr: dsn,
 	})
 
-	_, err := Client.Ping().Result()
+	_, err := r.Client.Ping().Result()
 	if err != nil {
 		logger.Error(fmt.Sprintf("Unable to connect to Redis at %s: %s\n", dsn, err.Error()), err)
 
@@ -36,3 +37,76 @@ func SetupRedisConnection() {
 
 	logger.Info(fmt.Sprintf("Connected to Redis at %s", dsn))
 }
+
+func (r *RedisCacheClient) CloseCacheConnection() {
+	r.Client.Close()
+}
+
+func (r *RedisCacheClient) Get(key string) (string, error) {
+	result, err := r.Client.Get(key).Result()
+	if err == redis.Nil {
+		return "", cache.ErrCacheMiss
+	}
+
+	return result, err
+}
+
+func (r *RedisCacheClient) Set(key string, value interface{}, ttl time.Duration) error {
+	return r.Client.Set(key, value, ttl).Err()
+}
+
+func (r *RedisCacheClient) Del(keys ...string) (int64, error) {
+	return r.Client.Del(keys...).Result()
+}
+
+func (r *RedisCacheClient) MGet(keys ...string) (map[string]string, error) {
+	values := make(map[string]string)
+
+	if len(keys) == 0 {
+		return values, nil
+	}
+
+	result, err := r.Client.MGet(keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	for index, value := range result {
+		if str, ok := value.(string); ok {
+			values[keys[index]] = str
+		}
+	}
+
+	return values, nil
+}
+
+func toInterfaces(members []string) []interface{} {
+	values := make([]interface{}, len(members))
+	for index, member := range members {
+		values[index] = member
+	}
+
+	return values
+}
+
+func (r *RedisCacheClient) SAdd(key string, ttl time.Duration, members ...string) error {
+	pipe := r.Client.TxPipeline()
+	pipe.SAdd(key, toInterfaces(members)...)
+	pipe.Expire(key, ttl)
+
+	_, err := pipe.Exec()
+
+	return err
+}
+
+func (r *RedisCacheClient) SRem(key string, members ...string) error {
+	if len(members) == 0 {
+		return nil
+	}
+
+	return r.Client.SRem(key, toInterfaces(members)...).Err()
+}
+
+func (r *RedisCacheClient) SMembers(key string) ([]string, error) {
+	return r.Client.SMembers(key).Result()
+}
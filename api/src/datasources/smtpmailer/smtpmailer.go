@@ -0,0 +1,54 @@
+package smtpmailer
+
+import (
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+
+	"github.com/ericbg27/top10movies-api/src/datasources/mailer"
+)
+
+type SMTPMailer struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+func NewSMTPMailer(host string, port int, username string, password string, from string) *SMTPMailer {
+	return &SMTPMailer{
+		Host:     host,
+		Port:     port,
+		Username: username,
+		Password: password,
+		From:     from,
+	}
+}
+
+func (s *SMTPMailer) buildMessage(message mailer.Message) []byte {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("From: %s\r\n", s.From))
+	sb.WriteString(fmt.Sprintf("To: %s\r\n", message.To))
+	sb.WriteString(fmt.Sprintf("Subject: %s\r\n", message.Subject))
+	sb.WriteString("MIME-Version: 1.0\r\n")
+	sb.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	sb.WriteString("\r\n")
+	sb.WriteString(strings.ReplaceAll(message.Body, "\n", "\r\n"))
+
+	return []byte(sb.String())
+}
+
+func (s *SMTPMailer) Send(message mailer.Message) error {
+	var auth smtp.Auth
+	if s.Username != "" {
+		auth = smtp.PlainAuth("", s.Username, s.Password, s.Host)
+	}
+
+	address := net.JoinHostPort(s.Host, strconv.Itoa(s.Port))
+
+	return smtp.SendMail(address, auth, s.From, []string{message.To}, s.buildMessage(message))
+}
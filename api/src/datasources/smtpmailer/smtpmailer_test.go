@@ -0,0 +1,28 @@
+package smtpmailer
+
+import (
+	"testing"
+
+	"github.com/ericbg27/top10movies-api/src/datasources/mailer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildMessage(t *testing.T) {
+	smtpMailer := NewSMTPMailer("localhost", 25, "", "", "no-reply@top10movies.com")
+
+	content := smtpMailer.buildMessage(mailer.Message{
+		To:      "johndoe@gmail.com",
+		Subject: "Hello",
+		Body:    "Hello\nJohn",
+	})
+
+	expected := "From: no-reply@top10movies.com\r\n" +
+		"To: johndoe@gmail.com\r\n" +
+		"Subject: Hello\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: text/plain; charset=\"utf-8\"\r\n" +
+		"\r\n" +
+		"Hello\r\nJohn"
+
+	assert.EqualValues(t, expected, string(content))
+}
@@ -0,0 +1,80 @@
+package tmdbprovider
+
+import (
+	"fmt"
+
+	"github.com/ericbg27/top10movies-api/src/datasources/movieprovider"
+	"github.com/ryanbradynd05/go-tmdb"
+)
+
+// TMDB status codes (not HTTP ones) answered for ids that do not match any movie
+const (
+	statusInvalidID        = 6
+	statusResourceNotFound = 34
+)
+
+type TMDBMovieProvider struct {
+	api *tmdb.TMDb
+}
+
+func NewTMDBMovieProvider(apiKey string) *TMDBMovieProvider {
+	tmdbConfig := tmdb.Config{
+		APIKey:   apiKey,
+		Proxies:  nil,
+		UseProxy: false,
+	}
+
+	return &TMDBMovieProvider{
+		api: tmdb.Init(tmdbConfig),
+	}
+}
+
+// translateError maps the TMDB not found answers to movieprovider.ErrMovieNotFound. The client
+// only keeps the TMDB status code of a failed request, formatted as "Code (<status>): <message>".
+func translateError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var status int
+	if _, scanErr := fmt.Sscanf(err.Error(), "Code (%d):", &status); scanErr != nil {
+		return err
+	}
+
+	if status == statusInvalidID || status == statusResourceNotFound {
+		return fmt.Errorf("%w: %s", movieprovider.ErrMovieNotFound, err)
+	}
+
+	return err
+}
+
+func (t *TMDBMovieProvider) SearchMovies(query string, options map[string]string) (*tmdb.MovieSearchResults, error) {
+	return t.api.SearchMovie(query, options)
+}
+
+func (t *TMDBMovieProvider) GetMovieById(movieId int) (*tmdb.Movie, error) {
+	movie, err := t.api.GetMovieInfo(movieId, nil)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	return movie, nil
+}
+
+func (t *TMDBMovieProvider) GetMovieCredits(movieId int) (*tmdb.MovieCredits, error) {
+	credits, err := t.api.GetMovieCredits(movieId, nil)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	return credits, nil
+}
+
+func (t *TMDBMovieProvider) GetMovieImages(movieId int) (*tmdb.MovieImages, error) {
+	images, err := t.api.GetMovieImages(movieId, nil)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	return images, nil
+}
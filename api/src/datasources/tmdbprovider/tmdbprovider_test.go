@@ -0,0 +1,30 @@
+package tmdbprovider
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ericbg27/top10movies-api/src/datasources/movieprovider"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranslateErrorNotFound(t *testing.T) {
+	err := translateError(errors.New("Code (34): The resource you requested could not be found."))
+
+	assert.True(t, errors.Is(err, movieprovider.ErrMovieNotFound))
+	assert.Contains(t, err.Error(), "The resource you requested could not be found.")
+
+	err = translateError(errors.New("Code (6): Invalid id: The pre-requisite id is invalid or not found."))
+
+	assert.True(t, errors.Is(err, movieprovider.ErrMovieNotFound))
+}
+
+func TestTranslateErrorOtherFailures(t *testing.T) {
+	apiErr := errors.New("Code (7): Invalid API key: You must be granted a valid key.")
+	assert.Equal(t, apiErr, translateError(apiErr))
+
+	connectionErr := errors.New("dial tcp: lookup api.themoviedb.org: no such host")
+	assert.Equal(t, connectionErr, translateError(connectionErr))
+
+	assert.Nil(t, translateError(nil))
+}
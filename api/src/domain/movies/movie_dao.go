@@ -6,21 +6,29 @@ import (
 	"strings"
 	"time"
 
-	redisdb "github.com/ericbg27/top10movies-api/src/datasources/redis"
+	"github.com/ericbg27/top10movies-api/src/datasources/cache"
 	"github.com/ericbg27/top10movies-api/src/utils/config"
 	"github.com/ericbg27/top10movies-api/src/utils/logger"
 	"github.com/ericbg27/top10movies-api/src/utils/rest_errors"
 )
 
 const (
-	CreatedAtLayout = "2006-02-01T15:04:05Z"
+	CreatedAtLayout = "2006-01-02T15:04:05Z"
 )
 
 var (
 	cachettl = config.GetConfig().Redis.CacheTtl
 )
 
-func (m MovieInfo) AddMovie() *rest_errors.RestErr {
+func CacheKey(movieId int) string {
+	var movieRedisKey strings.Builder
+	movieRedisKey.WriteString("movie:")
+	movieRedisKey.WriteString(strconv.Itoa(movieId))
+
+	return movieRedisKey.String()
+}
+
+func (m MovieInfo) AddMovie(c cache.CacheClient) *rest_errors.RestErr {
 	m.CreatedAt = time.Now().Format(CreatedAtLayout)
 
 	marshelledMovie, err := json.Marshal(m)
@@ -29,26 +37,23 @@ func (m MovieInfo) AddMovie() *rest_errors.RestErr {
 		return rest_errors.NewInternalServerError("Error when trying to add movie")
 	}
 
-	var movieRedisKey strings.Builder
-	movieRedisKey.WriteString("movie:")
-	movieRedisKey.WriteString(strconv.Itoa(m.Movie.ID))
-	redisdb.Client.Set(movieRedisKey.String(), marshelledMovie, time.Duration(cachettl*int64(time.Minute)))
+	err = c.Set(CacheKey(m.Movie.ID), marshelledMovie, time.Duration(cachettl*int64(time.Minute)))
+	if err != nil {
+		logger.Error("Error when trying to add movie", err)
+		return rest_errors.NewInternalServerError("Error when trying to add movie")
+	}
 
 	return nil
 }
 
-func (m MovieInfo) GetMovie() (MovieInterface, *rest_errors.RestErr) {
+func (m MovieInfo) GetMovie(c cache.CacheClient) (MovieInterface, *rest_errors.RestErr) {
 	var savedMovie MovieInfo
 
-	var movieRedisKey strings.Builder
-	movieRedisKey.WriteString("movie:")
-	movieRedisKey.WriteString(strconv.Itoa(m.Movie.ID))
-
-	result, err := redisdb.Client.Get(movieRedisKey.String()).Result()
-	if err != nil && err != redisdb.RedisNil {
+	result, err := c.Get(CacheKey(m.Movie.ID))
+	if err != nil && err != cache.ErrCacheMiss {
 		logger.Error("Error when trying to get movie", err)
 		return nil, rest_errors.NewInternalServerError("Error when trying to get movie")
-	} else if err == redisdb.RedisNil {
+	} else if err == cache.ErrCacheMiss {
 		savedMovie.Movie.ID = -1
 	} else {
 		marshalErr := json.Unmarshal([]byte(result), &savedMovie)
@@ -0,0 +1,94 @@
+package movies
+
+import (
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/ericbg27/top10movies-api/src/datasources/cache"
+	cache_mock "github.com/ericbg27/top10movies-api/src/mocks/cache"
+	"github.com/ryanbradynd05/go-tmdb"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	c cache.CacheClient
+)
+
+func TestMain(m *testing.M) {
+	c = &cache_mock.CacheClientMock{
+		CanGet: true,
+		CanSet: true,
+		CanDel: true,
+	}
+
+	c.SetupCacheConnection()
+	defer c.CloseCacheConnection()
+
+	os.Exit(m.Run())
+}
+
+func TestCacheKey(t *testing.T) {
+	assert.EqualValues(t, "movie:42", CacheKey(42))
+}
+
+func TestAddAndGetMovieSuccess(t *testing.T) {
+	movie := MovieInfo{
+		Movie: tmdb.Movie{
+			ID:    1,
+			Title: "Example Movie Title",
+		},
+	}
+
+	addErr := movie.AddMovie(c)
+	assert.Nil(t, addErr)
+
+	result, getErr := movie.GetMovie(c)
+	assert.Nil(t, getErr)
+
+	savedMovie := result.(MovieInfo)
+
+	assert.EqualValues(t, 1, savedMovie.Movie.ID)
+	assert.EqualValues(t, "Example Movie Title", savedMovie.Movie.Title)
+	assert.NotEmpty(t, savedMovie.CreatedAt)
+}
+
+func TestGetMovieNotCached(t *testing.T) {
+	movie := MovieInfo{
+		Movie: tmdb.Movie{
+			ID: 2,
+		},
+	}
+
+	result, err := movie.GetMovie(c)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, -1, result.(MovieInfo).Movie.ID)
+}
+
+func TestGetMovieCacheError(t *testing.T) {
+	var movie MovieInfo
+
+	c.(*cache_mock.CacheClientMock).CanGet = false
+
+	result, err := movie.GetMovie(c)
+
+	c.(*cache_mock.CacheClientMock).CanGet = true
+
+	assert.Nil(t, result)
+	assert.EqualValues(t, "Error when trying to get movie", err.Message)
+	assert.EqualValues(t, http.StatusInternalServerError, err.Status)
+}
+
+func TestAddMovieCacheError(t *testing.T) {
+	var movie MovieInfo
+
+	c.(*cache_mock.CacheClientMock).CanSet = false
+
+	err := movie.AddMovie(c)
+
+	c.(*cache_mock.CacheClientMock).CanSet = true
+
+	assert.EqualValues(t, "Error when trying to add movie", err.Message)
+	assert.EqualValues(t, http.StatusInternalServerError, err.Status)
+}
@@ -1,13 +1,14 @@
 package movies
 
 import (
+	"github.com/ericbg27/top10movies-api/src/datasources/cache"
 	"github.com/ericbg27/top10movies-api/src/utils/rest_errors"
 	"github.com/ryanbradynd05/go-tmdb"
 )
 
 type MovieInterface interface {
-	AddMovie() *rest_errors.RestErr
-	GetMovie() (MovieInterface, *rest_errors.RestErr)
+	AddMovie(cache.CacheClient) *rest_errors.RestErr
+	GetMovie(cache.CacheClient) (MovieInterface, *rest_errors.RestErr)
 }
 
 type MovieInfo struct {
@@ -3,75 +3,360 @@ package user_favorites
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"strconv"
-	"strings"
 
+	"github.com/ericbg27/top10movies-api/src/datasources/cache"
 	"github.com/ericbg27/top10movies-api/src/datasources/database"
-	redisdb "github.com/ericbg27/top10movies-api/src/datasources/redis"
 	"github.com/ericbg27/top10movies-api/src/domain/movies"
 	user_favorites_queries "github.com/ericbg27/top10movies-api/src/queries/user_favorites"
 	"github.com/ericbg27/top10movies-api/src/utils/logger"
+	"github.com/ericbg27/top10movies-api/src/utils/pagination"
 	"github.com/ericbg27/top10movies-api/src/utils/rest_errors"
 )
 
-func (u UserFavorites) GetFavorites(db database.DatabaseClient) (UserFavoritesInterface, map[int]bool, *rest_errors.RestErr) {
+func withTx(db database.DatabaseClient, errorMessage string, fn func(tx database.Transaction) *rest_errors.RestErr) *rest_errors.RestErr {
+	var restErr *rest_errors.RestErr
+
+	err := db.WithTx(context.Background(), func(tx database.Transaction) error {
+		if restErr = fn(tx); restErr != nil {
+			return restErr
+		}
+
+		return nil
+	})
+	if restErr != nil {
+		return restErr
+	}
+
+	if err != nil {
+		logger.Error(errorMessage, err)
+		return rest_errors.NewInternalServerError(errorMessage)
+	}
+
+	return nil
+}
+
+// recordEvent stores a change to the list in the same transaction as the change itself,
+// so the activity feed never shows changes that were rolled back.
+func (u UserFavorites) recordEvent(tx database.Transaction, event FavoriteEvent, errorMessage string) *rest_errors.RestErr {
+	_, err := tx.Exec(context.Background(), user_favorites_queries.QueryAddFavoriteEvent, u.UserID, event.MovieID, event.Type, event.Rank, event.PreviousRank)
+	if err != nil {
+		logger.Error("Error when trying to save user favorites event", err)
+		return rest_errors.NewInternalServerError(errorMessage)
+	}
+
+	return nil
+}
+
+// lockFavorites must run first in every transaction that changes the list. Ranks are computed
+// from the current list, so two unserialized changes could both claim the same rank.
+func (u UserFavorites) lockFavorites(tx database.Transaction, errorMessage string) *rest_errors.RestErr {
+	var ownerID int64
+	result, err := tx.QueryRow(context.Background(), user_favorites_queries.QueryLockUserFavorites, u.UserID)
+	if err == nil {
+		err = result.Scan(&ownerID)
+	}
+	if errors.Is(err, database.ErrNoRows) {
+		return rest_errors.NewNotFoundError("User not found")
+	}
+	if err != nil {
+		logger.Error("Error when trying to lock user favorites", err)
+		return rest_errors.NewInternalServerError(errorMessage)
+	}
+
+	return nil
+}
+
+func (u UserFavorites) getFavoritesIds(db database.Querier) ([]int, *rest_errors.RestErr) {
 	result, err := db.Query(context.Background(), user_favorites_queries.QueryGetUserFavoritesIds, u.UserID)
 	if err != nil {
 		logger.Error("Error when trying to get user favorites", err)
-		return nil, nil, rest_errors.NewInternalServerError("Error when trying to get user favorites")
+		return nil, rest_errors.NewInternalServerError("Error when trying to get user favorites")
 	}
 
-	var userFavorites UserFavorites
+	defer result.Close()
+
+	var moviesIds []int
 
 	for result.Next() {
 		var movieId int
 		err := result.Scan(&movieId)
 		if err != nil {
 			logger.Error("Error when trying to get user favorites IDs", err)
-			return nil, nil, rest_errors.NewInternalServerError("Error when trying to get user favorites")
+			return nil, rest_errors.NewInternalServerError("Error when trying to get user favorites")
 		}
 
-		userFavorites.MoviesIDs = append(userFavorites.MoviesIDs, movieId)
+		moviesIds = append(moviesIds, movieId)
+	}
+
+	return moviesIds, nil
+}
+
+func (u UserFavorites) GetFavorites(db database.DatabaseClient, c cache.CacheClient) (UserFavoritesInterface, map[int]bool, *rest_errors.RestErr) {
+	moviesIds, getErr := u.getFavoritesIds(db)
+	if getErr != nil {
+		return nil, nil, getErr
 	}
 
+	var userFavorites UserFavorites
+	userFavorites.MoviesIDs = moviesIds
+
 	cachedIds := make(map[int]bool)
 
-	for _, movieId := range userFavorites.MoviesIDs {
-		var movieRedisKey strings.Builder
-		movieRedisKey.WriteString("movie:")
-		movieRedisKey.WriteString(strconv.Itoa(movieId))
+	if len(moviesIds) == 0 {
+		return userFavorites, cachedIds, nil
+	}
 
-		redisResult, redisErr := redisdb.Client.Get(movieRedisKey.String()).Result()
-		if redisErr != nil && redisErr != redisdb.RedisNil { // Do we throw an error here? Maybe just log!
-			logger.Error("Error when trying to get user favorites", redisErr)
-			return nil, nil, rest_errors.NewInternalServerError("Error when trying to get user favorites")
-		}
+	keys := make([]string, len(moviesIds))
+	for index, movieId := range moviesIds {
+		keys[index] = movies.CacheKey(movieId)
+	}
 
-		if redisErr != redisdb.RedisNil {
-			var cachedFavorite movies.MovieInfo
-			err = json.Unmarshal([]byte(redisResult), &cachedFavorite)
-			if err != nil {
-				logger.Error("Error when trying to get user favorites", err)
-				return nil, nil, rest_errors.NewInternalServerError("Error when trying to get user favorites")
-			}
+	cacheResult, cacheErr := c.MGet(keys...)
+	if cacheErr != nil {
+		logger.Error("Error when trying to get user favorites from cache", cacheErr)
+		return userFavorites, cachedIds, nil
+	}
 
-			cachedIds[movieId] = true
-			userFavorites.MoviesData = append(userFavorites.MoviesData, cachedFavorite.Movie)
+	for index, movieId := range moviesIds {
+		cachedValue, ok := cacheResult[keys[index]]
+		if !ok {
+			continue
 		}
+
+		var cachedFavorite movies.MovieInfo
+		err := json.Unmarshal([]byte(cachedValue), &cachedFavorite)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Error when trying to decode cached movie %d", movieId), err)
+			continue
+		}
+
+		cachedIds[movieId] = true
+		userFavorites.MoviesData = append(userFavorites.MoviesData, cachedFavorite.Movie)
 	}
 
 	return userFavorites, cachedIds, nil
 }
 
 func (u UserFavorites) AddFavorite(db database.DatabaseClient) *rest_errors.RestErr {
-	result, err := db.Exec(context.Background(), user_favorites_queries.QueryAddUserFavorite, u.UserID, u.MoviesIDs[0])
+	return withTx(db, "Error when trying to add user favorite", func(tx database.Transaction) *rest_errors.RestErr {
+		if lockErr := u.lockFavorites(tx, "Error when trying to add user favorite"); lockErr != nil {
+			return lockErr
+		}
+
+		countResult, err := tx.QueryRow(context.Background(), user_favorites_queries.QueryCountUserFavorites, u.UserID)
+		if err != nil {
+			logger.Error("Error when trying to count user favorites", err)
+			return rest_errors.NewInternalServerError("Error when trying to add user favorite")
+		}
+
+		var favoritesCount int64
+		if err = countResult.Scan(&favoritesCount); err != nil {
+			logger.Error("Error when trying to count user favorites", err)
+			return rest_errors.NewInternalServerError("Error when trying to add user favorite")
+		}
+
+		if favoritesCount >= MaxFavorites {
+			return rest_errors.NewBadRequestError(fmt.Sprintf("User favorites list cannot have more than %d movies", MaxFavorites))
+		}
+
+		result, err := tx.Exec(context.Background(), user_favorites_queries.QueryAddUserFavorite, u.UserID, u.MoviesIDs[0], favoritesCount+1)
+		if err != nil {
+			logger.Error("Error when trying to prepare add user favorite statement", err)
+			return rest_errors.NewBadRequestError("Error when trying to add user favorite")
+		}
+
+		logger.Info(fmt.Sprintf("Saved user favorite in the database. Rows affected: %d", result.RowsAffected()))
+
+		return u.recordEvent(tx, FavoriteEvent{MovieID: u.MoviesIDs[0], Type: EventAdded, Rank: int(favoritesCount) + 1}, "Error when trying to add user favorite")
+	})
+}
+
+func (u UserFavorites) ReorderFavorites(db database.DatabaseClient) *rest_errors.RestErr {
+	return withTx(db, "Error when trying to reorder user favorites", func(tx database.Transaction) *rest_errors.RestErr {
+		if lockErr := u.lockFavorites(tx, "Error when trying to reorder user favorites"); lockErr != nil {
+			return lockErr
+		}
+
+		currentIds, getErr := u.getFavoritesIds(tx)
+		if getErr != nil {
+			return getErr
+		}
+
+		if validateErr := u.ValidateOrder(currentIds); validateErr != nil {
+			return validateErr
+		}
+
+		result, err := tx.Exec(context.Background(), user_favorites_queries.QueryReorderUserFavorites, u.UserID, u.MoviesIDs)
+		if err != nil {
+			logger.Error("Error when trying to reorder user favorites", err)
+			return rest_errors.NewInternalServerError("Error when trying to reorder user favorites")
+		}
+
+		logger.Info(fmt.Sprintf("Reordered user favorites in the database. Rows affected: %d", result.RowsAffected()))
+
+		previousRanks := make(map[int]int)
+		for index, movieId := range currentIds {
+			previousRanks[movieId] = index + 1
+		}
+
+		for index, movieId := range u.MoviesIDs {
+			if previousRanks[movieId] == index+1 {
+				continue
+			}
+
+			event := FavoriteEvent{MovieID: movieId, Type: EventReranked, Rank: index + 1, PreviousRank: previousRanks[movieId]}
+			if eventErr := u.recordEvent(tx, event, "Error when trying to reorder user favorites"); eventErr != nil {
+				return eventErr
+			}
+		}
+
+		return nil
+	})
+}
+
+func (u UserFavorites) RemoveFavorite(db database.DatabaseClient) *rest_errors.RestErr {
+	return withTx(db, "Error when trying to remove user favorite", func(tx database.Transaction) *rest_errors.RestErr {
+		if lockErr := u.lockFavorites(tx, "Error when trying to remove user favorite"); lockErr != nil {
+			return lockErr
+		}
+
+		currentIds, getErr := u.getFavoritesIds(tx)
+		if getErr != nil {
+			return getErr
+		}
+
+		movieRank := 0
+		for index, movieId := range currentIds {
+			if movieId == u.MoviesIDs[0] {
+				movieRank = index + 1
+				break
+			}
+		}
+
+		if movieRank == 0 {
+			return rest_errors.NewNotFoundError("Movie is not in user favorites")
+		}
+
+		result, err := tx.Exec(context.Background(), user_favorites_queries.QueryRemoveUserFavorite, u.UserID, u.MoviesIDs[0])
+		if err != nil {
+			logger.Error("Error when trying to remove user favorite", err)
+			return rest_errors.NewInternalServerError("Error when trying to remove user favorite")
+		}
+
+		logger.Info(fmt.Sprintf("Removed user favorite from the database. Rows affected: %d", result.RowsAffected()))
+
+		_, err = tx.Exec(context.Background(), user_favorites_queries.QueryShiftUserFavoritesRanks, u.UserID, movieRank)
+		if err != nil {
+			logger.Error("Error when trying to shift user favorites ranks", err)
+			return rest_errors.NewInternalServerError("Error when trying to remove user favorite")
+		}
+
+		return u.recordEvent(tx, FavoriteEvent{MovieID: u.MoviesIDs[0], Type: EventRemoved, Rank: movieRank}, "Error when trying to remove user favorite")
+	})
+}
+
+func (u UserFavorites) ReplaceFavorite(rank int, db database.DatabaseClient) *rest_errors.RestErr {
+	return withTx(db, "Error when trying to replace user favorite", func(tx database.Transaction) *rest_errors.RestErr {
+		if lockErr := u.lockFavorites(tx, "Error when trying to replace user favorite"); lockErr != nil {
+			return lockErr
+		}
+
+		currentIds, getErr := u.getFavoritesIds(tx)
+		if getErr != nil {
+			return getErr
+		}
+
+		if rank < 1 || rank > len(currentIds) {
+			return rest_errors.NewNotFoundError(fmt.Sprintf("There is no favorite movie at position %d", rank))
+		}
+
+		for _, movieId := range currentIds {
+			if movieId == u.MoviesIDs[0] {
+				return rest_errors.NewBadRequestError("Movie is already in user favorites")
+			}
+		}
+
+		result, err := tx.Exec(context.Background(), user_favorites_queries.QueryReplaceUserFavorite, u.UserID, rank, u.MoviesIDs[0])
+		if err != nil {
+			logger.Error("Error when trying to replace user favorite", err)
+			return rest_errors.NewInternalServerError("Error when trying to replace user favorite")
+		}
+
+		logger.Info(fmt.Sprintf("Replaced user favorite in the database. Rows affected: %d", result.RowsAffected()))
+
+		// Followers see a replacement as the old movie leaving the list and the new one taking its place
+		if eventErr := u.recordEvent(tx, FavoriteEvent{MovieID: currentIds[rank-1], Type: EventRemoved, Rank: rank}, "Error when trying to replace user favorite"); eventErr != nil {
+			return eventErr
+		}
+
+		return u.recordEvent(tx, FavoriteEvent{MovieID: u.MoviesIDs[0], Type: EventAdded, Rank: rank}, "Error when trying to replace user favorite")
+	})
+}
+
+// GetFeed returns the most recent changes to the favorites lists followed by u.UserID, newest first.
+func (u UserFavorites) GetFeed(limit int, encodedCursor string, db database.DatabaseClient) (*pagination.Page, *rest_errors.RestErr) {
+	var cursor pagination.Cursor
+	hasCursor := encodedCursor != ""
+	if hasCursor {
+		decodedCursor, err := pagination.DecodeCursor(encodedCursor)
+		if err != nil {
+			return nil, rest_errors.NewBadRequestError("Invalid cursor")
+		}
+
+		cursor = decodedCursor
+	}
+
+	var total int64
+	countResult, err := db.QueryRow(context.Background(), user_favorites_queries.QueryCountFavoritesFeed, u.UserID)
+	if err == nil {
+		err = countResult.Scan(&total)
+	}
 	if err != nil {
-		logger.Error("Error when trying to prepare add user favorite statement", err)
-		return rest_errors.NewBadRequestError("Error when trying to add user favorite")
+		logger.Error("Error when trying to count feed events in database", err)
+		return nil, rest_errors.NewInternalServerError("Error when trying to get activity feed")
 	}
 
-	logger.Info(fmt.Sprintf("Saved user in the database. Rows affected: %d", result.RowsAffected()))
+	// One extra row is fetched to know whether there is a next page
+	result, err := db.Query(context.Background(), user_favorites_queries.QueryGetFavoritesFeed, u.UserID, hasCursor, cursor.ID, limit+1)
+	if err != nil {
+		logger.Error("Error when trying to get feed events from database", err)
+		return nil, rest_errors.NewInternalServerError("Error when trying to get activity feed")
+	}
 
-	return nil
+	defer result.Close()
+
+	events := make([]FavoriteEvent, 0)
+	hasNextPage := false
+	for result.Next() {
+		if len(events) == limit {
+			hasNextPage = true
+			break
+		}
+
+		var event FavoriteEvent
+
+		err = result.Scan(&event.ID, &event.UserID, &event.FirstName, &event.LastName, &event.MovieID, &event.Type, &event.Rank, &event.PreviousRank, &event.DateCreated)
+		if err != nil {
+			logger.Error("Error when trying to get feed events from database", err)
+			return nil, rest_errors.NewInternalServerError("Error when trying to get activity feed")
+		}
+
+		events = append(events, event)
+	}
+
+	page := &pagination.Page{
+		Items: events,
+		Total: total,
+		Limit: limit,
+	}
+
+	if hasNextPage {
+		page.NextCursor = pagination.EncodeCursor(pagination.Cursor{
+			ID: events[len(events)-1].ID,
+		})
+	}
+
+	return page, nil
 }
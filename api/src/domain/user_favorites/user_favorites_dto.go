@@ -1,18 +1,74 @@
 package user_favorites
 
 import (
+	"github.com/ericbg27/top10movies-api/src/datasources/cache"
 	"github.com/ericbg27/top10movies-api/src/datasources/database"
+	"github.com/ericbg27/top10movies-api/src/utils/pagination"
 	"github.com/ericbg27/top10movies-api/src/utils/rest_errors"
 	"github.com/ryanbradynd05/go-tmdb"
 )
 
+const (
+	MaxFavorites = 10
+
+	EventAdded    = "added"
+	EventRemoved  = "removed"
+	EventReranked = "reranked"
+)
+
 type UserFavoritesInterface interface {
-	GetFavorites(database.DatabaseClient) (UserFavoritesInterface, map[int]bool, *rest_errors.RestErr)
+	GetFavorites(database.DatabaseClient, cache.CacheClient) (UserFavoritesInterface, map[int]bool, *rest_errors.RestErr)
 	AddFavorite(database.DatabaseClient) *rest_errors.RestErr
+	ReorderFavorites(database.DatabaseClient) *rest_errors.RestErr
+	RemoveFavorite(database.DatabaseClient) *rest_errors.RestErr
+	ReplaceFavorite(int, database.DatabaseClient) *rest_errors.RestErr
+	GetFeed(int, string, database.DatabaseClient) (*pagination.Page, *rest_errors.RestErr)
+}
+
+type FavoriteError struct {
+	MovieID int                  `json:"movie_id"`
+	Error   *rest_errors.RestErr `json:"error"`
 }
 
 type UserFavorites struct {
-	UserID     int64        `json:"user_id"`
-	MoviesIDs  []int        `json:"favorite_movies"`
-	MoviesData []tmdb.Movie `json:"favorite_movies_data"`
+	UserID     int64           `json:"user_id"`
+	MoviesIDs  []int           `json:"favorite_movies"`
+	MoviesData []tmdb.Movie    `json:"favorite_movies_data"`
+	Errors     []FavoriteError `json:"errors,omitempty"`
+}
+
+// FavoriteEvent is a change to a favorites list, shown in the activity feed of the followers of its owner.
+// Rank is where the movie was added, removed from or moved to; PreviousRank is only set for re-rankings.
+type FavoriteEvent struct {
+	ID           int64  `json:"id"`
+	UserID       int64  `json:"user_id"`
+	FirstName    string `json:"first_name"`
+	LastName     string `json:"last_name"`
+	MovieID      int    `json:"movie_id"`
+	Type         string `json:"type"`
+	Rank         int    `json:"rank"`
+	PreviousRank int    `json:"previous_rank,omitempty"`
+	DateCreated  string `json:"date_created"`
+}
+
+func (u UserFavorites) ValidateOrder(currentIds []int) *rest_errors.RestErr {
+	if len(u.MoviesIDs) != len(currentIds) {
+		return rest_errors.NewBadRequestError("New order must contain every favorite movie exactly once")
+	}
+
+	current := make(map[int]bool)
+	for _, movieId := range currentIds {
+		current[movieId] = true
+	}
+
+	seen := make(map[int]bool)
+	for _, movieId := range u.MoviesIDs {
+		if !current[movieId] || seen[movieId] {
+			return rest_errors.NewBadRequestError("New order must contain every favorite movie exactly once")
+		}
+
+		seen[movieId] = true
+	}
+
+	return nil
 }
@@ -0,0 +1,56 @@
+package user_favorites
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateOrderSuccess(t *testing.T) {
+	u := UserFavorites{
+		UserID:    1,
+		MoviesIDs: []int{3, 1, 2},
+	}
+
+	err := u.ValidateOrder([]int{1, 2, 3})
+
+	assert.Nil(t, err)
+}
+
+func TestValidateOrderWrongLength(t *testing.T) {
+	u := UserFavorites{
+		UserID:    1,
+		MoviesIDs: []int{3, 1},
+	}
+
+	err := u.ValidateOrder([]int{1, 2, 3})
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status)
+	assert.EqualValues(t, "New order must contain every favorite movie exactly once", err.Message)
+}
+
+func TestValidateOrderUnknownMovie(t *testing.T) {
+	u := UserFavorites{
+		UserID:    1,
+		MoviesIDs: []int{3, 1, 4},
+	}
+
+	err := u.ValidateOrder([]int{1, 2, 3})
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status)
+}
+
+func TestValidateOrderDuplicatedMovie(t *testing.T) {
+	u := UserFavorites{
+		UserID:    1,
+		MoviesIDs: []int{3, 1, 1},
+	}
+
+	err := u.ValidateOrder([]int{1, 2, 3})
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status)
+}
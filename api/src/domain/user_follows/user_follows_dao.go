@@ -0,0 +1,136 @@
+package user_follows
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ericbg27/top10movies-api/src/datasources/database"
+	"github.com/ericbg27/top10movies-api/src/domain/users"
+	user_follows_queries "github.com/ericbg27/top10movies-api/src/queries/user_follows"
+	"github.com/ericbg27/top10movies-api/src/utils/logger"
+	"github.com/ericbg27/top10movies-api/src/utils/pagination"
+	"github.com/ericbg27/top10movies-api/src/utils/rest_errors"
+)
+
+// Follow is idempotent, following a user twice keeps a single follow. Only active users can be followed.
+func (f UserFollow) Follow(db database.DatabaseClient) *rest_errors.RestErr {
+	if validateErr := f.Validate(); validateErr != nil {
+		return validateErr
+	}
+
+	var followableCount int64
+	countResult, err := db.QueryRow(context.Background(), user_follows_queries.QueryCountFollowableUser, f.FollowedID)
+	if err == nil {
+		err = countResult.Scan(&followableCount)
+	}
+	if err != nil {
+		logger.Error("Error when trying to check followed user in database", err)
+		return rest_errors.NewInternalServerError("Error when trying to follow user")
+	}
+
+	if followableCount == 0 {
+		return rest_errors.NewNotFoundError("User not found")
+	}
+
+	result, err := db.Exec(context.Background(), user_follows_queries.QueryFollowUser, f.FollowerID, f.FollowedID)
+	if err != nil {
+		logger.Error("Error when trying to follow user in database", err)
+		return rest_errors.NewInternalServerError("Error when trying to follow user")
+	}
+
+	logger.Info(fmt.Sprintf("User %d followed user %d. Rows affected: %d", f.FollowerID, f.FollowedID, result.RowsAffected()))
+
+	return nil
+}
+
+func (f UserFollow) Unfollow(db database.DatabaseClient) *rest_errors.RestErr {
+	result, err := db.Exec(context.Background(), user_follows_queries.QueryUnfollowUser, f.FollowerID, f.FollowedID)
+	if err != nil {
+		logger.Error("Error when trying to unfollow user in database", err)
+		return rest_errors.NewInternalServerError("Error when trying to unfollow user")
+	}
+
+	if result.RowsAffected() == 0 {
+		return rest_errors.NewNotFoundError("User is not being followed")
+	}
+
+	logger.Info(fmt.Sprintf("User %d unfollowed user %d", f.FollowerID, f.FollowedID))
+
+	return nil
+}
+
+// listFollows returns a page of the users in a follow list, newest follow first
+func listFollows(db database.DatabaseClient, countQuery string, query string, userID int64, limit int, encodedCursor string) (*pagination.Page, *rest_errors.RestErr) {
+	var cursor pagination.Cursor
+	hasCursor := encodedCursor != ""
+	if hasCursor {
+		decodedCursor, err := pagination.DecodeCursor(encodedCursor)
+		if err != nil {
+			return nil, rest_errors.NewBadRequestError("Invalid cursor")
+		}
+
+		cursor = decodedCursor
+	}
+
+	var total int64
+	countResult, err := db.QueryRow(context.Background(), countQuery, userID)
+	if err == nil {
+		err = countResult.Scan(&total)
+	}
+	if err != nil {
+		logger.Error("Error when trying to count follows in database", err)
+		return nil, rest_errors.NewInternalServerError("Error when trying to list follows")
+	}
+
+	// One extra row is fetched to know whether there is a next page
+	result, err := db.Query(context.Background(), query, userID, hasCursor, cursor.Value, cursor.ID, limit+1)
+	if err != nil {
+		logger.Error("Error when trying to list follows in database", err)
+		return nil, rest_errors.NewInternalServerError("Error when trying to list follows")
+	}
+
+	defer result.Close()
+
+	foundUsers := make([]users.UserInterface, 0)
+	hasNextPage := false
+	var lastFollowDate string
+	for result.Next() {
+		if len(foundUsers) == limit {
+			hasNextPage = true
+			break
+		}
+
+		var followUser users.User
+
+		err = result.Scan(&followUser.ID, &followUser.FirstName, &followUser.LastName, &followUser.FavoritesVisibility, &lastFollowDate)
+		if err != nil {
+			logger.Error("Error when trying to list follows in database", err)
+			return nil, rest_errors.NewInternalServerError("Error when trying to list follows")
+		}
+
+		foundUsers = append(foundUsers, followUser)
+	}
+
+	page := &pagination.Page{
+		Items: foundUsers,
+		Total: total,
+		Limit: limit,
+	}
+
+	if hasNextPage {
+		page.NextCursor = pagination.EncodeCursor(pagination.Cursor{
+			Value: lastFollowDate,
+			ID:    foundUsers[len(foundUsers)-1].(users.User).ID,
+		})
+	}
+
+	return page, nil
+}
+
+func (f UserFollow) GetFollowers(limit int, cursor string, db database.DatabaseClient) (*pagination.Page, *rest_errors.RestErr) {
+	return listFollows(db, user_follows_queries.QueryCountFollowers, user_follows_queries.QueryGetFollowers, f.FollowedID, limit, cursor)
+}
+
+func (f UserFollow) GetFollowing(limit int, cursor string, db database.DatabaseClient) (*pagination.Page, *rest_errors.RestErr) {
+	return listFollows(db, user_follows_queries.QueryCountFollowing, user_follows_queries.QueryGetFollowing, f.FollowerID, limit, cursor)
+}
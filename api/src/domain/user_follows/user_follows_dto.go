@@ -0,0 +1,30 @@
+package user_follows
+
+import (
+	"github.com/ericbg27/top10movies-api/src/datasources/database"
+	"github.com/ericbg27/top10movies-api/src/utils/pagination"
+	"github.com/ericbg27/top10movies-api/src/utils/rest_errors"
+)
+
+type UserFollowInterface interface {
+	Validate() *rest_errors.RestErr
+	Follow(database.DatabaseClient) *rest_errors.RestErr
+	Unfollow(database.DatabaseClient) *rest_errors.RestErr
+	GetFollowers(int, string, database.DatabaseClient) (*pagination.Page, *rest_errors.RestErr)
+	GetFollowing(int, string, database.DatabaseClient) (*pagination.Page, *rest_errors.RestErr)
+}
+
+// UserFollow is FollowerID following FollowedID. GetFollowers lists the followers of FollowedID
+// and GetFollowing the users FollowerID follows.
+type UserFollow struct {
+	FollowerID int64 `json:"follower_id"`
+	FollowedID int64 `json:"followed_id"`
+}
+
+func (f UserFollow) Validate() *rest_errors.RestErr {
+	if f.FollowerID == f.FollowedID {
+		return rest_errors.NewBadRequestError("Users cannot follow themselves")
+	}
+
+	return nil
+}
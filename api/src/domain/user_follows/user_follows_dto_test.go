@@ -0,0 +1,24 @@
+package user_follows
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateSuccess(t *testing.T) {
+	follow := UserFollow{FollowerID: 1, FollowedID: 2}
+
+	assert.Nil(t, follow.Validate())
+}
+
+func TestValidateSelfFollow(t *testing.T) {
+	follow := UserFollow{FollowerID: 1, FollowedID: 1}
+
+	err := follow.Validate()
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "Users cannot follow themselves", err.Message)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status)
+}
@@ -0,0 +1,132 @@
+package user_profiles
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ericbg27/top10movies-api/src/datasources/database"
+	user_profiles_queries "github.com/ericbg27/top10movies-api/src/queries/user_profiles"
+	"github.com/ericbg27/top10movies-api/src/utils/logger"
+	"github.com/ericbg27/top10movies-api/src/utils/rest_errors"
+)
+
+// getProfile runs a profile query through Query so a missing row can be told apart from a
+// failure regardless of the database driver
+func getProfile(db database.Querier, query string, argument interface{}) (UserProfileInterface, *rest_errors.RestErr) {
+	result, err := db.Query(context.Background(), query, argument)
+	if err != nil {
+		logger.Error("Error when trying to get user profile in database", err)
+		return nil, rest_errors.NewInternalServerError("Error when trying to get user profile")
+	}
+
+	defer result.Close()
+
+	if !result.Next() {
+		return nil, rest_errors.NewNotFoundError("Profile not found")
+	}
+
+	var savedProfile UserProfile
+	err = result.Scan(&savedProfile.UserID, &savedProfile.Handle, &savedProfile.DisplayName, &savedProfile.Bio, &savedProfile.AvatarUrl, &savedProfile.FavoriteGenres)
+	if err != nil {
+		logger.Error("Error when trying to get user profile in database", err)
+		return nil, rest_errors.NewInternalServerError("Error when trying to get user profile")
+	}
+
+	return savedProfile, nil
+}
+
+func (p UserProfile) Get(db database.DatabaseClient) (UserProfileInterface, *rest_errors.RestErr) {
+	return getProfile(db, user_profiles_queries.QueryGetUserProfile, p.UserID)
+}
+
+// GetByHandle only finds profiles of active users
+func (p UserProfile) GetByHandle(db database.DatabaseClient) (UserProfileInterface, *rest_errors.RestErr) {
+	return getProfile(db, user_profiles_queries.QueryGetUserProfileByHandle, NormalizeHandle(p.Handle))
+}
+
+func (p UserProfile) checkHandle(tx database.Querier) *rest_errors.RestErr {
+	result, err := tx.Query(context.Background(), user_profiles_queries.QueryGetHandleOwner, p.Handle)
+	if err != nil {
+		logger.Error("Error when trying to check profile handle in database", err)
+		return rest_errors.NewInternalServerError("Error when trying to update user profile")
+	}
+
+	defer result.Close()
+
+	for result.Next() {
+		var ownerID int64
+		if err := result.Scan(&ownerID); err != nil {
+			logger.Error("Error when trying to check profile handle in database", err)
+			return rest_errors.NewInternalServerError("Error when trying to update user profile")
+		}
+
+		if ownerID != p.UserID {
+			return rest_errors.NewBadRequestError("Handle is already taken")
+		}
+	}
+
+	return nil
+}
+
+// Update merges newProfile into p, following the same partial update rules as users: on a partial
+// update only the fields that were sent replace the current ones. The profile is created when the
+// user does not have one yet.
+func (p UserProfile) Update(newProfile UserProfileInterface, isPartial bool, db database.DatabaseClient) (UserProfileInterface, *rest_errors.RestErr) {
+	toUpdateProfile := newProfile.(UserProfile)
+
+	if isPartial {
+		if toUpdateProfile.Handle != "" {
+			p.Handle = toUpdateProfile.Handle
+		}
+		if toUpdateProfile.DisplayName != "" {
+			p.DisplayName = toUpdateProfile.DisplayName
+		}
+		if toUpdateProfile.Bio != "" {
+			p.Bio = toUpdateProfile.Bio
+		}
+		if toUpdateProfile.AvatarUrl != "" {
+			p.AvatarUrl = toUpdateProfile.AvatarUrl
+		}
+		if toUpdateProfile.FavoriteGenres != nil {
+			p.FavoriteGenres = toUpdateProfile.FavoriteGenres
+		}
+	} else {
+		p.Handle = toUpdateProfile.Handle
+		p.DisplayName = toUpdateProfile.DisplayName
+		p.Bio = toUpdateProfile.Bio
+		p.AvatarUrl = toUpdateProfile.AvatarUrl
+		p.FavoriteGenres = toUpdateProfile.FavoriteGenres
+	}
+
+	validatedProfile, validateErr := p.Validate()
+	if validateErr != nil {
+		return nil, validateErr
+	}
+	p = validatedProfile.(UserProfile)
+
+	var restErr *rest_errors.RestErr
+	err := db.WithTx(context.Background(), func(tx database.Transaction) error {
+		if restErr = p.checkHandle(tx); restErr != nil {
+			return restErr
+		}
+
+		result, err := tx.Exec(context.Background(), user_profiles_queries.QuerySaveUserProfile, p.UserID, p.Handle, p.DisplayName, p.Bio, p.AvatarUrl, p.FavoriteGenres)
+		if err != nil {
+			return err
+		}
+
+		logger.Info(fmt.Sprintf("Saved user profile in the database. Rows affected: %d", result.RowsAffected()))
+
+		return nil
+	})
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	if err != nil {
+		logger.Error("Error when trying to save user profile in database", err)
+		return nil, rest_errors.NewInternalServerError("Error when trying to update user profile")
+	}
+
+	return p, nil
+}
@@ -0,0 +1,107 @@
+package user_profiles
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ericbg27/top10movies-api/src/datasources/database"
+	"github.com/ericbg27/top10movies-api/src/domain/user_favorites"
+	"github.com/ericbg27/top10movies-api/src/utils/rest_errors"
+)
+
+const (
+	MaxDisplayNameLength = 50
+	MaxBioLength         = 500
+	MaxAvatarUrlLength   = 2048
+	MaxFavoriteGenres    = 5
+	MaxGenreLength       = 30
+)
+
+type UserProfileInterface interface {
+	Validate() (UserProfileInterface, *rest_errors.RestErr)
+	Get(database.DatabaseClient) (UserProfileInterface, *rest_errors.RestErr)
+	GetByHandle(database.DatabaseClient) (UserProfileInterface, *rest_errors.RestErr)
+	Update(UserProfileInterface, bool, database.DatabaseClient) (UserProfileInterface, *rest_errors.RestErr)
+}
+
+type UserProfile struct {
+	UserID         int64    `json:"user_id"`
+	Handle         string   `json:"handle"`
+	DisplayName    string   `json:"display_name"`
+	Bio            string   `json:"bio"`
+	AvatarUrl      string   `json:"avatar_url"`
+	FavoriteGenres []string `json:"favorite_genres"`
+}
+
+// PublicProfile is the page shown for a handle: the profile and, when visible to the viewer, the ranked top 10
+type PublicProfile struct {
+	UserProfile
+	Favorites *user_favorites.UserFavorites `json:"favorites,omitempty"`
+}
+
+var (
+	handleRegex = regexp.MustCompile(`^[a-z][a-z0-9_]{2,29}$`)
+
+	// reservedHandles would be shadowed by static routes under /users
+	reservedHandles = map[string]bool{
+		"search": true,
+	}
+)
+
+func NormalizeHandle(handle string) string {
+	return strings.ToLower(strings.TrimSpace(handle))
+}
+
+func (p UserProfile) Validate() (UserProfileInterface, *rest_errors.RestErr) {
+	validatedProfile := p
+
+	validatedProfile.Handle = NormalizeHandle(validatedProfile.Handle)
+	if !handleRegex.MatchString(validatedProfile.Handle) || reservedHandles[validatedProfile.Handle] {
+		return nil, rest_errors.NewBadRequestError("Handle must be 3 to 30 characters long, start with a letter and contain only letters, digits and underscores")
+	}
+
+	validatedProfile.DisplayName = strings.TrimSpace(validatedProfile.DisplayName)
+	if utf8.RuneCountInString(validatedProfile.DisplayName) > MaxDisplayNameLength {
+		return nil, rest_errors.NewBadRequestError(fmt.Sprintf("Display name must be at most %d characters long", MaxDisplayNameLength))
+	}
+
+	validatedProfile.Bio = strings.TrimSpace(validatedProfile.Bio)
+	if utf8.RuneCountInString(validatedProfile.Bio) > MaxBioLength {
+		return nil, rest_errors.NewBadRequestError(fmt.Sprintf("Bio must be at most %d characters long", MaxBioLength))
+	}
+
+	validatedProfile.AvatarUrl = strings.TrimSpace(validatedProfile.AvatarUrl)
+	if validatedProfile.AvatarUrl != "" {
+		avatarUrl, err := url.Parse(validatedProfile.AvatarUrl)
+		if err != nil || (avatarUrl.Scheme != "http" && avatarUrl.Scheme != "https") || avatarUrl.Host == "" || len(validatedProfile.AvatarUrl) > MaxAvatarUrlLength {
+			return nil, rest_errors.NewBadRequestError("Avatar URL must be a valid http or https URL")
+		}
+	}
+
+	genres := make([]string, 0, len(validatedProfile.FavoriteGenres))
+	seen := make(map[string]bool)
+	for _, genre := range validatedProfile.FavoriteGenres {
+		genre = strings.TrimSpace(genre)
+		if genre == "" || utf8.RuneCountInString(genre) > MaxGenreLength {
+			return nil, rest_errors.NewBadRequestError(fmt.Sprintf("Favorite genres must be between 1 and %d characters long", MaxGenreLength))
+		}
+
+		if seen[strings.ToLower(genre)] {
+			continue
+		}
+
+		seen[strings.ToLower(genre)] = true
+		genres = append(genres, genre)
+	}
+
+	if len(genres) > MaxFavoriteGenres {
+		return nil, rest_errors.NewBadRequestError(fmt.Sprintf("Profile cannot have more than %d favorite genres", MaxFavoriteGenres))
+	}
+
+	validatedProfile.FavoriteGenres = genres
+
+	return validatedProfile, nil
+}
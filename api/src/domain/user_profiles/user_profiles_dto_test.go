@@ -0,0 +1,68 @@
+package user_profiles
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateSuccess(t *testing.T) {
+	profile := UserProfile{
+		UserID:         1,
+		Handle:         " John_Doe ",
+		DisplayName:    " Johnny ",
+		Bio:            "Horror movies all day",
+		AvatarUrl:      "https://example.com/avatar.png",
+		FavoriteGenres: []string{"Horror", " horror ", "Comedy"},
+	}
+
+	result, err := profile.Validate()
+
+	validatedProfile := result.(UserProfile)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, "john_doe", validatedProfile.Handle)
+	assert.EqualValues(t, "Johnny", validatedProfile.DisplayName)
+	assert.EqualValues(t, []string{"Horror", "Comedy"}, validatedProfile.FavoriteGenres)
+}
+
+func TestValidateHandleError(t *testing.T) {
+	for _, handle := range []string{"", "jo", "1john", "john doe", "search", strings.Repeat("a", 31)} {
+		_, err := UserProfile{Handle: handle}.Validate()
+
+		assert.NotNil(t, err, handle)
+		assert.EqualValues(t, http.StatusBadRequest, err.Status)
+		assert.EqualValues(t, "Handle must be 3 to 30 characters long, start with a letter and contain only letters, digits and underscores", err.Message)
+	}
+}
+
+func TestValidateBioError(t *testing.T) {
+	_, err := UserProfile{Handle: "johndoe", Bio: strings.Repeat("a", MaxBioLength+1)}.Validate()
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status)
+	assert.EqualValues(t, "Bio must be at most 500 characters long", err.Message)
+}
+
+func TestValidateAvatarUrlError(t *testing.T) {
+	for _, avatarUrl := range []string{"not a url", "ftp://example.com/avatar.png", "https://"} {
+		_, err := UserProfile{Handle: "johndoe", AvatarUrl: avatarUrl}.Validate()
+
+		assert.NotNil(t, err, avatarUrl)
+		assert.EqualValues(t, "Avatar URL must be a valid http or https URL", err.Message)
+	}
+}
+
+func TestValidateFavoriteGenresError(t *testing.T) {
+	_, err := UserProfile{Handle: "johndoe", FavoriteGenres: []string{"a", "b", "c", "d", "e", "f"}}.Validate()
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "Profile cannot have more than 5 favorite genres", err.Message)
+
+	_, err = UserProfile{Handle: "johndoe", FavoriteGenres: []string{" "}}.Validate()
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "Favorite genres must be between 1 and 30 characters long", err.Message)
+}
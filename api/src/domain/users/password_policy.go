@@ -0,0 +1,107 @@
+package users
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/ericbg27/top10movies-api/src/utils/config"
+	"github.com/ericbg27/top10movies-api/src/utils/logger"
+	"github.com/ericbg27/top10movies-api/src/utils/rest_errors"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	maxPasswordBytes = 72
+)
+
+var (
+	//go:embed common_passwords.txt
+	commonPasswordsFile string
+
+	commonPasswords = loadCommonPasswords(commonPasswordsFile)
+)
+
+func loadCommonPasswords(content string) map[string]bool {
+	passwords := make(map[string]bool)
+
+	for _, line := range strings.Split(content, "\n") {
+		password := strings.ToLower(strings.TrimSpace(line))
+		if password != "" {
+			passwords[password] = true
+		}
+	}
+
+	return passwords
+}
+
+// ValidatePassword checks a plain text password against the configured password policy
+func ValidatePassword(password string) *rest_errors.RestErr {
+	policy := config.GetConfig().Password
+
+	if strings.TrimSpace(password) == "" {
+		return rest_errors.NewBadRequestError("Invalid password")
+	}
+
+	length := len([]rune(password))
+	if length < policy.MinLength {
+		return rest_errors.NewBadRequestError(fmt.Sprintf("Password must be at least %d characters long", policy.MinLength))
+	}
+
+	// bcrypt ignores everything past 72 bytes, so longer passwords are refused instead of silently truncated
+	if length > policy.MaxLength || len(password) > maxPasswordBytes {
+		return rest_errors.NewBadRequestError(fmt.Sprintf("Password must be at most %d characters long", policy.MaxLength))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, char := range password {
+		switch {
+		case unicode.IsUpper(char):
+			hasUpper = true
+		case unicode.IsLower(char):
+			hasLower = true
+		case unicode.IsDigit(char):
+			hasDigit = true
+		case unicode.IsPunct(char) || unicode.IsSymbol(char):
+			hasSymbol = true
+		}
+	}
+
+	switch {
+	case policy.RequireUpper && !hasUpper:
+		return rest_errors.NewBadRequestError("Password must contain an uppercase letter")
+	case policy.RequireLower && !hasLower:
+		return rest_errors.NewBadRequestError("Password must contain a lowercase letter")
+	case policy.RequireDigit && !hasDigit:
+		return rest_errors.NewBadRequestError("Password must contain a digit")
+	case policy.RequireSymbol && !hasSymbol:
+		return rest_errors.NewBadRequestError("Password must contain a symbol")
+	}
+
+	if commonPasswords[strings.ToLower(password)] {
+		return rest_errors.NewBadRequestError("Password is too common")
+	}
+
+	return nil
+}
+
+func HashPassword(password string) (string, *rest_errors.RestErr) {
+	hashedPass, err := bcrypt.GenerateFromPassword([]byte(password), config.GetConfig().Password.BcryptCost)
+	if err != nil {
+		logger.Error("Unable to hash password", err)
+		return "", rest_errors.NewInternalServerError("Unable to hash password")
+	}
+
+	return string(hashedPass), nil
+}
+
+// NeedsRehash tells whether a stored hash was generated with a lower cost than the configured one
+func NeedsRehash(hashedPassword string) bool {
+	cost, err := bcrypt.Cost([]byte(hashedPassword))
+	if err != nil {
+		return false
+	}
+
+	return cost < config.GetConfig().Password.BcryptCost
+}
@@ -0,0 +1,77 @@
+package users
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/ericbg27/top10movies-api/src/utils/config"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestValidatePasswordSuccess(t *testing.T) {
+	err := ValidatePassword("Sup3rSecretPass")
+
+	assert.Nil(t, err)
+}
+
+func TestValidatePasswordPolicyViolations(t *testing.T) {
+	testCases := map[string]string{
+		"   ":                     "Invalid password",
+		"Sh0rt":                   "Password must be at least 10 characters long",
+		strings.Repeat("Aa1", 22): "Password must be at most 64 characters long",
+		"sup3rsecretpass":         "Password must contain an uppercase letter",
+		"SUP3RSECRETPASS":         "Password must contain a lowercase letter",
+		"SuperSecretPass":         "Password must contain a digit",
+		"Password1234":            "Password is too common",
+	}
+
+	for password, message := range testCases {
+		err := ValidatePassword(password)
+
+		assert.NotNil(t, err, password)
+		assert.EqualValues(t, message, err.Message)
+		assert.EqualValues(t, http.StatusBadRequest, err.Status)
+	}
+}
+
+func TestValidatePasswordRequireSymbol(t *testing.T) {
+	config.GetConfig().Password.RequireSymbol = true
+
+	missingErr := ValidatePassword("Sup3rSecretPass")
+	symbolErr := ValidatePassword("Sup3r$ecretPass")
+
+	config.GetConfig().Password.RequireSymbol = false
+
+	assert.EqualValues(t, "Password must contain a symbol", missingErr.Message)
+	assert.Nil(t, symbolErr)
+}
+
+func TestHashPassword(t *testing.T) {
+	hashedPass, err := HashPassword("Sup3rSecretPass")
+
+	assert.Nil(t, err)
+	assert.Nil(t, bcrypt.CompareHashAndPassword([]byte(hashedPass), []byte("Sup3rSecretPass")))
+
+	cost, costErr := bcrypt.Cost([]byte(hashedPass))
+
+	assert.Nil(t, costErr)
+	assert.EqualValues(t, config.GetConfig().Password.BcryptCost, cost)
+}
+
+func TestNeedsRehash(t *testing.T) {
+	hashedPass, err := HashPassword("Sup3rSecretPass")
+	assert.Nil(t, err)
+
+	assert.False(t, NeedsRehash(hashedPass))
+
+	config.GetConfig().Password.BcryptCost++
+
+	needsRehash := NeedsRehash(hashedPass)
+
+	config.GetConfig().Password.BcryptCost--
+
+	assert.True(t, needsRehash)
+	assert.False(t, NeedsRehash("not a bcrypt hash"))
+}
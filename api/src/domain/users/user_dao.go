@@ -2,14 +2,62 @@ package users
 
 import (
 	"context"
+	"crypto/subtle"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/ericbg27/top10movies-api/src/datasources/cache"
 	"github.com/ericbg27/top10movies-api/src/datasources/database"
+	user_follows_queries "github.com/ericbg27/top10movies-api/src/queries/user_follows"
 	user_queries "github.com/ericbg27/top10movies-api/src/queries/users"
+	"github.com/ericbg27/top10movies-api/src/utils/config"
 	"github.com/ericbg27/top10movies-api/src/utils/logger"
+	"github.com/ericbg27/top10movies-api/src/utils/pagination"
 	"github.com/ericbg27/top10movies-api/src/utils/rest_errors"
+	"github.com/gofrs/uuid"
 )
 
+const (
+	verificationKeyPrefix  = "email_verification:"
+	passwordResetKeyPrefix = "password_reset:"
+)
+
+var (
+	searchQueries = map[string]string{
+		SortByName:            user_queries.QuerySearchUsersByName,
+		SortByNameDesc:        user_queries.QuerySearchUsersByNameDesc,
+		SortByDateCreated:     user_queries.QuerySearchUsersByDate,
+		SortByDateCreatedDesc: user_queries.QuerySearchUsersByDateDesc,
+	}
+
+	// likeEscaper makes LIKE wildcards in the search term match literally
+	likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+)
+
+func verificationKey(token string) string {
+	return verificationKeyPrefix + token
+}
+
+func passwordResetKey(token string) string {
+	return passwordResetKeyPrefix + token
+}
+
+// storeEmailToken generates a random token and maps it to the given email in the cache
+func storeEmailToken(email string, key func(string) string, ttl time.Duration, cacheClient cache.CacheClient) (string, error) {
+	token, err := uuid.NewV4()
+	if err != nil {
+		return "", err
+	}
+
+	if err := cacheClient.Set(key(token.String()), email, ttl); err != nil {
+		return "", err
+	}
+
+	return token.String(), nil
+}
+
 func (user User) Get(db database.DatabaseClient) (UserInterface, *rest_errors.RestErr) {
 	savedUser := user
 
@@ -19,7 +67,10 @@ func (user User) Get(db database.DatabaseClient) (UserInterface, *rest_errors.Re
 		return nil, rest_errors.NewInternalServerError("Error when trying to get user")
 	}
 
-	err = result.Scan(&savedUser.ID, &savedUser.FirstName, &savedUser.LastName, &savedUser.Email, &savedUser.Status, &savedUser.Password)
+	err = result.Scan(&savedUser.ID, &savedUser.FirstName, &savedUser.LastName, &savedUser.Email, &savedUser.Status, &savedUser.Password, &savedUser.Role, &savedUser.FavoritesVisibility, &savedUser.FavoritesShareToken)
+	if errors.Is(err, database.ErrNoRows) {
+		return nil, rest_errors.NewNotFoundError("User not found")
+	}
 	if err != nil {
 		logger.Error("Error when trying to get user in database", err)
 		return nil, rest_errors.NewInternalServerError("Error when trying to get user")
@@ -37,7 +88,10 @@ func (user User) GetById(db database.DatabaseClient) (UserInterface, *rest_error
 		return nil, rest_errors.NewInternalServerError("Error when trying to get user")
 	}
 
-	err = result.Scan(&savedUser.ID, &savedUser.FirstName, &savedUser.LastName, &savedUser.Email, &savedUser.Status, &savedUser.Password)
+	err = result.Scan(&savedUser.ID, &savedUser.FirstName, &savedUser.LastName, &savedUser.Email, &savedUser.Status, &savedUser.Password, &savedUser.Role, &savedUser.FavoritesVisibility, &savedUser.FavoritesShareToken)
+	if errors.Is(err, database.ErrNoRows) {
+		return nil, rest_errors.NewNotFoundError("User not found")
+	}
 	if err != nil {
 		logger.Error("Error when trying to get user by id in database", err)
 		return nil, rest_errors.NewInternalServerError("Error when trying to get user")
@@ -47,7 +101,7 @@ func (user User) GetById(db database.DatabaseClient) (UserInterface, *rest_error
 }
 
 func (user User) Save(db database.DatabaseClient) *rest_errors.RestErr {
-	result, err := db.Exec(context.Background(), user_queries.QueryInsertUser, user.FirstName, user.LastName, user.Email, user.DateCreated, user.Status, user.Password)
+	result, err := db.Exec(context.Background(), user_queries.QueryInsertUser, user.FirstName, user.LastName, user.Email, user.DateCreated, user.Status, user.Password, user.Role)
 	if err != nil {
 		logger.Error("Error when trying to save user in database", err)
 		return rest_errors.NewInternalServerError("Error when trying to save user")
@@ -95,38 +149,353 @@ func (user User) Update(newUser UserInterface, isPartial bool, db database.Datab
 }
 
 func (user User) Delete(db database.DatabaseClient) *rest_errors.RestErr {
-	result, err := db.Exec(context.Background(), user_queries.QueryDeleteUser, user.ID)
-	if err != nil {
-		logger.Error("Error when trying to delete user in database", err)
-		return rest_errors.NewInternalServerError("Error when trying to delete user")
-	}
+	return user.UpdateStatus(StatusDeleted, db)
+}
 
-	logger.Info(fmt.Sprintf("Deleted user in the database. Rows affected: %d", result.RowsAffected()))
+func searchSortValue(user User, sort string) string {
+	if sort == SortByDateCreated || sort == SortByDateCreatedDesc {
+		return user.DateCreated
+	}
 
-	return nil
+	return strings.ToLower(user.FirstName + " " + user.LastName)
 }
 
-func (user User) Search(db database.DatabaseClient) ([]UserInterface, *rest_errors.RestErr) {
-	result, err := db.Query(context.Background(), user_queries.QuerySearchUser, user.FirstName, user.LastName)
+func (user User) Search(criteria SearchCriteria, db database.DatabaseClient) (*pagination.Page, *rest_errors.RestErr) {
+	searchQuery, ok := searchQueries[criteria.Sort]
+	if !ok {
+		return nil, rest_errors.NewBadRequestError(fmt.Sprintf("Sort should be one of: %s", strings.Join(SortOptions, ", ")))
+	}
+
+	var cursor pagination.Cursor
+	hasCursor := criteria.Cursor != ""
+	if hasCursor {
+		decodedCursor, err := pagination.DecodeCursor(criteria.Cursor)
+		if err != nil || decodedCursor.Sort != criteria.Sort {
+			return nil, rest_errors.NewBadRequestError("Invalid cursor")
+		}
+
+		cursor = decodedCursor
+	}
+
+	term := likeEscaper.Replace(strings.ToLower(strings.TrimSpace(criteria.Query)))
+
+	var total int64
+	countResult, err := db.QueryRow(context.Background(), user_queries.QueryCountSearchUsers, term, criteria.IncludeEmail)
+	if err == nil {
+		err = countResult.Scan(&total)
+	}
+	if err != nil {
+		logger.Error("Error when trying to count searched users in database", err)
+		return nil, rest_errors.NewInternalServerError("Error when trying to search user")
+	}
+
+	// One extra row is fetched to know whether there is a next page
+	result, err := db.Query(context.Background(), searchQuery, term, criteria.IncludeEmail, hasCursor, cursor.Value, cursor.ID, criteria.Limit+1)
 	if err != nil {
 		logger.Error("Error when trying to search user in database", err)
 		return nil, rest_errors.NewInternalServerError("Error when trying to search user")
 	}
 
-	var foundUsers []UserInterface
+	defer result.Close()
+
+	foundUsers := make([]UserInterface, 0)
+	hasNextPage := false
+	var lastUser User
 	for result.Next() {
+		if len(foundUsers) == criteria.Limit {
+			hasNextPage = true
+			break
+		}
+
 		var searchedUser User
 
-		err = result.Scan(&searchedUser.ID, &searchedUser.FirstName, &searchedUser.LastName, &searchedUser.Email, &searchedUser.Status, &searchedUser.Password)
+		err = result.Scan(&searchedUser.ID, &searchedUser.FirstName, &searchedUser.LastName, &searchedUser.Email, &searchedUser.Status, &searchedUser.Role, &searchedUser.FavoritesVisibility, &searchedUser.DateCreated)
 		if err != nil {
 			logger.Error("Error when trying to search user in database", err)
 			return nil, rest_errors.NewInternalServerError("Error when trying to search user")
 		}
 
-		searchedUser.Password = ""
-
 		foundUsers = append(foundUsers, searchedUser)
+		lastUser = searchedUser
+	}
+
+	page := &pagination.Page{
+		Items: foundUsers,
+		Total: total,
+		Limit: criteria.Limit,
+	}
+
+	if hasNextPage {
+		page.NextCursor = pagination.EncodeCursor(pagination.Cursor{
+			Sort:  criteria.Sort,
+			Value: searchSortValue(lastUser, criteria.Sort),
+			ID:    lastUser.ID,
+		})
+	}
+
+	return page, nil
+}
+
+func (user User) List(limit int, offset int, db database.DatabaseClient) ([]UserInterface, *rest_errors.RestErr) {
+	result, err := db.Query(context.Background(), user_queries.QueryListUsers, limit, offset)
+	if err != nil {
+		logger.Error("Error when trying to list users in database", err)
+		return nil, rest_errors.NewInternalServerError("Error when trying to list users")
+	}
+
+	defer result.Close()
+
+	foundUsers := make([]UserInterface, 0)
+	for result.Next() {
+		var listedUser User
+
+		err = result.Scan(&listedUser.ID, &listedUser.FirstName, &listedUser.LastName, &listedUser.Email, &listedUser.Status, &listedUser.Role, &listedUser.FavoritesVisibility)
+		if err != nil {
+			logger.Error("Error when trying to list users in database", err)
+			return nil, rest_errors.NewInternalServerError("Error when trying to list users")
+		}
+
+		foundUsers = append(foundUsers, listedUser)
 	}
 
 	return foundUsers, nil
 }
+
+// UpdateStatus moves user from user.Status to status. The update only applies while the stored
+// status is still user.Status, so concurrent changes get a conflict instead of skipping the
+// transition rules.
+func (user User) UpdateStatus(status string, db database.DatabaseClient) *rest_errors.RestErr {
+	if !CanTransitionStatus(user.Status, status) {
+		return rest_errors.NewBadRequestError(fmt.Sprintf("Cannot change user status from %s to %s", user.Status, status))
+	}
+
+	var deletedAt interface{}
+	if status == StatusDeleted {
+		deletedAt = time.Now().UTC()
+	}
+
+	result, err := db.Exec(context.Background(), user_queries.QueryUpdateUserStatus, user.ID, status, deletedAt, user.Status)
+	if err != nil {
+		logger.Error("Error when trying to update user status in database", err)
+		return rest_errors.NewInternalServerError("Error when trying to update user status")
+	}
+
+	if result.RowsAffected() == 0 {
+		return rest_errors.NewConflictError("User status was changed by another request")
+	}
+
+	logger.Info(fmt.Sprintf("Updated user %d status from %s to %s", user.ID, user.Status, status))
+
+	return nil
+}
+
+func (user User) UpdateRole(db database.DatabaseClient) *rest_errors.RestErr {
+	result, err := db.Exec(context.Background(), user_queries.QueryUpdateUserRole, user.ID, user.Role)
+	if err != nil {
+		logger.Error("Error when trying to update user role in database", err)
+		return rest_errors.NewInternalServerError("Error when trying to update user role")
+	}
+
+	if result.RowsAffected() == 0 {
+		return rest_errors.NewNotFoundError("User not found")
+	}
+
+	logger.Info(fmt.Sprintf("Updated user %d role to %s", user.ID, user.Role))
+
+	return nil
+}
+
+// CanViewFavorites tells whether viewerID, 0 for anonymous requests, may see the favorites of user.
+// Unlisted lists are only shown to whoever has the share token and followers-only lists to the
+// users following user. Follows need no approval, so followers-only lists are not private.
+func (user User) CanViewFavorites(viewerID int64, shareToken string, db database.DatabaseClient) (bool, *rest_errors.RestErr) {
+	if viewerID != 0 && viewerID == user.ID {
+		return true, nil
+	}
+
+	if user.Status != StatusActive {
+		return false, nil
+	}
+
+	switch user.FavoritesVisibility {
+	case FavoritesPublic:
+		return true, nil
+	case FavoritesUnlisted:
+		return user.FavoritesShareToken != "" && subtle.ConstantTimeCompare([]byte(shareToken), []byte(user.FavoritesShareToken)) == 1, nil
+	case FavoritesFollowers:
+		if viewerID == 0 {
+			return false, nil
+		}
+
+		return user.isFollowedBy(viewerID, db)
+	default:
+		return false, nil
+	}
+}
+
+func (user User) isFollowedBy(followerID int64, db database.DatabaseClient) (bool, *rest_errors.RestErr) {
+	var followCount int64
+	result, err := db.QueryRow(context.Background(), user_follows_queries.QueryIsFollowing, followerID, user.ID)
+	if err == nil {
+		err = result.Scan(&followCount)
+	}
+	if err != nil {
+		logger.Error("Error when trying to check user follow in database", err)
+		return false, rest_errors.NewInternalServerError("Error when trying to check follow")
+	}
+
+	return followCount > 0, nil
+}
+
+// UpdateFavoritesVisibility stores user.FavoritesVisibility. Making a list unlisted always issues a new
+// share token, so it also revokes links shared before; any other visibility drops the token.
+func (user User) UpdateFavoritesVisibility(db database.DatabaseClient) (UserInterface, *rest_errors.RestErr) {
+	user.FavoritesShareToken = ""
+	if user.FavoritesVisibility == FavoritesUnlisted {
+		token, err := uuid.NewV4()
+		if err != nil {
+			logger.Error("Error when trying to generate favorites share token", err)
+			return nil, rest_errors.NewInternalServerError("Error when trying to update favorites visibility")
+		}
+
+		user.FavoritesShareToken = token.String()
+	}
+
+	result, err := db.Exec(context.Background(), user_queries.QueryUpdateFavoritesVisibility, user.ID, user.FavoritesVisibility, user.FavoritesShareToken)
+	if err != nil {
+		logger.Error("Error when trying to update favorites visibility in database", err)
+		return nil, rest_errors.NewInternalServerError("Error when trying to update favorites visibility")
+	}
+
+	if result.RowsAffected() == 0 {
+		return nil, rest_errors.NewNotFoundError("User not found")
+	}
+
+	logger.Info(fmt.Sprintf("Updated user %d favorites visibility to %s", user.ID, user.FavoritesVisibility))
+
+	return user, nil
+}
+
+func (user User) UpdatePassword(db database.DatabaseClient) *rest_errors.RestErr {
+	result, err := db.Exec(context.Background(), user_queries.QueryUpdateUserPassword, user.ID, user.Password)
+	if err != nil {
+		logger.Error("Error when trying to update user password in database", err)
+		return rest_errors.NewInternalServerError("Error when trying to update user password")
+	}
+
+	if result.RowsAffected() == 0 {
+		return rest_errors.NewNotFoundError("User not found")
+	}
+
+	logger.Info(fmt.Sprintf("Updated user %d password", user.ID))
+
+	return nil
+}
+
+func (user User) PurgeDeleted(before time.Time, db database.DatabaseClient) (int64, *rest_errors.RestErr) {
+	result, err := db.Exec(context.Background(), user_queries.QueryPurgeDeletedUsers, before.UTC())
+	if err != nil {
+		logger.Error("Error when trying to purge deleted users in database", err)
+		return 0, rest_errors.NewInternalServerError("Error when trying to purge deleted users")
+	}
+
+	return result.RowsAffected(), nil
+}
+
+func (user User) CreateVerificationToken(cacheClient cache.CacheClient) (string, *rest_errors.RestErr) {
+	token, err := storeEmailToken(user.Email, verificationKey, config.GetConfig().Users.VerificationTokenTtl, cacheClient)
+	if err != nil {
+		logger.Error("Error when trying to save verification token in cache", err)
+		return "", rest_errors.NewInternalServerError("Error when trying to create verification token")
+	}
+
+	return token, nil
+}
+
+func (user User) Verify(token string, db database.DatabaseClient, cacheClient cache.CacheClient) *rest_errors.RestErr {
+	email, err := cacheClient.Get(verificationKey(token))
+	if errors.Is(err, cache.ErrCacheMiss) {
+		return rest_errors.NewBadRequestError("Invalid or expired verification token")
+	}
+
+	if err != nil {
+		logger.Error("Error when trying to get verification token from cache", err)
+		return rest_errors.NewInternalServerError("Error when trying to verify email")
+	}
+
+	user.Email = email
+
+	result, getErr := user.Get(db)
+	if getErr != nil {
+		return getErr
+	}
+
+	// Verification only ever activates pending accounts, it must not lift a suspension or a deletion
+	savedUser := result.(User)
+	switch savedUser.Status {
+	case StatusPending:
+		if updateErr := savedUser.UpdateStatus(StatusActive, db); updateErr != nil {
+			return updateErr
+		}
+	case StatusActive:
+	default:
+		return rest_errors.NewBadRequestError("Invalid or expired verification token")
+	}
+
+	if _, err := cacheClient.Del(verificationKey(token)); err != nil {
+		logger.Error("Error when trying to delete verification token from cache", err)
+	}
+
+	return nil
+}
+
+func (user User) CreatePasswordResetToken(cacheClient cache.CacheClient) (string, *rest_errors.RestErr) {
+	token, err := storeEmailToken(user.Email, passwordResetKey, config.GetConfig().Users.PasswordResetTokenTtl, cacheClient)
+	if err != nil {
+		logger.Error("Error when trying to save password reset token in cache", err)
+		return "", rest_errors.NewInternalServerError("Error when trying to create password reset token")
+	}
+
+	return token, nil
+}
+
+// ResetPassword consumes the reset token and stores user.Password, which must already be hashed,
+// as the new password of the account the token was issued for
+func (user User) ResetPassword(token string, db database.DatabaseClient, cacheClient cache.CacheClient) (UserInterface, *rest_errors.RestErr) {
+	email, err := cacheClient.Get(passwordResetKey(token))
+	if errors.Is(err, cache.ErrCacheMiss) {
+		return nil, rest_errors.NewBadRequestError("Invalid or expired password reset token")
+	}
+
+	if err != nil {
+		logger.Error("Error when trying to get password reset token from cache", err)
+		return nil, rest_errors.NewInternalServerError("Error when trying to reset password")
+	}
+
+	// Deleting before using the token keeps it single-use even under concurrent requests
+	deleted, err := cacheClient.Del(passwordResetKey(token))
+	if err != nil {
+		logger.Error("Error when trying to delete password reset token from cache", err)
+		return nil, rest_errors.NewInternalServerError("Error when trying to reset password")
+	}
+
+	if deleted == 0 {
+		return nil, rest_errors.NewBadRequestError("Invalid or expired password reset token")
+	}
+
+	user.Email = email
+
+	result, getErr := user.Get(db)
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	savedUser := result.(User)
+	savedUser.Password = user.Password
+
+	if updateErr := savedUser.UpdatePassword(db); updateErr != nil {
+		return nil, updateErr
+	}
+
+	return savedUser, nil
+}
@@ -3,9 +3,12 @@ package users
 import (
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/ericbg27/top10movies-api/src/datasources/database"
+	cache_mock "github.com/ericbg27/top10movies-api/src/mocks/cache"
 	database_mock "github.com/ericbg27/top10movies-api/src/mocks/database"
+	"github.com/ericbg27/top10movies-api/src/utils/pagination"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -26,6 +29,7 @@ func TestGetSuccess(t *testing.T) {
 	assert.EqualValues(t, "Doe", fetchedUser.LastName)
 	assert.EqualValues(t, "johndoe@gmail.com", fetchedUser.Email)
 	assert.EqualValues(t, "1234", fetchedUser.Password)
+	assert.EqualValues(t, "user", fetchedUser.Role)
 }
 
 func TestGetQueryRowError(t *testing.T) {
@@ -58,6 +62,23 @@ func TestGetScanError(t *testing.T) {
 	assert.EqualValues(t, "internal_server_error", err.Err)
 }
 
+func TestGetNotFound(t *testing.T) {
+	user := User{
+		Email: "unknown@gmail.com",
+	}
+
+	db.(*database_mock.DatabaseClientMock).NoRows = true
+
+	result, err := user.Get(db)
+
+	db.(*database_mock.DatabaseClientMock).NoRows = false
+
+	assert.Nil(t, result)
+	assert.EqualValues(t, "User not found", err.Message)
+	assert.EqualValues(t, http.StatusNotFound, err.Status)
+	assert.EqualValues(t, "not_found", err.Err)
+}
+
 func TestGetByIdSuccess(t *testing.T) {
 	var user User
 
@@ -88,6 +109,23 @@ func TestGetByIdQueryRowError(t *testing.T) {
 	assert.EqualValues(t, "internal_server_error", err.Err)
 }
 
+func TestGetByIdNotFound(t *testing.T) {
+	user := User{
+		ID: 99,
+	}
+
+	db.(*database_mock.DatabaseClientMock).NoRows = true
+
+	result, err := user.GetById(db)
+
+	db.(*database_mock.DatabaseClientMock).NoRows = false
+
+	assert.Nil(t, result)
+	assert.EqualValues(t, "User not found", err.Message)
+	assert.EqualValues(t, http.StatusNotFound, err.Status)
+	assert.EqualValues(t, "not_found", err.Err)
+}
+
 func TestGetByIdScanError(t *testing.T) {
 	var user User
 
@@ -312,7 +350,10 @@ func TestUpdateExecError(t *testing.T) {
 }
 
 func TestDeleteSuccess(t *testing.T) {
-	var user User
+	user := User{
+		ID:     1,
+		Status: StatusActive,
+	}
 
 	err := user.Delete(db)
 
@@ -320,7 +361,10 @@ func TestDeleteSuccess(t *testing.T) {
 }
 
 func TestDeleteExecError(t *testing.T) {
-	var user User
+	user := User{
+		ID:     1,
+		Status: StatusActive,
+	}
 
 	db.(*database_mock.DatabaseClientMock).CanExec = false
 
@@ -328,28 +372,45 @@ func TestDeleteExecError(t *testing.T) {
 
 	db.(*database_mock.DatabaseClientMock).CanExec = true
 
-	assert.EqualValues(t, "Error when trying to delete user", err.Message)
+	assert.EqualValues(t, "Error when trying to update user status", err.Message)
 	assert.EqualValues(t, http.StatusInternalServerError, err.Status)
 	assert.EqualValues(t, "internal_server_error", err.Err)
 }
 
+func TestDeleteAlreadyDeleted(t *testing.T) {
+	user := User{
+		ID:     1,
+		Status: StatusDeleted,
+	}
+
+	err := user.Delete(db)
+
+	assert.EqualValues(t, "Cannot change user status from deleted to deleted", err.Message)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status)
+	assert.EqualValues(t, "bad_request", err.Err)
+}
+
 func TestSearchSuccess(t *testing.T) {
 	var user User
 
-	results, err := user.Search(db)
+	page, err := user.Search(SearchCriteria{Query: "jo", Sort: SortByName, Limit: 10}, db)
 
 	var usersFetched []User
 
-	for _, result := range results {
+	for _, result := range page.Items.([]UserInterface) {
 		usersFetched = append(usersFetched, result.(User))
 	}
 
 	assert.Nil(t, err)
+	assert.EqualValues(t, 1, page.Total)
+	assert.EqualValues(t, 10, page.Limit)
+	assert.Empty(t, page.NextCursor)
 	assert.EqualValues(t, 2, len(usersFetched))
 	assert.EqualValues(t, int64(1), usersFetched[0].ID)
 	assert.EqualValues(t, "John", usersFetched[0].FirstName)
 	assert.EqualValues(t, "Doe", usersFetched[0].LastName)
 	assert.EqualValues(t, "johndoe@gmail.com", usersFetched[0].Email)
+	assert.EqualValues(t, "2021-01-01", usersFetched[0].DateCreated)
 	assert.EqualValues(t, "", usersFetched[0].Password)
 	assert.EqualValues(t, int64(2), usersFetched[1].ID)
 	assert.EqualValues(t, "Josh", usersFetched[1].FirstName)
@@ -358,12 +419,41 @@ func TestSearchSuccess(t *testing.T) {
 	assert.EqualValues(t, "", usersFetched[1].Password)
 }
 
+func TestSearchNextCursor(t *testing.T) {
+	var user User
+
+	page, err := user.Search(SearchCriteria{Sort: SortByDateCreated, Limit: 1}, db)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, len(page.Items.([]UserInterface)))
+
+	cursor, cursorErr := pagination.DecodeCursor(page.NextCursor)
+
+	assert.Nil(t, cursorErr)
+	assert.EqualValues(t, SortByDateCreated, cursor.Sort)
+	assert.EqualValues(t, "2021-01-01", cursor.Value)
+	assert.EqualValues(t, int64(1), cursor.ID)
+}
+
+func TestSearchInvalidCursor(t *testing.T) {
+	var user User
+
+	cursor := pagination.EncodeCursor(pagination.Cursor{Sort: SortByDateCreated, Value: "2021-01-01", ID: 1})
+
+	result, err := user.Search(SearchCriteria{Sort: SortByName, Limit: 10, Cursor: cursor}, db)
+
+	assert.Nil(t, result)
+	assert.EqualValues(t, "Invalid cursor", err.Message)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status)
+	assert.EqualValues(t, "bad_request", err.Err)
+}
+
 func TestSearchQueryError(t *testing.T) {
 	var user User
 
 	db.(*database_mock.DatabaseClientMock).CanQuery = false
 
-	result, err := user.Search(db)
+	result, err := user.Search(SearchCriteria{Sort: SortByName, Limit: 10}, db)
 
 	db.(*database_mock.DatabaseClientMock).CanQuery = true
 
@@ -372,3 +462,355 @@ func TestSearchQueryError(t *testing.T) {
 	assert.EqualValues(t, http.StatusInternalServerError, err.Status)
 	assert.EqualValues(t, "internal_server_error", err.Err)
 }
+
+func TestListSuccess(t *testing.T) {
+	var user User
+
+	results, err := user.List(10, 0, db)
+
+	var usersFetched []User
+
+	for _, result := range results {
+		usersFetched = append(usersFetched, result.(User))
+	}
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, 2, len(usersFetched))
+	assert.EqualValues(t, int64(1), usersFetched[0].ID)
+	assert.EqualValues(t, "", usersFetched[0].Password)
+	assert.EqualValues(t, int64(2), usersFetched[1].ID)
+	assert.EqualValues(t, "", usersFetched[1].Password)
+	assert.EqualValues(t, "admin", usersFetched[1].Role)
+}
+
+func TestListQueryError(t *testing.T) {
+	var user User
+
+	db.(*database_mock.DatabaseClientMock).CanQuery = false
+
+	result, err := user.List(10, 0, db)
+
+	db.(*database_mock.DatabaseClientMock).CanQuery = true
+
+	assert.Nil(t, result)
+	assert.EqualValues(t, "Error when trying to list users", err.Message)
+	assert.EqualValues(t, http.StatusInternalServerError, err.Status)
+	assert.EqualValues(t, "internal_server_error", err.Err)
+}
+
+func TestUpdateStatusSuccess(t *testing.T) {
+	user := User{
+		ID:     1,
+		Status: StatusActive,
+	}
+
+	err := user.UpdateStatus(StatusSuspended, db)
+
+	assert.Nil(t, err)
+}
+
+func TestUpdateStatusExecError(t *testing.T) {
+	user := User{
+		ID:     1,
+		Status: StatusActive,
+	}
+
+	db.(*database_mock.DatabaseClientMock).CanExec = false
+
+	err := user.UpdateStatus(StatusSuspended, db)
+
+	db.(*database_mock.DatabaseClientMock).CanExec = true
+
+	assert.EqualValues(t, "Error when trying to update user status", err.Message)
+	assert.EqualValues(t, http.StatusInternalServerError, err.Status)
+	assert.EqualValues(t, "internal_server_error", err.Err)
+}
+
+func TestUpdateStatusInvalidTransition(t *testing.T) {
+	user := User{
+		ID:     1,
+		Status: StatusDeleted,
+	}
+
+	err := user.UpdateStatus(StatusSuspended, db)
+
+	assert.EqualValues(t, "Cannot change user status from deleted to suspended", err.Message)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status)
+}
+
+func TestUpdateRoleSuccess(t *testing.T) {
+	user := User{
+		ID:   1,
+		Role: RoleModerator,
+	}
+
+	err := user.UpdateRole(db)
+
+	assert.Nil(t, err)
+}
+
+func TestUpdateRoleExecError(t *testing.T) {
+	user := User{
+		ID:   1,
+		Role: RoleModerator,
+	}
+
+	db.(*database_mock.DatabaseClientMock).CanExec = false
+
+	err := user.UpdateRole(db)
+
+	db.(*database_mock.DatabaseClientMock).CanExec = true
+
+	assert.EqualValues(t, "Error when trying to update user role", err.Message)
+	assert.EqualValues(t, http.StatusInternalServerError, err.Status)
+	assert.EqualValues(t, "internal_server_error", err.Err)
+}
+
+func TestPurgeDeletedSuccess(t *testing.T) {
+	var user User
+
+	purged, err := user.PurgeDeleted(time.Now(), db)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, purged)
+}
+
+func TestPurgeDeletedExecError(t *testing.T) {
+	var user User
+
+	db.(*database_mock.DatabaseClientMock).CanExec = false
+
+	purged, err := user.PurgeDeleted(time.Now(), db)
+
+	db.(*database_mock.DatabaseClientMock).CanExec = true
+
+	assert.EqualValues(t, 0, purged)
+	assert.EqualValues(t, "Error when trying to purge deleted users", err.Message)
+	assert.EqualValues(t, http.StatusInternalServerError, err.Status)
+}
+
+func TestCreateVerificationTokenSuccess(t *testing.T) {
+	cacheClient := &cache_mock.CacheClientMock{CanSet: true}
+	cacheClient.SetupCacheConnection()
+
+	user := User{Email: "johndoe@gmail.com"}
+
+	token, err := user.CreateVerificationToken(cacheClient)
+
+	assert.Nil(t, err)
+	assert.NotEmpty(t, token)
+	assert.EqualValues(t, "johndoe@gmail.com", cacheClient.Values[verificationKey(token)])
+}
+
+func TestCreateVerificationTokenCacheError(t *testing.T) {
+	cacheClient := &cache_mock.CacheClientMock{CanSet: false}
+	cacheClient.SetupCacheConnection()
+
+	user := User{Email: "johndoe@gmail.com"}
+
+	token, err := user.CreateVerificationToken(cacheClient)
+
+	assert.Empty(t, token)
+	assert.EqualValues(t, "Error when trying to create verification token", err.Message)
+	assert.EqualValues(t, http.StatusInternalServerError, err.Status)
+}
+
+func TestVerifySuccess(t *testing.T) {
+	cacheClient := &cache_mock.CacheClientMock{CanGet: true, CanDel: true}
+	cacheClient.SetupCacheConnection()
+	cacheClient.Values[verificationKey("token")] = "johndoe@gmail.com"
+
+	var user User
+
+	err := user.Verify("token", db, cacheClient)
+
+	assert.Nil(t, err)
+	assert.NotContains(t, cacheClient.Values, verificationKey("token"))
+}
+
+func TestVerifyInvalidToken(t *testing.T) {
+	cacheClient := &cache_mock.CacheClientMock{CanGet: true, CanDel: true}
+	cacheClient.SetupCacheConnection()
+
+	var user User
+
+	err := user.Verify("token", db, cacheClient)
+
+	assert.EqualValues(t, "Invalid or expired verification token", err.Message)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status)
+}
+
+func TestVerifyCacheError(t *testing.T) {
+	cacheClient := &cache_mock.CacheClientMock{CanGet: false}
+	cacheClient.SetupCacheConnection()
+
+	var user User
+
+	err := user.Verify("token", db, cacheClient)
+
+	assert.EqualValues(t, "Error when trying to verify email", err.Message)
+	assert.EqualValues(t, http.StatusInternalServerError, err.Status)
+}
+
+func TestUpdatePasswordSuccess(t *testing.T) {
+	user := User{
+		ID:       1,
+		Password: "hashed",
+	}
+
+	err := user.UpdatePassword(db)
+
+	assert.Nil(t, err)
+}
+
+func TestUpdatePasswordExecError(t *testing.T) {
+	user := User{
+		ID:       1,
+		Password: "hashed",
+	}
+
+	db.(*database_mock.DatabaseClientMock).CanExec = false
+
+	err := user.UpdatePassword(db)
+
+	db.(*database_mock.DatabaseClientMock).CanExec = true
+
+	assert.EqualValues(t, "Error when trying to update user password", err.Message)
+	assert.EqualValues(t, http.StatusInternalServerError, err.Status)
+}
+
+func TestResetPasswordSuccess(t *testing.T) {
+	cacheClient := &cache_mock.CacheClientMock{CanGet: true, CanSet: true, CanDel: true}
+	cacheClient.SetupCacheConnection()
+
+	user := User{Email: "johndoe@gmail.com"}
+
+	token, createErr := user.CreatePasswordResetToken(cacheClient)
+	assert.Nil(t, createErr)
+
+	result, err := User{Password: "hashed"}.ResetPassword(token, db, cacheClient)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, "hashed", result.(User).Password)
+	assert.NotContains(t, cacheClient.Values, passwordResetKey(token))
+
+	_, reuseErr := User{Password: "hashed"}.ResetPassword(token, db, cacheClient)
+
+	assert.EqualValues(t, "Invalid or expired password reset token", reuseErr.Message)
+	assert.EqualValues(t, http.StatusBadRequest, reuseErr.Status)
+}
+
+func TestResetPasswordCacheError(t *testing.T) {
+	cacheClient := &cache_mock.CacheClientMock{CanGet: false}
+	cacheClient.SetupCacheConnection()
+
+	result, err := User{Password: "hashed"}.ResetPassword("token", db, cacheClient)
+
+	assert.Nil(t, result)
+	assert.EqualValues(t, "Error when trying to reset password", err.Message)
+	assert.EqualValues(t, http.StatusInternalServerError, err.Status)
+}
+
+func TestUpdateFavoritesVisibilityUnlisted(t *testing.T) {
+	user := User{
+		ID:                  1,
+		FavoritesVisibility: FavoritesUnlisted,
+	}
+
+	result, err := user.UpdateFavoritesVisibility(db)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, FavoritesUnlisted, result.(User).FavoritesVisibility)
+	assert.NotEmpty(t, result.(User).FavoritesShareToken)
+}
+
+func TestUpdateFavoritesVisibilityClearsToken(t *testing.T) {
+	user := User{
+		ID:                  1,
+		FavoritesVisibility: FavoritesPrivate,
+		FavoritesShareToken: "share_token",
+	}
+
+	result, err := user.UpdateFavoritesVisibility(db)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, "", result.(User).FavoritesShareToken)
+}
+
+func TestUpdateFavoritesVisibilityExecError(t *testing.T) {
+	user := User{
+		ID:                  1,
+		FavoritesVisibility: FavoritesPublic,
+	}
+
+	db.(*database_mock.DatabaseClientMock).CanExec = false
+
+	result, err := user.UpdateFavoritesVisibility(db)
+
+	db.(*database_mock.DatabaseClientMock).CanExec = true
+
+	assert.Nil(t, result)
+	assert.EqualValues(t, "Error when trying to update favorites visibility", err.Message)
+	assert.EqualValues(t, http.StatusInternalServerError, err.Status)
+}
+
+func canView(t *testing.T, owner User, viewerID int64, shareToken string) bool {
+	visible, err := owner.CanViewFavorites(viewerID, shareToken, db)
+	assert.Nil(t, err)
+
+	return visible
+}
+
+func TestCanViewFavorites(t *testing.T) {
+	owner := User{ID: 1, Status: StatusActive, FavoritesVisibility: FavoritesPublic}
+	assert.True(t, canView(t, owner, 0, ""))
+	assert.True(t, canView(t, owner, 2, ""))
+
+	owner.FavoritesVisibility = FavoritesPrivate
+	assert.True(t, canView(t, owner, 1, ""))
+	assert.False(t, canView(t, owner, 0, ""))
+	assert.False(t, canView(t, owner, 2, ""))
+
+	owner.FavoritesVisibility = FavoritesUnlisted
+	owner.FavoritesShareToken = "share_token"
+	assert.True(t, canView(t, owner, 0, "share_token"))
+	assert.False(t, canView(t, owner, 0, "other_token"))
+	assert.False(t, canView(t, owner, 2, ""))
+
+	owner.FavoritesShareToken = ""
+	assert.False(t, canView(t, owner, 0, ""))
+}
+
+func TestCanViewFavoritesInactiveOwner(t *testing.T) {
+	for _, status := range []string{StatusPending, StatusSuspended, StatusDeleted} {
+		owner := User{ID: 1, Status: status, FavoritesVisibility: FavoritesPublic}
+		assert.True(t, canView(t, owner, 1, ""))
+		assert.False(t, canView(t, owner, 0, ""))
+		assert.False(t, canView(t, owner, 2, ""))
+
+		owner.FavoritesVisibility = FavoritesUnlisted
+		owner.FavoritesShareToken = "share_token"
+		assert.False(t, canView(t, owner, 0, "share_token"))
+
+		owner.FavoritesVisibility = FavoritesFollowers
+		assert.False(t, canView(t, owner, 2, ""))
+	}
+}
+
+func TestCanViewFavoritesFollowers(t *testing.T) {
+	owner := User{ID: 1, Status: StatusActive, FavoritesVisibility: FavoritesFollowers}
+
+	assert.True(t, canView(t, owner, 1, ""))
+	assert.False(t, canView(t, owner, 0, ""))
+	assert.True(t, canView(t, owner, 2, ""))
+
+	db.(*database_mock.DatabaseClientMock).CanQueryRow = false
+
+	visible, err := owner.CanViewFavorites(2, "", db)
+
+	db.(*database_mock.DatabaseClientMock).CanQueryRow = true
+
+	assert.False(t, visible)
+	assert.EqualValues(t, "Error when trying to check follow", err.Message)
+	assert.EqualValues(t, http.StatusInternalServerError, err.Status)
+}
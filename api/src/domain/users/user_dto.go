@@ -1,15 +1,40 @@
 package users
 
 import (
+	"fmt"
 	"net/mail"
 	"strings"
+	"time"
 
+	"github.com/ericbg27/top10movies-api/src/datasources/cache"
 	"github.com/ericbg27/top10movies-api/src/datasources/database"
+	"github.com/ericbg27/top10movies-api/src/datasources/mailer"
+	"github.com/ericbg27/top10movies-api/src/utils/pagination"
 	"github.com/ericbg27/top10movies-api/src/utils/rest_errors"
 )
 
 const (
-	StatusActive = "active"
+	StatusPending   = "pending"
+	StatusActive    = "active"
+	StatusSuspended = "suspended"
+	StatusDeleted   = "deleted"
+
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+
+	// FavoritesFollowers lists are shown to followers. Following needs no approval, so any signed in
+	// user can see one by following its owner: it keeps the list off anonymous views and is not
+	// access control.
+	FavoritesPublic    = "public"
+	FavoritesFollowers = "followers"
+	FavoritesPrivate   = "private"
+	FavoritesUnlisted  = "unlisted"
+
+	SortByName            = "name"
+	SortByNameDesc        = "-name"
+	SortByDateCreated     = "date_created"
+	SortByDateCreatedDesc = "-date_created"
 )
 
 type UserInterface interface {
@@ -19,9 +44,24 @@ type UserInterface interface {
 	Save(database.DatabaseClient) *rest_errors.RestErr
 	Update(UserInterface, bool, database.DatabaseClient) (UserInterface, *rest_errors.RestErr)
 	Delete(database.DatabaseClient) *rest_errors.RestErr
-	Search(database.DatabaseClient) ([]UserInterface, *rest_errors.RestErr)
+	Search(SearchCriteria, database.DatabaseClient) (*pagination.Page, *rest_errors.RestErr)
+	List(int, int, database.DatabaseClient) ([]UserInterface, *rest_errors.RestErr)
+	UpdateStatus(string, database.DatabaseClient) *rest_errors.RestErr
+	UpdateRole(database.DatabaseClient) *rest_errors.RestErr
+	PurgeDeleted(time.Time, database.DatabaseClient) (int64, *rest_errors.RestErr)
+	CreateVerificationToken(cache.CacheClient) (string, *rest_errors.RestErr)
+	VerificationMessage(string) mailer.Message
+	Verify(string, database.DatabaseClient, cache.CacheClient) *rest_errors.RestErr
+	UpdatePassword(database.DatabaseClient) *rest_errors.RestErr
+	CreatePasswordResetToken(cache.CacheClient) (string, *rest_errors.RestErr)
+	PasswordResetMessage(string) mailer.Message
+	ResetPassword(string, database.DatabaseClient, cache.CacheClient) (UserInterface, *rest_errors.RestErr)
+	Marshall(bool) interface{}
+	CanViewFavorites(int64, string, database.DatabaseClient) (bool, *rest_errors.RestErr)
+	UpdateFavoritesVisibility(database.DatabaseClient) (UserInterface, *rest_errors.RestErr)
 }
 
+// User is the internal representation of a user. Responses use PublicUser or PrivateUser, see Marshall.
 type User struct {
 	ID          int64  `json:"id"`
 	FirstName   string `json:"first_name"`
@@ -30,6 +70,79 @@ type User struct {
 	DateCreated string `json:"date_created"`
 	Status      string `json:"status"`
 	Password    string `json:"password"`
+	Role        string `json:"role"`
+
+	FavoritesVisibility string `json:"favorites_visibility"`
+	FavoritesShareToken string `json:"favorites_share_token"`
+}
+
+// SearchCriteria describes a page of a user search. Only active users are listed and emails are
+// not matched unless IncludeEmail is set, for admin searches, which match emails by prefix and also
+// list pending and suspended users.
+type SearchCriteria struct {
+	Query        string
+	IncludeEmail bool
+	Sort         string
+	Limit        int
+	Cursor       string
+}
+
+var (
+	FavoritesVisibilities = []string{FavoritesPublic, FavoritesFollowers, FavoritesPrivate, FavoritesUnlisted}
+
+	SortOptions = []string{SortByName, SortByNameDesc, SortByDateCreated, SortByDateCreatedDesc}
+
+	statusTransitions = map[string][]string{
+		StatusPending:   {StatusActive, StatusDeleted},
+		StatusActive:    {StatusSuspended, StatusDeleted},
+		StatusSuspended: {StatusActive, StatusDeleted},
+		StatusDeleted:   {StatusActive},
+	}
+)
+
+func IsValidStatus(status string) bool {
+	_, ok := statusTransitions[status]
+
+	return ok
+}
+
+func IsValidFavoritesVisibility(visibility string) bool {
+	for _, option := range FavoritesVisibilities {
+		if option == visibility {
+			return true
+		}
+	}
+
+	return false
+}
+
+func IsValidSort(sort string) bool {
+	for _, option := range SortOptions {
+		if option == sort {
+			return true
+		}
+	}
+
+	return false
+}
+
+func CanTransitionStatus(from string, to string) bool {
+	for _, allowed := range statusTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+func IsValidRole(role string) bool {
+	switch role {
+	case RoleUser, RoleModerator, RoleAdmin:
+		return true
+	default:
+		return false
+	}
 }
 
 func (user User) Validate() (UserInterface, *rest_errors.RestErr) {
@@ -50,6 +163,7 @@ func (user User) Validate() (UserInterface, *rest_errors.RestErr) {
 		return nil, rest_errors.NewBadRequestError("Invalid email address")
 	}
 
+	// Password holds the bcrypt hash at this point, the plain text one goes through ValidatePassword
 	validatedUser.Password = strings.TrimSpace(validatedUser.Password)
 	if validatedUser.Password == "" {
 		return nil, rest_errors.NewBadRequestError("Invalid password")
@@ -57,3 +171,19 @@ func (user User) Validate() (UserInterface, *rest_errors.RestErr) {
 
 	return validatedUser, nil
 }
+
+func (user User) VerificationMessage(verificationLink string) mailer.Message {
+	return mailer.Message{
+		To:      user.Email,
+		Subject: "Confirm your top10movies account",
+		Body:    fmt.Sprintf("Hi %s,\n\nConfirm your email address by opening the link below:\n\n%s\n", user.FirstName, verificationLink),
+	}
+}
+
+func (user User) PasswordResetMessage(resetLink string) mailer.Message {
+	return mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your top10movies password",
+		Body:    fmt.Sprintf("Hi %s,\n\nReset your password by opening the link below:\n\n%s\n\nIf you did not ask for a password reset you can ignore this email.\n", user.FirstName, resetLink),
+	}
+}
@@ -16,6 +16,7 @@ func TestMain(m *testing.M) {
 		CanQueryRow:    true,
 		CanExec:        true,
 		CanScanResults: true,
+		CanBeginTx:     true,
 	}
 
 	db.SetupDbConnection()
@@ -96,3 +97,32 @@ func TestValidatePasswordError(t *testing.T) {
 	assert.NotNil(t, err)
 	assert.Nil(t, result)
 }
+
+func TestIsValidRole(t *testing.T) {
+	assert.True(t, IsValidRole(RoleUser))
+	assert.True(t, IsValidRole(RoleModerator))
+	assert.True(t, IsValidRole(RoleAdmin))
+	assert.False(t, IsValidRole("superuser"))
+	assert.False(t, IsValidRole(""))
+}
+
+func TestIsValidStatus(t *testing.T) {
+	assert.True(t, IsValidStatus(StatusPending))
+	assert.True(t, IsValidStatus(StatusActive))
+	assert.True(t, IsValidStatus(StatusSuspended))
+	assert.True(t, IsValidStatus(StatusDeleted))
+	assert.False(t, IsValidStatus("banned"))
+	assert.False(t, IsValidStatus(""))
+}
+
+func TestCanTransitionStatus(t *testing.T) {
+	assert.True(t, CanTransitionStatus(StatusPending, StatusActive))
+	assert.True(t, CanTransitionStatus(StatusActive, StatusSuspended))
+	assert.True(t, CanTransitionStatus(StatusSuspended, StatusActive))
+	assert.True(t, CanTransitionStatus(StatusActive, StatusDeleted))
+	assert.True(t, CanTransitionStatus(StatusDeleted, StatusActive))
+	assert.False(t, CanTransitionStatus(StatusActive, StatusPending))
+	assert.False(t, CanTransitionStatus(StatusDeleted, StatusSuspended))
+	assert.False(t, CanTransitionStatus(StatusActive, StatusActive))
+	assert.False(t, CanTransitionStatus("", StatusDeleted))
+}
@@ -0,0 +1,65 @@
+package users
+
+import "encoding/json"
+
+// PublicUser holds the display data other users are allowed to see. FavoritesListed tells whether
+// the favorites list may be linked from public listings, which unlisted and private lists are not.
+type PublicUser struct {
+	ID              int64  `json:"id"`
+	FirstName       string `json:"first_name"`
+	LastName        string `json:"last_name"`
+	FavoritesListed bool   `json:"favorites_listed"`
+}
+
+// PrivateUser holds the account data shown to the user themselves and to administrators
+type PrivateUser struct {
+	ID          int64  `json:"id"`
+	FirstName   string `json:"first_name"`
+	LastName    string `json:"last_name"`
+	Email       string `json:"email"`
+	DateCreated string `json:"date_created"`
+	Status      string `json:"status"`
+	Role        string `json:"role"`
+
+	FavoritesVisibility string `json:"favorites_visibility"`
+	FavoritesShareToken string `json:"favorites_share_token,omitempty"`
+}
+
+func (user User) Marshall(isPublic bool) interface{} {
+	if isPublic {
+		return PublicUser{
+			ID:              user.ID,
+			FirstName:       user.FirstName,
+			LastName:        user.LastName,
+			FavoritesListed: user.FavoritesVisibility == FavoritesPublic || user.FavoritesVisibility == FavoritesFollowers,
+		}
+	}
+
+	return PrivateUser{
+		ID:          user.ID,
+		FirstName:   user.FirstName,
+		LastName:    user.LastName,
+		Email:       user.Email,
+		DateCreated: user.DateCreated,
+		Status:      user.Status,
+		Role:        user.Role,
+
+		FavoritesVisibility: user.FavoritesVisibility,
+		FavoritesShareToken: user.FavoritesShareToken,
+	}
+}
+
+// MarshalJSON encodes User, the internal representation, as its private one so the password
+// hash can never end up in a response
+func (user User) MarshalJSON() ([]byte, error) {
+	return json.Marshal(user.Marshall(false))
+}
+
+func MarshallUsers(usersToMarshall []UserInterface, isPublic bool) []interface{} {
+	marshalledUsers := make([]interface{}, 0, len(usersToMarshall))
+	for _, user := range usersToMarshall {
+		marshalledUsers = append(marshalledUsers, user.Marshall(isPublic))
+	}
+
+	return marshalledUsers
+}
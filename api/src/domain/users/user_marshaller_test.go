@@ -0,0 +1,79 @@
+package users
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	marshallUser = User{
+		ID:          1,
+		FirstName:   "John",
+		LastName:    "Doe",
+		Email:       "johndoe@gmail.com",
+		DateCreated: "2021-01-01",
+		Status:      StatusActive,
+		Password:    "$2a$10$hash",
+		Role:        RoleUser,
+	}
+)
+
+func TestMarshallPublic(t *testing.T) {
+	publicUser, ok := marshallUser.Marshall(true).(PublicUser)
+
+	assert.True(t, ok)
+	assert.EqualValues(t, PublicUser{ID: 1, FirstName: "John", LastName: "Doe"}, publicUser)
+}
+
+func TestMarshallPublicFavoritesListed(t *testing.T) {
+	listedUser := marshallUser
+	listedUser.FavoritesVisibility = FavoritesPublic
+
+	assert.True(t, listedUser.Marshall(true).(PublicUser).FavoritesListed)
+
+	listedUser.FavoritesVisibility = FavoritesUnlisted
+	listedUser.FavoritesShareToken = "share_token"
+
+	publicUser := listedUser.Marshall(true).(PublicUser)
+	assert.False(t, publicUser.FavoritesListed)
+
+	userData, err := json.Marshal(publicUser)
+	assert.Nil(t, err)
+	assert.NotContains(t, string(userData), "share_token")
+}
+
+func TestMarshallPrivate(t *testing.T) {
+	privateUser, ok := marshallUser.Marshall(false).(PrivateUser)
+
+	assert.True(t, ok)
+	assert.EqualValues(t, "johndoe@gmail.com", privateUser.Email)
+	assert.EqualValues(t, StatusActive, privateUser.Status)
+	assert.EqualValues(t, RoleUser, privateUser.Role)
+}
+
+func TestMarshalJSONNeverIncludesPassword(t *testing.T) {
+	userData, err := json.Marshal(marshallUser)
+	assert.Nil(t, err)
+
+	var fields map[string]interface{}
+	assert.Nil(t, json.Unmarshal(userData, &fields))
+
+	assert.NotContains(t, fields, "password")
+	assert.NotContains(t, string(userData), marshallUser.Password)
+	assert.EqualValues(t, "johndoe@gmail.com", fields["email"])
+
+	var boundUser User
+	assert.Nil(t, json.Unmarshal([]byte(`{"email":"johndoe@gmail.com","password":"Sup3rSecretPass"}`), &boundUser))
+	assert.EqualValues(t, "Sup3rSecretPass", boundUser.Password)
+}
+
+func TestMarshallUsers(t *testing.T) {
+	marshalledUsers := MarshallUsers([]UserInterface{marshallUser}, true)
+
+	assert.EqualValues(t, 1, len(marshalledUsers))
+	assert.IsType(t, PublicUser{}, marshalledUsers[0])
+
+	assert.NotNil(t, MarshallUsers(nil, false))
+}
@@ -4,23 +4,36 @@ import (
 	"errors"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/ericbg27/top10movies-api/src/datasources/cache"
 	auth "github.com/ericbg27/top10movies-api/src/utils/authorization"
 )
 
 type AuthorizationMock struct {
-	CanCreate  bool
-	Authorized bool
-	WrongID    bool
+	CanCreate     bool
+	Authorized    bool
+	WrongID       bool
+	CanRefresh    bool
+	RefreshReused bool
+	CanRevoke     bool
+	CanList       bool
+	UserAgent     string
+	Role          string
+	RefreshUserID int64
 }
 
-func (a AuthorizationMock) CreateToken(userId int64) (*auth.TokenDetails, error) {
+func (a *AuthorizationMock) SetupCacheClient(cacheClient cache.CacheClient) {}
+
+func (a *AuthorizationMock) CreateToken(userId int64, role string, userAgent string) (*auth.TokenDetails, error) {
 	if !a.CanCreate {
 		return nil, errors.New("failed to create token")
 	}
 
 	var sb strings.Builder
 
+	a.UserAgent = userAgent
+
 	sb.WriteString("token_")
 	sb.WriteString(strconv.Itoa(int(userId)))
 
@@ -33,16 +46,81 @@ func (a AuthorizationMock) CreateToken(userId int64) (*auth.TokenDetails, error)
 	return tokenInfo, nil
 }
 
-func (a AuthorizationMock) FetchAuth(bearToken string) (uint64, error) {
+func (a AuthorizationMock) RefreshToken(refreshToken string) (*auth.TokenDetails, error) {
+	if a.RefreshReused {
+		return nil, auth.ErrRefreshTokenReused
+	}
+
+	if !a.CanRefresh {
+		return nil, auth.ErrInvalidRefreshToken
+	}
+
+	tokenInfo := &auth.TokenDetails{
+		AccessToken:  "token_1",
+		RefreshToken: "refresh_token_1",
+	}
+
+	return tokenInfo, nil
+}
+
+func (a AuthorizationMock) RefreshTokenUserID(refreshToken string) (int64, error) {
+	if !a.CanRefresh {
+		return 0, auth.ErrInvalidRefreshToken
+	}
+
+	if a.RefreshUserID != 0 {
+		return a.RefreshUserID, nil
+	}
+
+	return 1, nil
+}
+
+func (a AuthorizationMock) FetchAuth(bearToken string) (uint64, string, error) {
 	if !a.Authorized {
-		return 0, errors.New("not authorized")
+		return 0, "", errors.New("not authorized")
 	}
 
 	if a.WrongID {
-		return 0, nil
+		return 0, a.Role, nil
 	}
 
 	id, _ := strconv.Atoi(strings.Split(bearToken, "_")[1])
 
-	return uint64(id), nil
+	return uint64(id), a.Role, nil
+}
+
+func (a AuthorizationMock) Logout(bearToken string) error {
+	if !a.Authorized {
+		return auth.ErrInvalidAccessToken
+	}
+
+	if !a.CanRevoke {
+		return errors.New("failed to revoke session")
+	}
+
+	return nil
+}
+
+func (a AuthorizationMock) RevokeAllSessions(userId int64) error {
+	if !a.CanRevoke {
+		return errors.New("failed to revoke sessions")
+	}
+
+	return nil
+}
+
+func (a AuthorizationMock) ListSessions(userId int64) ([]auth.SessionInfo, error) {
+	if !a.CanList {
+		return nil, errors.New("failed to list sessions")
+	}
+
+	sessions := []auth.SessionInfo{
+		{
+			ID:        "session_" + strconv.Itoa(int(userId)),
+			CreatedAt: time.Unix(0, 0).UTC(),
+			UserAgent: a.UserAgent,
+		},
+	}
+
+	return sessions, nil
 }
@@ -0,0 +1,143 @@
+package cache
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ericbg27/top10movies-api/src/datasources/cache"
+)
+
+type CacheClientMock struct {
+	Connected bool
+	CanGet    bool
+	CanSet    bool
+	CanDel    bool
+	Values    map[string]string
+	Sets      map[string][]string
+}
+
+func (c *CacheClientMock) SetupCacheConnection() {
+	c.Connected = true
+
+	if c.Values == nil {
+		c.Values = make(map[string]string)
+	}
+
+	if c.Sets == nil {
+		c.Sets = make(map[string][]string)
+	}
+}
+
+func (c *CacheClientMock) CloseCacheConnection() {
+	c.Connected = false
+}
+
+func (c *CacheClientMock) Get(key string) (string, error) {
+	if !c.CanGet {
+		return "", errors.New("unable to get")
+	}
+
+	value, ok := c.Values[key]
+	if !ok {
+		return "", cache.ErrCacheMiss
+	}
+
+	return value, nil
+}
+
+func (c *CacheClientMock) Set(key string, value interface{}, ttl time.Duration) error {
+	if !c.CanSet {
+		return errors.New("unable to set")
+	}
+
+	switch v := value.(type) {
+	case []byte:
+		c.Values[key] = string(v)
+	case string:
+		c.Values[key] = v
+	default:
+		return errors.New("unsupported value type")
+	}
+
+	return nil
+}
+
+func (c *CacheClientMock) Del(keys ...string) (int64, error) {
+	if !c.CanDel {
+		return 0, errors.New("unable to delete")
+	}
+
+	var deleted int64
+	for _, key := range keys {
+		if _, ok := c.Values[key]; ok {
+			delete(c.Values, key)
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
+
+func (c *CacheClientMock) MGet(keys ...string) (map[string]string, error) {
+	if !c.CanGet {
+		return nil, errors.New("unable to get")
+	}
+
+	values := make(map[string]string)
+	for _, key := range keys {
+		if value, ok := c.Values[key]; ok {
+			values[key] = value
+		}
+	}
+
+	return values, nil
+}
+
+func (c *CacheClientMock) SAdd(key string, ttl time.Duration, members ...string) error {
+	if !c.CanSet {
+		return errors.New("unable to set")
+	}
+
+	for _, member := range members {
+		if !containsMember(c.Sets[key], member) {
+			c.Sets[key] = append(c.Sets[key], member)
+		}
+	}
+
+	return nil
+}
+
+func (c *CacheClientMock) SRem(key string, members ...string) error {
+	if !c.CanDel {
+		return errors.New("unable to delete")
+	}
+
+	var kept []string
+	for _, member := range c.Sets[key] {
+		if !containsMember(members, member) {
+			kept = append(kept, member)
+		}
+	}
+
+	c.Sets[key] = kept
+
+	return nil
+}
+
+func (c *CacheClientMock) SMembers(key string) ([]string, error) {
+	if !c.CanGet {
+		return nil, errors.New("unable to get")
+	}
+
+	return append(make([]string, 0), c.Sets[key]...), nil
+}
+
+func containsMember(members []string, member string) bool {
+	for _, current := range members {
+		if current == member {
+			return true
+		}
+	}
+
+	return false
+}
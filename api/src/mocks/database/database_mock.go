@@ -14,6 +14,14 @@ type DatabaseClientMock struct {
 	CanQueryRow    bool
 	CanExec        bool
 	CanScanResults bool
+	CanBeginTx     bool
+	NoRows         bool
+	Committed      int
+	RolledBack     int
+}
+
+type TransactionMock struct {
+	db *DatabaseClientMock
 }
 
 type ModificationResultMock struct {
@@ -23,6 +31,7 @@ type ModificationResultMock struct {
 type UsersSingleElementResultMock struct {
 	result  interface{}
 	CanScan bool
+	NoRows  bool
 }
 
 type UsersMultipleElementsResultMock struct {
@@ -51,32 +60,38 @@ func (d *DatabaseClientMock) Query(ctx context.Context, query string, arguments
 		FirstName   string
 		LastName    string
 		Email       string
-		DateCreated string
 		Status      string
-		Password    string
+		Role        string
+		Visibility  string
+		DateCreated string
 	}{
 		ID:          1,
 		FirstName:   "John",
 		LastName:    "Doe",
 		Email:       "johndoe@gmail.com",
-		DateCreated: "",
 		Status:      "",
-		Password:    "1234",
+		Role:        "user",
+		Visibility:  "public",
+		DateCreated: "2021-01-01",
 	})
 	usersResult = append(usersResult, struct {
-		ID        int64
-		FirstName string
-		LastName  string
-		Email     string
-		Status    string
-		Password  string
+		ID          int64
+		FirstName   string
+		LastName    string
+		Email       string
+		Status      string
+		Role        string
+		Visibility  string
+		DateCreated string
 	}{
-		ID:        2,
-		FirstName: "Josh",
-		LastName:  "Davis",
-		Email:     "joshdavis@gmail.com",
-		Status:    "active",
-		Password:  "12345",
+		ID:          2,
+		FirstName:   "Josh",
+		LastName:    "Davis",
+		Email:       "joshdavis@gmail.com",
+		Status:      "active",
+		Role:        "admin",
+		Visibility:  "private",
+		DateCreated: "2021-02-01",
 	})
 
 	var result UsersMultipleElementsResultMock
@@ -93,24 +108,31 @@ func (d *DatabaseClientMock) QueryRow(ctx context.Context, query string, argumen
 	}
 
 	userResult := struct {
-		ID        int64
-		FirstName string
-		LastName  string
-		Email     string
-		Status    string
-		Password  string
+		ID         int64
+		FirstName  string
+		LastName   string
+		Email      string
+		Status     string
+		Password   string
+		Role       string
+		Visibility string
+		ShareToken string
 	}{
-		ID:        1,
-		FirstName: "John",
-		LastName:  "Doe",
-		Email:     "johndoe@gmail.com",
-		Status:    "active",
-		Password:  "1234",
+		ID:         1,
+		FirstName:  "John",
+		LastName:   "Doe",
+		Email:      "johndoe@gmail.com",
+		Status:     "active",
+		Password:   "1234",
+		Role:       "user",
+		Visibility: "public",
+		ShareToken: "",
 	}
 
 	var result UsersSingleElementResultMock
 	result.result = userResult
 	result.CanScan = d.CanScanResults
+	result.NoRows = d.NoRows
 
 	return result, nil
 }
@@ -126,6 +148,53 @@ func (d *DatabaseClientMock) Exec(ctx context.Context, query string, arguments .
 	return result, nil
 }
 
+func (d *DatabaseClientMock) BeginTx(ctx context.Context) (database.Transaction, error) {
+	if !d.CanBeginTx {
+		return nil, errors.New("unable to begin transaction")
+	}
+
+	return &TransactionMock{db: d}, nil
+}
+
+func (d *DatabaseClientMock) WithTx(ctx context.Context, fn func(tx database.Transaction) error) error {
+	tx, err := d.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback(ctx)
+
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (t *TransactionMock) Query(ctx context.Context, query string, arguments ...interface{}) (database.MultipleElementsResult, error) {
+	return t.db.Query(ctx, query, arguments...)
+}
+
+func (t *TransactionMock) QueryRow(ctx context.Context, query string, arguments ...interface{}) (database.SingleElementResult, error) {
+	return t.db.QueryRow(ctx, query, arguments...)
+}
+
+func (t *TransactionMock) Exec(ctx context.Context, query string, arguments ...interface{}) (database.ModificationResult, error) {
+	return t.db.Exec(ctx, query, arguments...)
+}
+
+func (t *TransactionMock) Commit(ctx context.Context) error {
+	t.db.Committed++
+
+	return nil
+}
+
+func (t *TransactionMock) Rollback(ctx context.Context) error {
+	t.db.RolledBack++
+
+	return nil
+}
+
 func (m ModificationResultMock) RowsAffected() int64 {
 	return m.affectedRows
 }
@@ -135,6 +204,10 @@ func (us UsersSingleElementResultMock) Scan(arguments ...interface{}) error {
 		return errors.New("failed to scan")
 	}
 
+	if us.NoRows {
+		return database.ErrNoRows
+	}
+
 	resultReflection := reflect.TypeOf(us.result)
 	resultReflectionValue := reflect.ValueOf(us.result)
 
@@ -190,6 +263,10 @@ func (um *UsersMultipleElementsResultMock) Scan(arguments ...interface{}) error
 	return nil
 }
 
+func (um *UsersMultipleElementsResultMock) Close() {
+	um.scanIndex = len(um.results)
+}
+
 func (um *UsersMultipleElementsResultMock) Next() bool {
 	return um.scanIndex < len(um.results)
 }
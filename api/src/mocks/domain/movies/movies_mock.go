@@ -1,6 +1,7 @@
 package movies
 
 import (
+	"github.com/ericbg27/top10movies-api/src/datasources/cache"
 	movies "github.com/ericbg27/top10movies-api/src/domain/movies"
 	"github.com/ericbg27/top10movies-api/src/utils/rest_errors"
 	"github.com/ryanbradynd05/go-tmdb"
@@ -13,7 +14,7 @@ type MovieInfoMock struct {
 	CanGet     bool
 }
 
-func (m *MovieInfoMock) AddMovie() *rest_errors.RestErr {
+func (m *MovieInfoMock) AddMovie(c cache.CacheClient) *rest_errors.RestErr {
 	if !m.CanAdd {
 		return rest_errors.NewInternalServerError("Failed to add movie")
 	}
@@ -23,7 +24,7 @@ func (m *MovieInfoMock) AddMovie() *rest_errors.RestErr {
 	return nil
 }
 
-func (m *MovieInfoMock) GetMovie() (movies.MovieInterface, *rest_errors.RestErr) {
+func (m *MovieInfoMock) GetMovie(c cache.CacheClient) (movies.MovieInterface, *rest_errors.RestErr) {
 	if !m.CanGet {
 		return nil, rest_errors.NewInternalServerError("Failed to get movie")
 	}
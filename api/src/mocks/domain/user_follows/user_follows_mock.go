@@ -0,0 +1,50 @@
+package user_follows
+
+import (
+	"github.com/ericbg27/top10movies-api/src/datasources/database"
+	"github.com/ericbg27/top10movies-api/src/domain/users"
+	"github.com/ericbg27/top10movies-api/src/utils/pagination"
+	"github.com/ericbg27/top10movies-api/src/utils/rest_errors"
+)
+
+type UserFollowMock struct {
+	CanFollow bool
+	Following bool
+	CanList   bool
+}
+
+func (f UserFollowMock) Validate() *rest_errors.RestErr {
+	return nil
+}
+
+func (f UserFollowMock) Follow(db database.DatabaseClient) *rest_errors.RestErr {
+	if !f.CanFollow {
+		return rest_errors.NewInternalServerError("Error when trying to follow user")
+	}
+
+	return nil
+}
+
+func (f UserFollowMock) Unfollow(db database.DatabaseClient) *rest_errors.RestErr {
+	if !f.Following {
+		return rest_errors.NewNotFoundError("User is not being followed")
+	}
+
+	return nil
+}
+
+func (f UserFollowMock) GetFollowers(limit int, cursor string, db database.DatabaseClient) (*pagination.Page, *rest_errors.RestErr) {
+	if !f.CanList {
+		return nil, rest_errors.NewInternalServerError("Error when trying to list follows")
+	}
+
+	return &pagination.Page{
+		Items: []users.UserInterface{users.User{ID: 2, FirstName: "Josh", LastName: "Davis"}},
+		Total: 1,
+		Limit: limit,
+	}, nil
+}
+
+func (f UserFollowMock) GetFollowing(limit int, cursor string, db database.DatabaseClient) (*pagination.Page, *rest_errors.RestErr) {
+	return f.GetFollowers(limit, cursor, db)
+}
@@ -0,0 +1,52 @@
+package user_profiles
+
+import (
+	"github.com/ericbg27/top10movies-api/src/datasources/database"
+	"github.com/ericbg27/top10movies-api/src/domain/user_profiles"
+	"github.com/ericbg27/top10movies-api/src/utils/rest_errors"
+)
+
+type UserProfileMock struct {
+	Exists    bool
+	CanGet    bool
+	CanUpdate bool
+	Handle    string
+	Bio       string
+}
+
+func (p UserProfileMock) Validate() (user_profiles.UserProfileInterface, *rest_errors.RestErr) {
+	return p, nil
+}
+
+func (p UserProfileMock) Get(db database.DatabaseClient) (user_profiles.UserProfileInterface, *rest_errors.RestErr) {
+	if !p.CanGet {
+		return nil, rest_errors.NewInternalServerError("Error when trying to get user profile")
+	}
+
+	if !p.Exists {
+		return nil, rest_errors.NewNotFoundError("Profile not found")
+	}
+
+	return p, nil
+}
+
+func (p UserProfileMock) GetByHandle(db database.DatabaseClient) (user_profiles.UserProfileInterface, *rest_errors.RestErr) {
+	return p.Get(db)
+}
+
+// Update returns the receiver merged with newProfile so tests can tell which profile the update started from
+func (p UserProfileMock) Update(newProfile user_profiles.UserProfileInterface, isPartial bool, db database.DatabaseClient) (user_profiles.UserProfileInterface, *rest_errors.RestErr) {
+	if !p.CanUpdate {
+		return nil, rest_errors.NewInternalServerError("Error when trying to update user profile")
+	}
+
+	toUpdateProfile := newProfile.(UserProfileMock)
+	if toUpdateProfile.Handle != "" || !isPartial {
+		p.Handle = toUpdateProfile.Handle
+	}
+	if toUpdateProfile.Bio != "" || !isPartial {
+		p.Bio = toUpdateProfile.Bio
+	}
+
+	return p, nil
+}
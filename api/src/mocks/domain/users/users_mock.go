@@ -1,8 +1,13 @@
 package users
 
 import (
+	"time"
+
+	"github.com/ericbg27/top10movies-api/src/datasources/cache"
 	"github.com/ericbg27/top10movies-api/src/datasources/database"
+	"github.com/ericbg27/top10movies-api/src/datasources/mailer"
 	"github.com/ericbg27/top10movies-api/src/domain/users"
+	"github.com/ericbg27/top10movies-api/src/utils/pagination"
 	"github.com/ericbg27/top10movies-api/src/utils/rest_errors"
 )
 
@@ -12,9 +17,13 @@ type UserMock struct {
 	CanSave   bool
 	CanUpdate bool
 	CanDelete bool
-	FirstName string
-	LastName  string
-	Email     string
+	// Missing makes the lookups answer as if the user did not exist
+	Missing bool
+	// FavoritesVisible is what CanViewFavorites answers for any viewer
+	FavoritesVisible bool
+	FirstName        string
+	LastName         string
+	Email            string
 }
 
 func (u UserMock) Validate() (users.UserInterface, *rest_errors.RestErr) {
@@ -42,6 +51,10 @@ func (u UserMock) Get(db database.DatabaseClient) (users.UserInterface, *rest_er
 func (u UserMock) GetById(db database.DatabaseClient) (users.UserInterface, *rest_errors.RestErr) {
 	savedUser := u
 
+	if savedUser.Missing {
+		return nil, rest_errors.NewNotFoundError("User not found")
+	}
+
 	if !savedUser.CanGet {
 		return nil, rest_errors.NewInternalServerError("Failed to get user by ID")
 	}
@@ -102,7 +115,122 @@ func (u UserMock) Delete(db database.DatabaseClient) *rest_errors.RestErr {
 	return nil
 }
 
-func (u UserMock) Search(db database.DatabaseClient) ([]users.UserInterface, *rest_errors.RestErr) {
-	// TODO
-	return nil, nil
+func (u UserMock) Search(criteria users.SearchCriteria, db database.DatabaseClient) (*pagination.Page, *rest_errors.RestErr) {
+	if !u.CanGet {
+		return nil, rest_errors.NewInternalServerError("Error when trying to search user")
+	}
+
+	return &pagination.Page{
+		Items: []users.UserInterface{u},
+		Total: 1,
+		Limit: criteria.Limit,
+	}, nil
+}
+
+func (u UserMock) List(limit int, offset int, db database.DatabaseClient) ([]users.UserInterface, *rest_errors.RestErr) {
+	if !u.CanGet {
+		return nil, rest_errors.NewInternalServerError("Failed to list users")
+	}
+
+	return []users.UserInterface{u}, nil
+}
+
+func (u UserMock) UpdateStatus(status string, db database.DatabaseClient) *rest_errors.RestErr {
+	if !u.CanUpdate {
+		return rest_errors.NewInternalServerError("Failed to update user status")
+	}
+
+	return nil
+}
+
+func (u UserMock) UpdateRole(db database.DatabaseClient) *rest_errors.RestErr {
+	if !u.CanUpdate {
+		return rest_errors.NewInternalServerError("Failed to update user role")
+	}
+
+	return nil
+}
+
+func (u UserMock) PurgeDeleted(before time.Time, db database.DatabaseClient) (int64, *rest_errors.RestErr) {
+	if !u.CanDelete {
+		return 0, rest_errors.NewInternalServerError("Failed to purge deleted users")
+	}
+
+	return 1, nil
+}
+
+func (u UserMock) CreateVerificationToken(cacheClient cache.CacheClient) (string, *rest_errors.RestErr) {
+	if !u.CanSave {
+		return "", rest_errors.NewInternalServerError("Failed to create verification token")
+	}
+
+	return "verification_token", nil
+}
+
+func (u UserMock) VerificationMessage(verificationLink string) mailer.Message {
+	return mailer.Message{
+		To:   u.Email,
+		Body: verificationLink,
+	}
+}
+
+func (u UserMock) Verify(token string, db database.DatabaseClient, cacheClient cache.CacheClient) *rest_errors.RestErr {
+	if !u.CanUpdate {
+		return rest_errors.NewBadRequestError("Invalid or expired verification token")
+	}
+
+	return nil
+}
+
+func (u UserMock) UpdatePassword(db database.DatabaseClient) *rest_errors.RestErr {
+	if !u.CanUpdate {
+		return rest_errors.NewInternalServerError("Failed to update user password")
+	}
+
+	return nil
+}
+
+func (u UserMock) CreatePasswordResetToken(cacheClient cache.CacheClient) (string, *rest_errors.RestErr) {
+	if !u.CanSave {
+		return "", rest_errors.NewInternalServerError("Failed to create password reset token")
+	}
+
+	return "reset_token", nil
+}
+
+func (u UserMock) PasswordResetMessage(resetLink string) mailer.Message {
+	return mailer.Message{
+		To:   u.Email,
+		Body: resetLink,
+	}
+}
+
+func (u UserMock) ResetPassword(token string, db database.DatabaseClient, cacheClient cache.CacheClient) (users.UserInterface, *rest_errors.RestErr) {
+	if !u.CanUpdate {
+		return nil, rest_errors.NewBadRequestError("Invalid or expired password reset token")
+	}
+
+	return u, nil
+}
+
+func (u UserMock) Marshall(isPublic bool) interface{} {
+	user := users.User{
+		FirstName: u.FirstName,
+		LastName:  u.LastName,
+		Email:     u.Email,
+	}
+
+	return user.Marshall(isPublic)
+}
+
+func (u UserMock) CanViewFavorites(viewerID int64, shareToken string, db database.DatabaseClient) (bool, *rest_errors.RestErr) {
+	return u.FavoritesVisible, nil
+}
+
+func (u UserMock) UpdateFavoritesVisibility(db database.DatabaseClient) (users.UserInterface, *rest_errors.RestErr) {
+	if !u.CanUpdate {
+		return nil, rest_errors.NewInternalServerError("Error when trying to update favorites visibility")
+	}
+
+	return u, nil
 }
@@ -0,0 +1,39 @@
+package loginguard
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ericbg27/top10movies-api/src/datasources/cache"
+)
+
+type LoginGuardMock struct {
+	Lockout  time.Duration
+	CanCheck bool
+	Failures []string
+	Reasons  []string
+	Resets   []string
+}
+
+func (l *LoginGuardMock) SetupCacheClient(cacheClient cache.CacheClient) {}
+
+func (l *LoginGuardMock) Check(email string, ip string) (time.Duration, error) {
+	if !l.CanCheck {
+		return 0, errors.New("unable to check login attempts")
+	}
+
+	return l.Lockout, nil
+}
+
+func (l *LoginGuardMock) RegisterFailure(email string, ip string, reason string) (time.Duration, error) {
+	l.Failures = append(l.Failures, email)
+	l.Reasons = append(l.Reasons, reason)
+
+	return 0, nil
+}
+
+func (l *LoginGuardMock) RegisterSuccess(email string) error {
+	l.Resets = append(l.Resets, email)
+
+	return nil
+}
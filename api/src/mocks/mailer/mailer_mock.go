@@ -0,0 +1,22 @@
+package mailer
+
+import (
+	"errors"
+
+	"github.com/ericbg27/top10movies-api/src/datasources/mailer"
+)
+
+type MailerMock struct {
+	CanSend bool
+	Sent    []mailer.Message
+}
+
+func (m *MailerMock) Send(message mailer.Message) error {
+	if !m.CanSend {
+		return errors.New("unable to send")
+	}
+
+	m.Sent = append(m.Sent, message)
+
+	return nil
+}
@@ -1,17 +1,32 @@
 package movies_service
 
 import (
+	"context"
+
+	"github.com/ericbg27/top10movies-api/src/datasources/cache"
+	"github.com/ericbg27/top10movies-api/src/datasources/movieprovider"
 	"github.com/ericbg27/top10movies-api/src/domain/movies"
 	"github.com/ericbg27/top10movies-api/src/utils/rest_errors"
 	"github.com/ryanbradynd05/go-tmdb"
 )
 
 type MoviesServiceMock struct {
+	cache          cache.CacheClient
+	provider       movieprovider.MovieProvider
 	CanAddMovie    bool
 	CanGetMovie    bool
 	HasMovieCached bool
 	CanSearch      bool
 	AddedMovie     bool
+	FailedMovieIds map[int]bool
+}
+
+func (m *MoviesServiceMock) SetupCacheClient(cacheClient cache.CacheClient) {
+	m.cache = cacheClient
+}
+
+func (m *MoviesServiceMock) SetupMovieProvider(provider movieprovider.MovieProvider) {
+	m.provider = provider
 }
 
 func (m *MoviesServiceMock) SearchMovies(searchOptions map[string]string) (*tmdb.MovieSearchResults, *rest_errors.RestErr) {
@@ -78,3 +93,43 @@ func (m *MoviesServiceMock) GetMovieById(movieId int) (*tmdb.Movie, *rest_errors
 
 	return movieInfo, nil
 }
+
+func (m *MoviesServiceMock) GetMoviesByIds(ctx context.Context, movieIds []int) (map[int]tmdb.Movie, map[int]*rest_errors.RestErr) {
+	fetchedMovies := make(map[int]tmdb.Movie)
+	fetchErrors := make(map[int]*rest_errors.RestErr)
+
+	for _, movieId := range movieIds {
+		if !m.CanGetMovie || m.FailedMovieIds[movieId] {
+			fetchErrors[movieId] = rest_errors.NewInternalServerError("Failed to get movie information")
+
+			continue
+		}
+
+		fetchedMovies[movieId] = tmdb.Movie{
+			ID: movieId,
+		}
+		m.AddedMovie = true
+	}
+
+	return fetchedMovies, fetchErrors
+}
+
+func (m *MoviesServiceMock) GetMovieCredits(movieId int) (*tmdb.MovieCredits, *rest_errors.RestErr) {
+	if !m.CanGetMovie {
+		return nil, rest_errors.NewInternalServerError("Failed to get movie credits")
+	}
+
+	return &tmdb.MovieCredits{
+		ID: movieId,
+	}, nil
+}
+
+func (m *MoviesServiceMock) GetMovieImages(movieId int) (*tmdb.MovieImages, *rest_errors.RestErr) {
+	if !m.CanGetMovie {
+		return nil, rest_errors.NewInternalServerError("Failed to get movie images")
+	}
+
+	return &tmdb.MovieImages{
+		ID: movieId,
+	}, nil
+}
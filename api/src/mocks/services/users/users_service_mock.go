@@ -1,9 +1,18 @@
 package users_service
 
 import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ericbg27/top10movies-api/src/datasources/cache"
 	"github.com/ericbg27/top10movies-api/src/datasources/database"
+	"github.com/ericbg27/top10movies-api/src/datasources/mailer"
 	"github.com/ericbg27/top10movies-api/src/domain/user_favorites"
+	"github.com/ericbg27/top10movies-api/src/domain/user_follows"
+	"github.com/ericbg27/top10movies-api/src/domain/user_profiles"
 	"github.com/ericbg27/top10movies-api/src/domain/users"
+	"github.com/ericbg27/top10movies-api/src/utils/pagination"
 	"github.com/ericbg27/top10movies-api/src/utils/rest_errors"
 	"github.com/ryanbradynd05/go-tmdb"
 )
@@ -11,20 +20,48 @@ import (
 var (
 	MockDb   map[string]string
 	MockDbID map[int64]users.User
-	Now      string
+	// MockProfiles is keyed by user ID
+	MockProfiles map[int64]user_profiles.UserProfile
+	Now          string
 )
 
 type UsersServiceMock struct {
-	db              database.DatabaseClient
-	CanGetFavorites bool
-	CanAddFavorite  bool
-	FavoriteCached  bool
+	db               database.DatabaseClient
+	cache            cache.CacheClient
+	mailer           mailer.Mailer
+	CanGetFavorites  bool
+	CanAddFavorite   bool
+	CanReorder       bool
+	CanRemove        bool
+	CanReplace       bool
+	FavoriteCached   bool
+	CanVerify        bool
+	CanResetPassword bool
+	CanSearch        bool
+	CanUpdateProfile bool
+	CanGetFeed       bool
+
+	VerificationsSent   []string
+	PasswordResetsSent  []string
+	UpdatedPasswordsIDs []int64
+	LastSearch          users.SearchCriteria
+	UpdatedProfiles     []user_profiles.UserProfile
+	Follows             []user_follows.UserFollow
+	LastFeedCursor      string
 }
 
 func (u *UsersServiceMock) SetupDBClient(dbClient database.DatabaseClient) {
 	u.db = dbClient
 }
 
+func (u *UsersServiceMock) SetupCacheClient(cacheClient cache.CacheClient) {
+	u.cache = cacheClient
+}
+
+func (u *UsersServiceMock) SetupMailer(mailerClient mailer.Mailer) {
+	u.mailer = mailerClient
+}
+
 func (u *UsersServiceMock) CreateUser(user users.UserInterface) (users.UserInterface, *rest_errors.RestErr) {
 	usr := user.(users.User)
 	if _, ok := MockDb[usr.Email]; ok {
@@ -44,6 +81,14 @@ func (u *UsersServiceMock) GetUser(user users.UserInterface) (users.UserInterfac
 			ID:       usr.ID,
 			Email:    usr.Email,
 			Password: savedPassword,
+			Status:   users.StatusActive,
+		}
+
+		for _, storedUser := range MockDbID {
+			if storedUser.Email == usr.Email {
+				savedUser.Status = storedUser.Status
+				savedUser.Role = storedUser.Role
+			}
 		}
 
 		return savedUser, nil
@@ -52,6 +97,17 @@ func (u *UsersServiceMock) GetUser(user users.UserInterface) (users.UserInterfac
 	return nil, rest_errors.NewNotFoundError("User not found")
 }
 
+func (u *UsersServiceMock) GetUserById(user users.UserInterface) (users.UserInterface, *rest_errors.RestErr) {
+	usr := user.(users.User)
+
+	savedUser, ok := MockDbID[usr.ID]
+	if !ok {
+		return nil, rest_errors.NewNotFoundError("User not found")
+	}
+
+	return savedUser, nil
+}
+
 func (u *UsersServiceMock) UpdateUser(user users.UserInterface, isPartial bool) (users.UserInterface, *rest_errors.RestErr) {
 	newUser := user.(users.User)
 
@@ -86,9 +142,24 @@ func (u *UsersServiceMock) DeleteUser(user users.UserInterface) *rest_errors.Res
 	return nil
 }
 
-func (u *UsersServiceMock) GetUserFavorites(userFavs user_favorites.UserFavoritesInterface) (user_favorites.UserFavoritesInterface, map[int]bool, *rest_errors.RestErr) {
+func (u *UsersServiceMock) GetUserFavorites(owner users.UserInterface, userFavs user_favorites.UserFavoritesInterface, viewerID int64, shareToken string) (user_favorites.UserFavoritesInterface, map[int]bool, *rest_errors.RestErr) {
 	userFavorites := userFavs.(user_favorites.UserFavorites)
 
+	if savedOwner, ok := MockDbID[owner.(users.User).ID]; ok {
+		// Followers-only lists are checked against Follows instead of the database
+		canView := viewerID == savedOwner.ID || (savedOwner.Status == users.StatusActive && u.isFollowing(viewerID, savedOwner.ID))
+		if savedOwner.FavoritesVisibility != users.FavoritesFollowers {
+			var err *rest_errors.RestErr
+			if canView, err = savedOwner.CanViewFavorites(viewerID, shareToken, u.db); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		if !canView {
+			return nil, nil, rest_errors.NewNotFoundError("Favorites not found")
+		}
+	}
+
 	if !u.CanGetFavorites {
 		return nil, nil, rest_errors.NewInternalServerError("Error when trying to get user favorites")
 	}
@@ -106,6 +177,23 @@ func (u *UsersServiceMock) GetUserFavorites(userFavs user_favorites.UserFavorite
 	return userFavorites, cacheMap, nil
 }
 
+func (u *UsersServiceMock) UpdateFavoritesVisibility(user users.UserInterface) (users.UserInterface, *rest_errors.RestErr) {
+	usr := user.(users.User)
+
+	savedUser, ok := MockDbID[usr.ID]
+	if !ok {
+		return nil, rest_errors.NewNotFoundError("User not found")
+	}
+
+	savedUser.FavoritesVisibility = usr.FavoritesVisibility
+	savedUser.FavoritesShareToken = ""
+	if savedUser.FavoritesVisibility == users.FavoritesUnlisted {
+		savedUser.FavoritesShareToken = "share_token"
+	}
+
+	return savedUser, nil
+}
+
 func (u *UsersServiceMock) AddUserFavorite(userFavs user_favorites.UserFavoritesInterface) *rest_errors.RestErr {
 	if !u.CanAddFavorite {
 		return rest_errors.NewInternalServerError("Error when trying to add user favorite")
@@ -114,7 +202,283 @@ func (u *UsersServiceMock) AddUserFavorite(userFavs user_favorites.UserFavorites
 	return nil
 }
 
-func (u *UsersServiceMock) SearchUser(userToSearch users.UserInterface) ([]users.UserInterface, *rest_errors.RestErr) {
-	// TODO
-	return nil, nil
+func (u *UsersServiceMock) ReorderUserFavorites(userFavs user_favorites.UserFavoritesInterface) *rest_errors.RestErr {
+	if !u.CanReorder {
+		return rest_errors.NewBadRequestError("New order must contain every favorite movie exactly once")
+	}
+
+	return nil
+}
+
+func (u *UsersServiceMock) RemoveUserFavorite(userFavs user_favorites.UserFavoritesInterface) *rest_errors.RestErr {
+	if !u.CanRemove {
+		return rest_errors.NewNotFoundError("Movie is not in user favorites")
+	}
+
+	return nil
+}
+
+func (u *UsersServiceMock) ReplaceUserFavorite(userFavs user_favorites.UserFavoritesInterface, rank int) *rest_errors.RestErr {
+	if !u.CanReplace {
+		return rest_errors.NewNotFoundError(fmt.Sprintf("There is no favorite movie at position %d", rank))
+	}
+
+	return nil
+}
+
+func (u *UsersServiceMock) isFollowing(followerID int64, followedID int64) bool {
+	for _, follow := range u.Follows {
+		if follow.FollowerID == followerID && follow.FollowedID == followedID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (u *UsersServiceMock) GetFeed(userFavs user_favorites.UserFavoritesInterface, limit int, cursor string) (*pagination.Page, *rest_errors.RestErr) {
+	u.LastFeedCursor = cursor
+
+	if !u.CanGetFeed {
+		return nil, rest_errors.NewInternalServerError("Error when trying to get activity feed")
+	}
+
+	events := []user_favorites.FavoriteEvent{
+		{
+			ID:        1,
+			UserID:    3,
+			FirstName: "Jane",
+			LastName:  "Doe",
+			MovieID:   1,
+			Type:      user_favorites.EventAdded,
+			Rank:      1,
+		},
+	}
+
+	return &pagination.Page{Items: events, Total: 1, Limit: limit}, nil
+}
+
+func (u *UsersServiceMock) FollowUser(follow user_follows.UserFollowInterface) *rest_errors.RestErr {
+	userFollow := follow.(user_follows.UserFollow)
+
+	if validateErr := userFollow.Validate(); validateErr != nil {
+		return validateErr
+	}
+
+	if followedUser, ok := MockDbID[userFollow.FollowedID]; !ok || followedUser.Status != users.StatusActive {
+		return rest_errors.NewNotFoundError("User not found")
+	}
+
+	if !u.isFollowing(userFollow.FollowerID, userFollow.FollowedID) {
+		u.Follows = append(u.Follows, userFollow)
+	}
+
+	return nil
+}
+
+func (u *UsersServiceMock) UnfollowUser(follow user_follows.UserFollowInterface) *rest_errors.RestErr {
+	userFollow := follow.(user_follows.UserFollow)
+
+	for index, existing := range u.Follows {
+		if existing == userFollow {
+			u.Follows = append(u.Follows[:index], u.Follows[index+1:]...)
+
+			return nil
+		}
+	}
+
+	return rest_errors.NewNotFoundError("User is not being followed")
+}
+
+func (u *UsersServiceMock) GetFollowers(follow user_follows.UserFollowInterface, limit int, cursor string) (*pagination.Page, *rest_errors.RestErr) {
+	userFollow := follow.(user_follows.UserFollow)
+
+	followers := make([]users.UserInterface, 0)
+	for _, existing := range u.Follows {
+		if existing.FollowedID == userFollow.FollowedID {
+			followers = append(followers, MockDbID[existing.FollowerID])
+		}
+	}
+
+	return &pagination.Page{Items: followers, Total: int64(len(followers)), Limit: limit}, nil
+}
+
+func (u *UsersServiceMock) GetFollowing(follow user_follows.UserFollowInterface, limit int, cursor string) (*pagination.Page, *rest_errors.RestErr) {
+	userFollow := follow.(user_follows.UserFollow)
+
+	following := make([]users.UserInterface, 0)
+	for _, existing := range u.Follows {
+		if existing.FollowerID == userFollow.FollowerID {
+			following = append(following, MockDbID[existing.FollowedID])
+		}
+	}
+
+	return &pagination.Page{Items: following, Total: int64(len(following)), Limit: limit}, nil
+}
+
+func (u *UsersServiceMock) GetUserProfile(profile user_profiles.UserProfileInterface) (user_profiles.UserProfileInterface, *rest_errors.RestErr) {
+	userProfile := profile.(user_profiles.UserProfile)
+
+	savedProfile, ok := MockProfiles[userProfile.UserID]
+	if !ok {
+		return nil, rest_errors.NewNotFoundError("Profile not found")
+	}
+
+	return savedProfile, nil
+}
+
+func (u *UsersServiceMock) GetProfileByHandle(profile user_profiles.UserProfileInterface) (user_profiles.UserProfileInterface, *rest_errors.RestErr) {
+	userProfile := profile.(user_profiles.UserProfile)
+
+	for _, savedProfile := range MockProfiles {
+		if savedProfile.Handle == user_profiles.NormalizeHandle(userProfile.Handle) {
+			return savedProfile, nil
+		}
+	}
+
+	return nil, rest_errors.NewNotFoundError("Profile not found")
+}
+
+func (u *UsersServiceMock) UpdateUserProfile(profile user_profiles.UserProfileInterface, isPartial bool) (user_profiles.UserProfileInterface, *rest_errors.RestErr) {
+	if !u.CanUpdateProfile {
+		return nil, rest_errors.NewInternalServerError("Error when trying to update user profile")
+	}
+
+	validatedProfile, validateErr := profile.Validate()
+	if validateErr != nil {
+		return nil, validateErr
+	}
+
+	u.UpdatedProfiles = append(u.UpdatedProfiles, validatedProfile.(user_profiles.UserProfile))
+
+	return validatedProfile, nil
+}
+
+func (u *UsersServiceMock) UpdateUserWithProfile(user users.UserInterface, profile user_profiles.UserProfileInterface, isPartial bool) (users.UserInterface, user_profiles.UserProfileInterface, *rest_errors.RestErr) {
+	updatedUser, err := u.UpdateUser(user, isPartial)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	updatedProfile, err := u.UpdateUserProfile(profile, isPartial)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return updatedUser, updatedProfile, nil
+}
+
+func (u *UsersServiceMock) SearchUser(userToSearch users.UserInterface, criteria users.SearchCriteria) (*pagination.Page, *rest_errors.RestErr) {
+	if !u.CanSearch {
+		return nil, rest_errors.NewInternalServerError("Error when trying to search user")
+	}
+
+	u.LastSearch = criteria
+
+	return &pagination.Page{
+		Items: []users.UserInterface{MockDbID[1]},
+		Total: 1,
+		Limit: criteria.Limit,
+	}, nil
+}
+
+func (u *UsersServiceMock) ListUsers(user users.UserInterface, limit int, offset int) ([]users.UserInterface, *rest_errors.RestErr) {
+	var ids []int64
+	for id := range MockDbID {
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+
+	var listedUsers []users.UserInterface
+	for _, id := range ids {
+		listedUsers = append(listedUsers, MockDbID[id])
+	}
+
+	if offset >= len(listedUsers) {
+		return []users.UserInterface{}, nil
+	}
+
+	listedUsers = listedUsers[offset:]
+	if limit < len(listedUsers) {
+		listedUsers = listedUsers[:limit]
+	}
+
+	return listedUsers, nil
+}
+
+func (u *UsersServiceMock) UpdateUserStatus(user users.UserInterface, status string) *rest_errors.RestErr {
+	usr := user.(users.User)
+
+	currentUser, ok := MockDbID[usr.ID]
+	if !ok {
+		return rest_errors.NewNotFoundError("User not found")
+	}
+
+	if !users.CanTransitionStatus(currentUser.Status, status) {
+		return rest_errors.NewBadRequestError(fmt.Sprintf("Cannot change user status from %s to %s", currentUser.Status, status))
+	}
+
+	return nil
+}
+
+func (u *UsersServiceMock) UpdateUserRole(user users.UserInterface) *rest_errors.RestErr {
+	usr := user.(users.User)
+
+	if _, ok := MockDbID[usr.ID]; !ok {
+		return rest_errors.NewNotFoundError("User not found")
+	}
+
+	return nil
+}
+
+func (u *UsersServiceMock) PurgeDeletedUsers(user users.UserInterface, before time.Time) (int64, *rest_errors.RestErr) {
+	return 0, nil
+}
+
+func (u *UsersServiceMock) SendVerificationEmail(user users.UserInterface) *rest_errors.RestErr {
+	u.VerificationsSent = append(u.VerificationsSent, user.(users.User).Email)
+
+	return nil
+}
+
+func (u *UsersServiceMock) VerifyEmail(user users.UserInterface, token string) *rest_errors.RestErr {
+	if !u.CanVerify {
+		return rest_errors.NewBadRequestError("Invalid or expired verification token")
+	}
+
+	return nil
+}
+
+func (u *UsersServiceMock) UpdateUserPassword(user users.UserInterface) *rest_errors.RestErr {
+	usr := user.(users.User)
+
+	if _, ok := MockDbID[usr.ID]; !ok {
+		return rest_errors.NewNotFoundError("User not found")
+	}
+
+	u.UpdatedPasswordsIDs = append(u.UpdatedPasswordsIDs, usr.ID)
+
+	return nil
+}
+
+func (u *UsersServiceMock) SendPasswordResetEmail(user users.UserInterface) *rest_errors.RestErr {
+	u.PasswordResetsSent = append(u.PasswordResetsSent, user.(users.User).Email)
+
+	return nil
+}
+
+func (u *UsersServiceMock) ResetPassword(user users.UserInterface, token string) (users.UserInterface, *rest_errors.RestErr) {
+	if !u.CanResetPassword {
+		return nil, rest_errors.NewBadRequestError("Invalid or expired password reset token")
+	}
+
+	resetUser := MockDbID[1]
+	resetUser.Password = user.(users.User).Password
+
+	u.UpdatedPasswordsIDs = append(u.UpdatedPasswordsIDs, resetUser.ID)
+
+	return resetUser, nil
 }
@@ -0,0 +1,15 @@
+package schema_migrations
+
+const (
+	QueryCreateSchemaMigrations     = "CREATE TABLE IF NOT EXISTS schema_migrations (version INTEGER PRIMARY KEY, name VARCHAR(255) NOT NULL, applied_at TIMESTAMPTZ NOT NULL DEFAULT now());"
+	QueryCreateSchemaMigrationsName = "create-schema-migrations-query"
+
+	QueryGetAppliedMigrations     = "SELECT version, applied_at FROM schema_migrations ORDER BY version;"
+	QueryGetAppliedMigrationsName = "get-applied-migrations-query"
+
+	QueryInsertMigration     = "INSERT INTO schema_migrations (version,name) VALUES ($1,$2);"
+	QueryInsertMigrationName = "insert-migration-query"
+
+	QueryDeleteMigration     = "DELETE FROM schema_migrations WHERE version=$1;"
+	QueryDeleteMigrationName = "delete-migration-query"
+)
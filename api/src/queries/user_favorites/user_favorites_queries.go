@@ -1,8 +1,40 @@
 package user_favorites
 
 const (
-	QueryGetUserFavoritesIds = "SELECT movie_id FROM user_favorites WHERE user_id=$1;"
+	QueryGetUserFavoritesIds = "SELECT movie_id FROM user_favorites WHERE user_id=$1 ORDER BY rank;"
 
-	QueryAddUserFavorite     = "INSERT INTO user_favorites VALUES ($1,$2);"
+	// Changes to a list lock its owner first, so concurrent changes compute ranks one after the other
+	QueryLockUserFavorites     = "SELECT id FROM users WHERE id=$1 FOR UPDATE;"
+	QueryLockUserFavoritesName = "query-lock-user-favorites"
+
+	QueryCountUserFavorites     = "SELECT COUNT(*) FROM user_favorites WHERE user_id=$1;"
+	QueryCountUserFavoritesName = "query-count-user-favorites"
+
+	QueryAddUserFavorite     = "INSERT INTO user_favorites (user_id,movie_id,rank) VALUES ($1,$2,$3);"
 	QueryAddUserFavoriteName = "query-add-user-favorite"
+
+	QueryRemoveUserFavorite     = "DELETE FROM user_favorites WHERE user_id=$1 AND movie_id=$2;"
+	QueryRemoveUserFavoriteName = "query-remove-user-favorite"
+
+	QueryShiftUserFavoritesRanks     = "UPDATE user_favorites SET rank=rank-1 WHERE user_id=$1 AND rank>$2;"
+	QueryShiftUserFavoritesRanksName = "query-shift-user-favorites-ranks"
+
+	QueryReplaceUserFavorite     = "UPDATE user_favorites SET movie_id=$3 WHERE user_id=$1 AND rank=$2;"
+	QueryReplaceUserFavoriteName = "query-replace-user-favorite"
+
+	QueryReorderUserFavorites     = "UPDATE user_favorites AS uf SET rank=o.rank FROM unnest($2::int[]) WITH ORDINALITY AS o(movie_id,rank) WHERE uf.user_id=$1 AND uf.movie_id=o.movie_id;"
+	QueryReorderUserFavoritesName = "query-reorder-user-favorites"
+
+	QueryAddFavoriteEvent     = "INSERT INTO favorite_events (user_id,movie_id,event_type,rank,previous_rank) VALUES ($1,$2,$3,$4,$5);"
+	QueryAddFavoriteEventName = "query-add-favorite-event"
+
+	// Feed queries take the follower ($1). Only lists that followers may see show up in the feed.
+	// The page query also takes whether a cursor is given ($2), the last event id seen ($3) and the page size ($4).
+	favoritesFeedFilter = "FROM favorite_events e JOIN user_follows f ON f.followed_id = e.user_id JOIN users u ON u.id = e.user_id WHERE f.follower_id=$1 AND u.status='active' AND u.favorites_visibility IN ('public','followers')"
+
+	QueryCountFavoritesFeed     = "SELECT COUNT(*) " + favoritesFeedFilter + ";"
+	QueryCountFavoritesFeedName = "query-count-favorites-feed"
+
+	QueryGetFavoritesFeed     = "SELECT e.id, e.user_id, u.first_name, u.last_name, e.movie_id, e.event_type, e.rank, e.previous_rank, to_char(e.date_created, 'YYYY-MM-DD\"T\"HH24:MI:SS') " + favoritesFeedFilter + " AND (NOT $2 OR e.id < $3) ORDER BY e.id DESC LIMIT $4;"
+	QueryGetFavoritesFeedName = "query-get-favorites-feed"
 )
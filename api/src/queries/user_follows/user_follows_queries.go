@@ -0,0 +1,35 @@
+package user_follows
+
+const (
+	// Follow list queries take the listed user ($1). The page queries also take whether a cursor is
+	// given ($2), the cursor follow date ($3) and user id ($4) and the page size ($5).
+	followDate        = "to_char(f.date_created, 'YYYY-MM-DD\"T\"HH24:MI:SS.US')"
+	followUsersSelect = "SELECT u.id, u.first_name, u.last_name, u.favorites_visibility, " + followDate + " FROM user_follows f "
+	followersFilter   = "JOIN users u ON u.id = f.follower_id WHERE f.followed_id=$1 AND u.status='active'"
+	followingFilter   = "JOIN users u ON u.id = f.followed_id WHERE f.follower_id=$1 AND u.status='active'"
+	followsPage       = " AND (NOT $2 OR (" + followDate + ", u.id) < ($3, $4)) ORDER BY " + followDate + " DESC, u.id DESC LIMIT $5;"
+
+	QueryCountFollowableUser     = "SELECT COUNT(*) FROM users WHERE id=$1 AND status='active';"
+	QueryCountFollowableUserName = "count-followable-user-query"
+
+	QueryFollowUser     = "INSERT INTO user_follows (follower_id,followed_id) VALUES ($1,$2) ON CONFLICT DO NOTHING;"
+	QueryFollowUserName = "follow-user-query"
+
+	QueryUnfollowUser     = "DELETE FROM user_follows WHERE follower_id=$1 AND followed_id=$2;"
+	QueryUnfollowUserName = "unfollow-user-query"
+
+	QueryIsFollowing     = "SELECT COUNT(*) FROM user_follows WHERE follower_id=$1 AND followed_id=$2;"
+	QueryIsFollowingName = "is-following-query"
+
+	QueryCountFollowers     = "SELECT COUNT(*) FROM user_follows f " + followersFilter + ";"
+	QueryCountFollowersName = "count-followers-query"
+
+	QueryGetFollowers     = followUsersSelect + followersFilter + followsPage
+	QueryGetFollowersName = "get-followers-query"
+
+	QueryCountFollowing     = "SELECT COUNT(*) FROM user_follows f " + followingFilter + ";"
+	QueryCountFollowingName = "count-following-query"
+
+	QueryGetFollowing     = followUsersSelect + followingFilter + followsPage
+	QueryGetFollowingName = "get-following-query"
+)
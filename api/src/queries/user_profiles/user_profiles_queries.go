@@ -0,0 +1,15 @@
+package user_profiles
+
+const (
+	QueryGetUserProfile     = "SELECT user_id, handle, display_name, bio, avatar_url, favorite_genres FROM user_profiles WHERE user_id=$1;"
+	QueryGetUserProfileName = "get-user-profile-query"
+
+	QueryGetUserProfileByHandle     = "SELECT p.user_id, p.handle, p.display_name, p.bio, p.avatar_url, p.favorite_genres FROM user_profiles p JOIN users u ON u.id = p.user_id WHERE p.handle=$1 AND u.status = 'active';"
+	QueryGetUserProfileByHandleName = "get-user-profile-by-handle-query"
+
+	QueryGetHandleOwner     = "SELECT user_id FROM user_profiles WHERE handle=$1;"
+	QueryGetHandleOwnerName = "get-handle-owner-query"
+
+	QuerySaveUserProfile     = "INSERT INTO user_profiles (user_id,handle,display_name,bio,avatar_url,favorite_genres) VALUES ($1,$2,$3,$4,$5,$6) ON CONFLICT (user_id) DO UPDATE SET handle=EXCLUDED.handle, display_name=EXCLUDED.display_name, bio=EXCLUDED.bio, avatar_url=EXCLUDED.avatar_url, favorite_genres=EXCLUDED.favorite_genres;"
+	QuerySaveUserProfileName = "save-user-profile-query"
+)
@@ -1,21 +1,57 @@
 package users
 
 const (
-	QueryInsertUser     = "INSERT INTO users (first_name,last_name,email,date_created,status,password) VALUES ($1,$2,$3,$4,$5,$6);"
+	QueryInsertUser     = "INSERT INTO users (first_name,last_name,email,date_created,status,password,role) VALUES ($1,$2,$3,$4,$5,$6,$7);"
 	QueryInsertUserName = "insert-user-query"
 
-	QueryGetUser     = "SELECT id, first_name, last_name, email, status, password FROM users WHERE email=$1;"
+	QueryGetUser     = "SELECT id, first_name, last_name, email, status, password, role, favorites_visibility, COALESCE(favorites_share_token, '') FROM users WHERE email=$1;"
 	QueryGetUserName = "get-user-query"
 
-	QueryGetUserById     = "SELECT id, first_name, last_name, email, status, password FROM users WHERE id=$1;"
+	QueryGetUserById     = "SELECT id, first_name, last_name, email, status, password, role, favorites_visibility, COALESCE(favorites_share_token, '') FROM users WHERE id=$1;"
 	QueryGetUserByIdName = "get-user-by-id-query"
 
 	QueryUpdateUser     = "UPDATE users SET first_name=$1, last_name=$2, email=$3 WHERE id=$4;"
 	QueryUpdateUserName = "update-user-query"
 
-	QueryDeleteUser     = "DELETE FROM users WHERE id=$1;"
-	QueryDeleteUserName = "delete-user-query"
+	QueryUpdateUserStatus     = "UPDATE users SET status=$2, deleted_at=$3 WHERE id=$1 AND status=$4;"
+	QueryUpdateUserStatusName = "update-user-status-query"
 
-	QuerySearchUser     = "SELECT id, first_name, last_name, email, status, password FROM users WHERE first_name ILIKE '' || $1 || '%' AND last_name ILIKE '%' || $2 || '%';"
-	QuerySearchUserName = "search-user-query"
+	QueryUpdateUserRole     = "UPDATE users SET role=$2 WHERE id=$1;"
+	QueryUpdateUserRoleName = "update-user-role-query"
+
+	QueryUpdateUserPassword     = "UPDATE users SET password=$2 WHERE id=$1;"
+	QueryUpdateUserPasswordName = "update-user-password-query"
+
+	QueryUpdateFavoritesVisibility     = "UPDATE users SET favorites_visibility=$2, favorites_share_token=NULLIF($3, '') WHERE id=$1;"
+	QueryUpdateFavoritesVisibilityName = "update-favorites-visibility-query"
+
+	// Search queries take the lowercased, LIKE escaped term ($1) and whether it is an admin search ($2),
+	// which also matches emails and lists pending and suspended users.
+	// The keyset variants also take whether a cursor is given ($3), the cursor sort value ($4) and id ($5)
+	// and the page size ($6).
+	searchUsersSelect = "SELECT id, first_name, last_name, email, status, role, favorites_visibility, to_char(date_created, 'YYYY-MM-DD') FROM users "
+	searchUsersFilter = "WHERE status <> 'deleted' AND ($2 OR status = 'active') AND (lower(first_name || ' ' || last_name) LIKE '%' || $1 || '%' OR ($2 AND lower(email) LIKE $1 || '%'))"
+	searchUsersName   = "lower(first_name || ' ' || last_name)"
+	searchUsersDate   = "to_char(date_created, 'YYYY-MM-DD')"
+
+	QueryCountSearchUsers     = "SELECT count(*) FROM users " + searchUsersFilter + ";"
+	QueryCountSearchUsersName = "count-search-users-query"
+
+	QuerySearchUsersByName     = searchUsersSelect + searchUsersFilter + " AND (NOT $3 OR (" + searchUsersName + ", id) > ($4, $5)) ORDER BY " + searchUsersName + ", id LIMIT $6;"
+	QuerySearchUsersByNameName = "search-users-by-name-query"
+
+	QuerySearchUsersByNameDesc     = searchUsersSelect + searchUsersFilter + " AND (NOT $3 OR (" + searchUsersName + ", id) < ($4, $5)) ORDER BY " + searchUsersName + " DESC, id DESC LIMIT $6;"
+	QuerySearchUsersByNameDescName = "search-users-by-name-desc-query"
+
+	QuerySearchUsersByDate     = searchUsersSelect + searchUsersFilter + " AND (NOT $3 OR (" + searchUsersDate + ", id) > ($4, $5)) ORDER BY " + searchUsersDate + ", id LIMIT $6;"
+	QuerySearchUsersByDateName = "search-users-by-date-query"
+
+	QuerySearchUsersByDateDesc     = searchUsersSelect + searchUsersFilter + " AND (NOT $3 OR (" + searchUsersDate + ", id) < ($4, $5)) ORDER BY " + searchUsersDate + " DESC, id DESC LIMIT $6;"
+	QuerySearchUsersByDateDescName = "search-users-by-date-desc-query"
+
+	QueryListUsers     = "SELECT id, first_name, last_name, email, status, role, favorites_visibility FROM users ORDER BY id LIMIT $1 OFFSET $2;"
+	QueryListUsersName = "list-users-query"
+
+	QueryPurgeDeletedUsers     = "DELETE FROM users WHERE status='deleted' AND deleted_at < $1;"
+	QueryPurgeDeletedUsersName = "purge-deleted-users-query"
 )
@@ -1,48 +1,73 @@
 package movies_service
 
 import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ericbg27/top10movies-api/src/datasources/cache"
+	"github.com/ericbg27/top10movies-api/src/datasources/movieprovider"
 	"github.com/ericbg27/top10movies-api/src/domain/movies"
 	"github.com/ericbg27/top10movies-api/src/utils/config"
+	"github.com/ericbg27/top10movies-api/src/utils/logger"
 	"github.com/ericbg27/top10movies-api/src/utils/rest_errors"
 	"github.com/ryanbradynd05/go-tmdb"
 )
 
-type moviesService struct{}
+type moviesService struct {
+	cache    cache.CacheClient
+	provider movieprovider.MovieProvider
+}
 
 type moviesServiceInterface interface {
+	SetupCacheClient(cache.CacheClient)
+	SetupMovieProvider(movieprovider.MovieProvider)
 	SearchMovies(searchOptions map[string]string) (*tmdb.MovieSearchResults, *rest_errors.RestErr)
 	AddMovie(movies.MovieInterface) *rest_errors.RestErr
 	GetMovieFromCache(movies.MovieInterface) (movies.MovieInterface, *rest_errors.RestErr)
 	GetMovieById(int) (*tmdb.Movie, *rest_errors.RestErr)
+	GetMoviesByIds(context.Context, []int) (map[int]tmdb.Movie, map[int]*rest_errors.RestErr)
+	GetMovieCredits(int) (*tmdb.MovieCredits, *rest_errors.RestErr)
+	GetMovieImages(int) (*tmdb.MovieImages, *rest_errors.RestErr)
+}
+
+type movieFetchResult struct {
+	movieId int
+	movie   *tmdb.Movie
+	err     *rest_errors.RestErr
 }
 
 var (
 	MoviesService moviesServiceInterface = &moviesService{}
-
-	tmdbAPI *tmdb.TMDb
 )
 
 const (
 	QueryParam = "query"
 )
 
-func init() {
-	cfg := config.GetConfig()
-
-	tmdbConfig := tmdb.Config{
-		APIKey:   cfg.MovieApi.ApiKey,
-		Proxies:  nil,
-		UseProxy: false,
+func providerError(err error, message string) *rest_errors.RestErr {
+	if errors.Is(err, movieprovider.ErrMovieNotFound) {
+		return rest_errors.NewNotFoundError("Movie not found")
 	}
 
-	tmdbAPI = tmdb.Init(tmdbConfig)
+	logger.Error(message, err)
+
+	return rest_errors.NewInternalServerError(message)
+}
+
+func (m *moviesService) SetupCacheClient(cacheClient cache.CacheClient) {
+	m.cache = cacheClient
+}
+
+func (m *moviesService) SetupMovieProvider(provider movieprovider.MovieProvider) {
+	m.provider = provider
 }
 
 func (m *moviesService) SearchMovies(searchOptions map[string]string) (*tmdb.MovieSearchResults, *rest_errors.RestErr) {
 	movieName := searchOptions[QueryParam]
 	delete(searchOptions, QueryParam)
 
-	result, err := tmdbAPI.SearchMovie(movieName, searchOptions)
+	result, err := m.provider.SearchMovies(movieName, searchOptions)
 	if err != nil {
 		return nil, rest_errors.NewInternalServerError("Failed to search for movie")
 	}
@@ -51,7 +76,7 @@ func (m *moviesService) SearchMovies(searchOptions map[string]string) (*tmdb.Mov
 }
 
 func (m *moviesService) AddMovie(movie movies.MovieInterface) *rest_errors.RestErr {
-	if err := movie.AddMovie(); err != nil {
+	if err := movie.AddMovie(m.cache); err != nil {
 		return err
 	}
 
@@ -59,7 +84,7 @@ func (m *moviesService) AddMovie(movie movies.MovieInterface) *rest_errors.RestE
 }
 
 func (m *moviesService) GetMovieFromCache(movie movies.MovieInterface) (movies.MovieInterface, *rest_errors.RestErr) {
-	savedMovie, err := movie.GetMovie()
+	savedMovie, err := movie.GetMovie(m.cache)
 	if err != nil {
 		return nil, err
 	}
@@ -68,10 +93,95 @@ func (m *moviesService) GetMovieFromCache(movie movies.MovieInterface) (movies.M
 }
 
 func (m *moviesService) GetMovieById(movieId int) (*tmdb.Movie, *rest_errors.RestErr) {
-	result, err := tmdbAPI.GetMovieInfo(movieId, nil)
+	result, err := m.provider.GetMovieById(movieId)
+	if err != nil {
+		return nil, providerError(err, "Failed to get movie information")
+	}
+
+	return result, nil
+}
+
+func (m *moviesService) GetMovieCredits(movieId int) (*tmdb.MovieCredits, *rest_errors.RestErr) {
+	result, err := m.provider.GetMovieCredits(movieId)
+	if err != nil {
+		return nil, providerError(err, "Failed to get movie credits")
+	}
+
+	return result, nil
+}
+
+func (m *moviesService) GetMovieImages(movieId int) (*tmdb.MovieImages, *rest_errors.RestErr) {
+	result, err := m.provider.GetMovieImages(movieId)
 	if err != nil {
-		return nil, rest_errors.NewInternalServerError("Failed to get movie information")
+		return nil, providerError(err, "Failed to get movie images")
 	}
 
 	return result, nil
 }
+
+func (m *moviesService) GetMoviesByIds(ctx context.Context, movieIds []int) (map[int]tmdb.Movie, map[int]*rest_errors.RestErr) {
+	movieApiCfg := config.GetConfig().MovieApi
+
+	ctx, cancel := context.WithTimeout(ctx, movieApiCfg.FetchTimeout)
+	defer cancel()
+
+	results := make(chan movieFetchResult, len(movieIds))
+	semaphore := make(chan struct{}, movieApiCfg.MaxConcurrentFetches)
+
+	for _, movieId := range movieIds {
+		go m.fetchMovie(ctx, movieId, semaphore, results)
+	}
+
+	fetchedMovies := make(map[int]tmdb.Movie)
+	fetchErrors := make(map[int]*rest_errors.RestErr)
+
+	for range movieIds {
+		select {
+		case result := <-results:
+			if result.err != nil {
+				fetchErrors[result.movieId] = result.err
+			} else {
+				fetchedMovies[result.movieId] = *result.movie
+			}
+		case <-ctx.Done():
+			for _, movieId := range movieIds {
+				_, fetched := fetchedMovies[movieId]
+				_, failed := fetchErrors[movieId]
+
+				if !fetched && !failed {
+					fetchErrors[movieId] = rest_errors.NewGatewayTimeoutError("Timed out when trying to get movie information")
+				}
+			}
+
+			return fetchedMovies, fetchErrors
+		}
+	}
+
+	return fetchedMovies, fetchErrors
+}
+
+func (m *moviesService) fetchMovie(ctx context.Context, movieId int, semaphore chan struct{}, results chan<- movieFetchResult) {
+	select {
+	case semaphore <- struct{}{}:
+	case <-ctx.Done():
+		results <- movieFetchResult{movieId: movieId, err: rest_errors.NewGatewayTimeoutError("Timed out when trying to get movie information")}
+
+		return
+	}
+
+	movie, err := m.GetMovieById(movieId)
+	<-semaphore
+
+	results <- movieFetchResult{movieId: movieId, movie: movie, err: err}
+
+	if err != nil {
+		return
+	}
+
+	var movieInfo movies.MovieInfo
+	movieInfo.Movie = *movie
+
+	if addErr := m.AddMovie(movieInfo); addErr != nil {
+		logger.Error(fmt.Sprintf("Error when trying to cache movie %d", movieId), addErr)
+	}
+}
@@ -1,14 +1,72 @@
 package movies_service
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"os"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/ericbg27/top10movies-api/src/datasources/lrucache"
+	"github.com/ericbg27/top10movies-api/src/datasources/movieprovider"
 	movies_mock "github.com/ericbg27/top10movies-api/src/mocks/domain/movies"
+	"github.com/ericbg27/top10movies-api/src/utils/config"
 	"github.com/ryanbradynd05/go-tmdb"
 	"github.com/stretchr/testify/assert"
 )
 
+type stubMovieProvider struct {
+	getMovieById func(movieId int) (*tmdb.Movie, error)
+}
+
+func (s *stubMovieProvider) SearchMovies(query string, options map[string]string) (*tmdb.MovieSearchResults, error) {
+	if query == "" {
+		return nil, errors.New("empty query")
+	}
+
+	return &tmdb.MovieSearchResults{
+		Page:         1,
+		Results:      []tmdb.MovieShort{{ID: 1, Title: query}},
+		TotalResults: 1,
+	}, nil
+}
+
+func (s *stubMovieProvider) GetMovieById(movieId int) (*tmdb.Movie, error) {
+	if s.getMovieById != nil {
+		return s.getMovieById(movieId)
+	}
+
+	return &tmdb.Movie{ID: movieId}, nil
+}
+
+func (s *stubMovieProvider) GetMovieCredits(movieId int) (*tmdb.MovieCredits, error) {
+	if movieId != 1 {
+		return nil, movieprovider.ErrMovieNotFound
+	}
+
+	return &tmdb.MovieCredits{ID: movieId}, nil
+}
+
+func (s *stubMovieProvider) GetMovieImages(movieId int) (*tmdb.MovieImages, error) {
+	if movieId != 1 {
+		return nil, errors.New("provider unavailable")
+	}
+
+	return &tmdb.MovieImages{ID: movieId}, nil
+}
+
+func TestMain(m *testing.M) {
+	cacheClient := &lrucache.LRUCacheClient{Capacity: 100}
+	cacheClient.SetupCacheConnection()
+
+	MoviesService.SetupCacheClient(cacheClient)
+	MoviesService.SetupMovieProvider(&stubMovieProvider{})
+
+	os.Exit(m.Run())
+}
+
 func TestAddMovieSuccess(t *testing.T) {
 	movieToAdd := movies_mock.MovieInfoMock{
 		CanAdd:     true,
@@ -52,3 +110,127 @@ func TestGetMovieFromCacheSuccess(t *testing.T) {
 	assert.EqualValues(t, 1, movie.Movie.ID)
 	assert.EqualValues(t, "Movie Test Title", movie.Movie.Title)
 }
+
+func TestSearchMoviesSuccess(t *testing.T) {
+	result, err := MoviesService.SearchMovies(map[string]string{QueryParam: "Test Movie"})
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, result.TotalResults)
+	assert.EqualValues(t, "Test Movie", result.Results[0].Title)
+}
+
+func TestSearchMoviesFailure(t *testing.T) {
+	result, err := MoviesService.SearchMovies(map[string]string{})
+
+	assert.Nil(t, result)
+	assert.EqualValues(t, http.StatusInternalServerError, err.Status)
+	assert.EqualValues(t, "Failed to search for movie", err.Message)
+}
+
+func TestGetMovieCreditsSuccess(t *testing.T) {
+	result, err := MoviesService.GetMovieCredits(1)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, result.ID)
+}
+
+func TestGetMovieCreditsNotFound(t *testing.T) {
+	result, err := MoviesService.GetMovieCredits(2)
+
+	assert.Nil(t, result)
+	assert.EqualValues(t, http.StatusNotFound, err.Status)
+	assert.EqualValues(t, "Movie not found", err.Message)
+}
+
+func TestGetMovieImagesSuccess(t *testing.T) {
+	result, err := MoviesService.GetMovieImages(1)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, result.ID)
+}
+
+func TestGetMovieImagesFailure(t *testing.T) {
+	result, err := MoviesService.GetMovieImages(2)
+
+	assert.Nil(t, result)
+	assert.EqualValues(t, http.StatusInternalServerError, err.Status)
+	assert.EqualValues(t, "Failed to get movie images", err.Message)
+}
+
+func TestGetMoviesByIdsPartialResults(t *testing.T) {
+	MoviesService.SetupMovieProvider(&stubMovieProvider{getMovieById: func(movieId int) (*tmdb.Movie, error) {
+		if movieId == 2 {
+			return nil, errors.New("movie not found")
+		}
+
+		return &tmdb.Movie{ID: movieId}, nil
+	}})
+	defer MoviesService.SetupMovieProvider(&stubMovieProvider{})
+
+	fetchedMovies, fetchErrors := MoviesService.GetMoviesByIds(context.Background(), []int{1, 2, 3})
+
+	assert.EqualValues(t, 2, len(fetchedMovies))
+	assert.EqualValues(t, 1, fetchedMovies[1].ID)
+	assert.EqualValues(t, 3, fetchedMovies[3].ID)
+	assert.EqualValues(t, 1, len(fetchErrors))
+	assert.EqualValues(t, "Failed to get movie information", fetchErrors[2].Message)
+	assert.EqualValues(t, http.StatusInternalServerError, fetchErrors[2].Status)
+}
+
+func TestGetMoviesByIdsBoundedConcurrency(t *testing.T) {
+	var inFlight, maxInFlight int32
+
+	MoviesService.SetupMovieProvider(&stubMovieProvider{getMovieById: func(movieId int) (*tmdb.Movie, error) {
+		current := atomic.AddInt32(&inFlight, 1)
+		for {
+			observed := atomic.LoadInt32(&maxInFlight)
+			if current <= observed || atomic.CompareAndSwapInt32(&maxInFlight, observed, current) {
+				break
+			}
+		}
+
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+
+		return &tmdb.Movie{ID: movieId}, nil
+	}})
+	defer MoviesService.SetupMovieProvider(&stubMovieProvider{})
+
+	movieIds := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+
+	fetchedMovies, fetchErrors := MoviesService.GetMoviesByIds(context.Background(), movieIds)
+
+	assert.EqualValues(t, len(movieIds), len(fetchedMovies))
+	assert.EqualValues(t, 0, len(fetchErrors))
+	assert.LessOrEqual(t, int(atomic.LoadInt32(&maxInFlight)), config.GetConfig().MovieApi.MaxConcurrentFetches)
+}
+
+func TestGetMoviesByIdsTimeout(t *testing.T) {
+	release := make(chan struct{})
+	finished := make(chan struct{})
+
+	MoviesService.SetupMovieProvider(&stubMovieProvider{getMovieById: func(movieId int) (*tmdb.Movie, error) {
+		if movieId == 1 {
+			return &tmdb.Movie{ID: movieId}, nil
+		}
+
+		<-release
+		defer close(finished)
+
+		return &tmdb.Movie{ID: movieId}, nil
+	}})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	fetchedMovies, fetchErrors := MoviesService.GetMoviesByIds(ctx, []int{1, 2})
+
+	close(release)
+	<-finished
+	MoviesService.SetupMovieProvider(&stubMovieProvider{})
+
+	assert.EqualValues(t, 1, len(fetchedMovies))
+	assert.EqualValues(t, 1, fetchedMovies[1].ID)
+	assert.EqualValues(t, 1, len(fetchErrors))
+	assert.EqualValues(t, http.StatusGatewayTimeout, fetchErrors[2].Status)
+}
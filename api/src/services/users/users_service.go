@@ -1,25 +1,64 @@
 package users_service
 
 import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/ericbg27/top10movies-api/src/datasources/cache"
 	"github.com/ericbg27/top10movies-api/src/datasources/database"
+	"github.com/ericbg27/top10movies-api/src/datasources/mailer"
 	"github.com/ericbg27/top10movies-api/src/domain/user_favorites"
+	"github.com/ericbg27/top10movies-api/src/domain/user_follows"
+	"github.com/ericbg27/top10movies-api/src/domain/user_profiles"
 	"github.com/ericbg27/top10movies-api/src/domain/users"
+	"github.com/ericbg27/top10movies-api/src/utils/config"
+	"github.com/ericbg27/top10movies-api/src/utils/logger"
+	"github.com/ericbg27/top10movies-api/src/utils/pagination"
 	"github.com/ericbg27/top10movies-api/src/utils/rest_errors"
 )
 
 type usersService struct {
-	db database.DatabaseClient
+	db     database.DatabaseClient
+	cache  cache.CacheClient
+	mailer mailer.Mailer
 }
 
 type usersServiceInterface interface {
 	SetupDBClient(database.DatabaseClient)
+	SetupCacheClient(cache.CacheClient)
+	SetupMailer(mailer.Mailer)
 	CreateUser(users.UserInterface) (users.UserInterface, *rest_errors.RestErr)
 	GetUser(users.UserInterface) (users.UserInterface, *rest_errors.RestErr)
+	GetUserById(users.UserInterface) (users.UserInterface, *rest_errors.RestErr)
 	UpdateUser(users.UserInterface, bool) (users.UserInterface, *rest_errors.RestErr)
 	DeleteUser(users.UserInterface) *rest_errors.RestErr
-	GetUserFavorites(user_favorites.UserFavoritesInterface) (user_favorites.UserFavoritesInterface, map[int]bool, *rest_errors.RestErr)
+	GetUserFavorites(users.UserInterface, user_favorites.UserFavoritesInterface, int64, string) (user_favorites.UserFavoritesInterface, map[int]bool, *rest_errors.RestErr)
+	UpdateFavoritesVisibility(users.UserInterface) (users.UserInterface, *rest_errors.RestErr)
 	AddUserFavorite(user_favorites.UserFavoritesInterface) *rest_errors.RestErr
-	SearchUser(users.UserInterface) ([]users.UserInterface, *rest_errors.RestErr)
+	ReorderUserFavorites(user_favorites.UserFavoritesInterface) *rest_errors.RestErr
+	RemoveUserFavorite(user_favorites.UserFavoritesInterface) *rest_errors.RestErr
+	ReplaceUserFavorite(user_favorites.UserFavoritesInterface, int) *rest_errors.RestErr
+	GetFeed(user_favorites.UserFavoritesInterface, int, string) (*pagination.Page, *rest_errors.RestErr)
+	FollowUser(user_follows.UserFollowInterface) *rest_errors.RestErr
+	UnfollowUser(user_follows.UserFollowInterface) *rest_errors.RestErr
+	GetFollowers(user_follows.UserFollowInterface, int, string) (*pagination.Page, *rest_errors.RestErr)
+	GetFollowing(user_follows.UserFollowInterface, int, string) (*pagination.Page, *rest_errors.RestErr)
+	GetUserProfile(user_profiles.UserProfileInterface) (user_profiles.UserProfileInterface, *rest_errors.RestErr)
+	GetProfileByHandle(user_profiles.UserProfileInterface) (user_profiles.UserProfileInterface, *rest_errors.RestErr)
+	UpdateUserProfile(user_profiles.UserProfileInterface, bool) (user_profiles.UserProfileInterface, *rest_errors.RestErr)
+	UpdateUserWithProfile(users.UserInterface, user_profiles.UserProfileInterface, bool) (users.UserInterface, user_profiles.UserProfileInterface, *rest_errors.RestErr)
+	SearchUser(users.UserInterface, users.SearchCriteria) (*pagination.Page, *rest_errors.RestErr)
+	ListUsers(users.UserInterface, int, int) ([]users.UserInterface, *rest_errors.RestErr)
+	UpdateUserStatus(users.UserInterface, string) *rest_errors.RestErr
+	UpdateUserRole(users.UserInterface) *rest_errors.RestErr
+	PurgeDeletedUsers(users.UserInterface, time.Time) (int64, *rest_errors.RestErr)
+	SendVerificationEmail(users.UserInterface) *rest_errors.RestErr
+	VerifyEmail(users.UserInterface, string) *rest_errors.RestErr
+	UpdateUserPassword(users.UserInterface) *rest_errors.RestErr
+	SendPasswordResetEmail(users.UserInterface) *rest_errors.RestErr
+	ResetPassword(users.UserInterface, string) (users.UserInterface, *rest_errors.RestErr)
 }
 
 const (
@@ -34,6 +73,14 @@ func (s *usersService) SetupDBClient(dbClient database.DatabaseClient) {
 	s.db = dbClient
 }
 
+func (s *usersService) SetupCacheClient(cacheClient cache.CacheClient) {
+	s.cache = cacheClient
+}
+
+func (s *usersService) SetupMailer(mailerClient mailer.Mailer) {
+	s.mailer = mailerClient
+}
+
 func (s *usersService) GetUser(user users.UserInterface) (users.UserInterface, *rest_errors.RestErr) {
 	var savedUser users.UserInterface
 	var err *rest_errors.RestErr
@@ -45,6 +92,17 @@ func (s *usersService) GetUser(user users.UserInterface) (users.UserInterface, *
 	return savedUser, nil
 }
 
+func (s *usersService) GetUserById(user users.UserInterface) (users.UserInterface, *rest_errors.RestErr) {
+	var savedUser users.UserInterface
+	var err *rest_errors.RestErr
+
+	if savedUser, err = user.GetById(s.db); err != nil {
+		return nil, err
+	}
+
+	return savedUser, nil
+}
+
 func (s *usersService) CreateUser(user users.UserInterface) (users.UserInterface, *rest_errors.RestErr) {
 	var validatedUser users.UserInterface
 	var err *rest_errors.RestErr
@@ -61,21 +119,57 @@ func (s *usersService) CreateUser(user users.UserInterface) (users.UserInterface
 }
 
 func (s *usersService) UpdateUser(user users.UserInterface, isPartial bool) (users.UserInterface, *rest_errors.RestErr) {
+	return updateUser(user, isPartial, s.db)
+}
+
+func updateUser(user users.UserInterface, isPartial bool, db database.DatabaseClient) (users.UserInterface, *rest_errors.RestErr) {
 	var currentUser users.UserInterface
 	var err *rest_errors.RestErr
 
-	if currentUser, err = user.GetById(s.db); err != nil {
+	if currentUser, err = user.GetById(db); err != nil {
 		return nil, err
 	}
 
 	var updatedUser users.UserInterface
-	if updatedUser, err = currentUser.Update(user, isPartial, s.db); err != nil {
+	if updatedUser, err = currentUser.Update(user, isPartial, db); err != nil {
 		return nil, err
 	}
 
 	return updatedUser, nil
 }
 
+// UpdateUserWithProfile applies both changes in a single transaction, so a rejected profile
+// leaves the user untouched
+func (s *usersService) UpdateUserWithProfile(user users.UserInterface, profile user_profiles.UserProfileInterface, isPartial bool) (users.UserInterface, user_profiles.UserProfileInterface, *rest_errors.RestErr) {
+	var updatedUser users.UserInterface
+	var updatedProfile user_profiles.UserProfileInterface
+	var restErr *rest_errors.RestErr
+
+	err := s.db.WithTx(context.Background(), func(tx database.Transaction) error {
+		txClient := database.InTransaction(tx)
+
+		if updatedUser, restErr = updateUser(user, isPartial, txClient); restErr != nil {
+			return restErr
+		}
+
+		if updatedProfile, restErr = updateUserProfile(profile, isPartial, txClient); restErr != nil {
+			return restErr
+		}
+
+		return nil
+	})
+	if restErr != nil {
+		return nil, nil, restErr
+	}
+
+	if err != nil {
+		logger.Error("Error when trying to update user with profile", err)
+		return nil, nil, rest_errors.NewInternalServerError("Error when trying to update user")
+	}
+
+	return updatedUser, updatedProfile, nil
+}
+
 func (s *usersService) DeleteUser(user users.UserInterface) *rest_errors.RestErr {
 	var currentUser users.UserInterface
 	var err *rest_errors.RestErr
@@ -91,18 +185,43 @@ func (s *usersService) DeleteUser(user users.UserInterface) *rest_errors.RestErr
 	return nil
 }
 
-func (s *usersService) GetUserFavorites(userFavorites user_favorites.UserFavoritesInterface) (user_favorites.UserFavoritesInterface, map[int]bool, *rest_errors.RestErr) {
-	var currentUserFavorites user_favorites.UserFavoritesInterface
-	var cachedIds map[int]bool
-	var err *rest_errors.RestErr
+// Hidden lists are reported as not found so their existence is not revealed
+func (s *usersService) GetUserFavorites(owner users.UserInterface, userFavorites user_favorites.UserFavoritesInterface, viewerID int64, shareToken string) (user_favorites.UserFavoritesInterface, map[int]bool, *rest_errors.RestErr) {
+	savedOwner, err := owner.GetById(s.db)
+	if err != nil {
+		if err.Status == http.StatusNotFound {
+			return nil, nil, rest_errors.NewNotFoundError("Favorites not found")
+		}
+
+		return nil, nil, err
+	}
+
+	canView, err := savedOwner.CanViewFavorites(viewerID, shareToken, s.db)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !canView {
+		return nil, nil, rest_errors.NewNotFoundError("Favorites not found")
+	}
 
-	if currentUserFavorites, cachedIds, err = userFavorites.GetFavorites(s.db); err != nil {
+	currentUserFavorites, cachedIds, err := userFavorites.GetFavorites(s.db, s.cache)
+	if err != nil {
 		return nil, nil, err
 	}
 
 	return currentUserFavorites, cachedIds, nil
 }
 
+func (s *usersService) UpdateFavoritesVisibility(user users.UserInterface) (users.UserInterface, *rest_errors.RestErr) {
+	updatedUser, err := user.UpdateFavoritesVisibility(s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	return updatedUser, nil
+}
+
 func (s *usersService) AddUserFavorite(userFavorites user_favorites.UserFavoritesInterface) *rest_errors.RestErr {
 	if err := userFavorites.AddFavorite(s.db); err != nil {
 		return err
@@ -111,11 +230,226 @@ func (s *usersService) AddUserFavorite(userFavorites user_favorites.UserFavorite
 	return nil
 }
 
-func (s *usersService) SearchUser(userToSearch users.UserInterface) ([]users.UserInterface, *rest_errors.RestErr) {
-	usersFound, searchErr := userToSearch.Search(s.db)
+func (s *usersService) ReorderUserFavorites(userFavorites user_favorites.UserFavoritesInterface) *rest_errors.RestErr {
+	if err := userFavorites.ReorderFavorites(s.db); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *usersService) RemoveUserFavorite(userFavorites user_favorites.UserFavoritesInterface) *rest_errors.RestErr {
+	if err := userFavorites.RemoveFavorite(s.db); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *usersService) ReplaceUserFavorite(userFavorites user_favorites.UserFavoritesInterface, rank int) *rest_errors.RestErr {
+	if err := userFavorites.ReplaceFavorite(rank, s.db); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *usersService) GetFeed(userFavorites user_favorites.UserFavoritesInterface, limit int, cursor string) (*pagination.Page, *rest_errors.RestErr) {
+	feed, err := userFavorites.GetFeed(limit, cursor, s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	return feed, nil
+}
+
+func (s *usersService) FollowUser(follow user_follows.UserFollowInterface) *rest_errors.RestErr {
+	if err := follow.Follow(s.db); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *usersService) UnfollowUser(follow user_follows.UserFollowInterface) *rest_errors.RestErr {
+	if err := follow.Unfollow(s.db); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *usersService) GetFollowers(follow user_follows.UserFollowInterface, limit int, cursor string) (*pagination.Page, *rest_errors.RestErr) {
+	followers, err := follow.GetFollowers(limit, cursor, s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	return followers, nil
+}
+
+func (s *usersService) GetFollowing(follow user_follows.UserFollowInterface, limit int, cursor string) (*pagination.Page, *rest_errors.RestErr) {
+	following, err := follow.GetFollowing(limit, cursor, s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	return following, nil
+}
+
+func (s *usersService) GetUserProfile(profile user_profiles.UserProfileInterface) (user_profiles.UserProfileInterface, *rest_errors.RestErr) {
+	savedProfile, err := profile.Get(s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	return savedProfile, nil
+}
+
+func (s *usersService) GetProfileByHandle(profile user_profiles.UserProfileInterface) (user_profiles.UserProfileInterface, *rest_errors.RestErr) {
+	savedProfile, err := profile.GetByHandle(s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	return savedProfile, nil
+}
+
+func (s *usersService) UpdateUserProfile(profile user_profiles.UserProfileInterface, isPartial bool) (user_profiles.UserProfileInterface, *rest_errors.RestErr) {
+	return updateUserProfile(profile, isPartial, s.db)
+}
+
+func updateUserProfile(profile user_profiles.UserProfileInterface, isPartial bool, db database.DatabaseClient) (user_profiles.UserProfileInterface, *rest_errors.RestErr) {
+	currentProfile, err := profile.Get(db)
+	if err != nil {
+		if err.Status != http.StatusNotFound {
+			return nil, err
+		}
+
+		// A user without a profile yet gets one made of the fields sent in this request
+		currentProfile = profile
+	}
+
+	updatedProfile, err := currentProfile.Update(profile, isPartial, db)
+	if err != nil {
+		return nil, err
+	}
+
+	return updatedProfile, nil
+}
+
+func (s *usersService) SearchUser(userToSearch users.UserInterface, criteria users.SearchCriteria) (*pagination.Page, *rest_errors.RestErr) {
+	usersFound, searchErr := userToSearch.Search(criteria, s.db)
 	if searchErr != nil {
 		return nil, searchErr
 	}
 
 	return usersFound, nil
 }
+
+func (s *usersService) ListUsers(user users.UserInterface, limit int, offset int) ([]users.UserInterface, *rest_errors.RestErr) {
+	usersFound, listErr := user.List(limit, offset, s.db)
+	if listErr != nil {
+		return nil, listErr
+	}
+
+	return usersFound, nil
+}
+
+func (s *usersService) UpdateUserStatus(user users.UserInterface, status string) *rest_errors.RestErr {
+	var currentUser users.UserInterface
+	var err *rest_errors.RestErr
+
+	if currentUser, err = user.GetById(s.db); err != nil {
+		return err
+	}
+
+	if err = currentUser.UpdateStatus(status, s.db); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *usersService) UpdateUserRole(user users.UserInterface) *rest_errors.RestErr {
+	if err := user.UpdateRole(s.db); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *usersService) PurgeDeletedUsers(user users.UserInterface, before time.Time) (int64, *rest_errors.RestErr) {
+	purged, err := user.PurgeDeleted(before, s.db)
+	if err != nil {
+		return 0, err
+	}
+
+	return purged, nil
+}
+
+func tokenLink(baseUrl string, token string) string {
+	link, err := url.Parse(baseUrl)
+	if err != nil {
+		return baseUrl + "?token=" + url.QueryEscape(token)
+	}
+
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	return link.String()
+}
+
+func (s *usersService) SendVerificationEmail(user users.UserInterface) *rest_errors.RestErr {
+	token, err := user.CreateVerificationToken(s.cache)
+	if err != nil {
+		return err
+	}
+
+	if sendErr := s.mailer.Send(user.VerificationMessage(tokenLink(config.GetConfig().Users.VerificationUrl, token))); sendErr != nil {
+		logger.Error("Error when trying to send verification email", sendErr)
+		return rest_errors.NewInternalServerError("Error when trying to send verification email")
+	}
+
+	return nil
+}
+
+func (s *usersService) VerifyEmail(user users.UserInterface, token string) *rest_errors.RestErr {
+	if err := user.Verify(token, s.db, s.cache); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *usersService) UpdateUserPassword(user users.UserInterface) *rest_errors.RestErr {
+	if err := user.UpdatePassword(s.db); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *usersService) SendPasswordResetEmail(user users.UserInterface) *rest_errors.RestErr {
+	token, err := user.CreatePasswordResetToken(s.cache)
+	if err != nil {
+		return err
+	}
+
+	if sendErr := s.mailer.Send(user.PasswordResetMessage(tokenLink(config.GetConfig().Users.PasswordResetUrl, token))); sendErr != nil {
+		logger.Error("Error when trying to send password reset email", sendErr)
+		return rest_errors.NewInternalServerError("Error when trying to send password reset email")
+	}
+
+	return nil
+}
+
+func (s *usersService) ResetPassword(user users.UserInterface, token string) (users.UserInterface, *rest_errors.RestErr) {
+	updatedUser, err := user.ResetPassword(token, s.db, s.cache)
+	if err != nil {
+		return nil, err
+	}
+
+	return updatedUser, nil
+}
@@ -4,13 +4,28 @@ import (
 	"net/http"
 	"os"
 	"testing"
+	"time"
 
+	"github.com/ericbg27/top10movies-api/src/domain/user_favorites"
+	"github.com/ericbg27/top10movies-api/src/domain/users"
+	database_mock "github.com/ericbg27/top10movies-api/src/mocks/database"
+	user_follows_mock "github.com/ericbg27/top10movies-api/src/mocks/domain/user_follows"
+	user_profiles_mock "github.com/ericbg27/top10movies-api/src/mocks/domain/user_profiles"
 	users_mock "github.com/ericbg27/top10movies-api/src/mocks/domain/users"
+	mailer_mock "github.com/ericbg27/top10movies-api/src/mocks/mailer"
 	"github.com/stretchr/testify/assert"
 )
 
+var (
+	mailerMock *mailer_mock.MailerMock
+)
+
 func TestMain(m *testing.M) {
+	mailerMock = &mailer_mock.MailerMock{CanSend: true}
+
 	UsersService = &usersService{}
+	UsersService.SetupMailer(mailerMock)
+
 	os.Exit(m.Run())
 }
 
@@ -202,3 +217,451 @@ func TestDeleteUserDeleteError(t *testing.T) {
 	assert.EqualValues(t, http.StatusInternalServerError, err.Status)
 	assert.EqualValues(t, "internal_server_error", err.Err)
 }
+
+func TestListUsersSuccess(t *testing.T) {
+	var user users_mock.UserMock
+	user.CanGet = true
+
+	result, err := UsersService.ListUsers(user, 10, 0)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, len(result))
+}
+
+func TestListUsersError(t *testing.T) {
+	var user users_mock.UserMock
+	user.CanGet = false
+
+	result, err := UsersService.ListUsers(user, 10, 0)
+
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "Failed to list users", err.Message)
+	assert.EqualValues(t, http.StatusInternalServerError, err.Status)
+}
+
+func TestUpdateUserProfileExisting(t *testing.T) {
+	profile := user_profiles_mock.UserProfileMock{Exists: true, CanGet: true, CanUpdate: true, Bio: "New bio"}
+
+	result, err := UsersService.UpdateUserProfile(profile, true)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, "New bio", result.(user_profiles_mock.UserProfileMock).Bio)
+}
+
+func TestUpdateUserProfileCreatesMissingProfile(t *testing.T) {
+	profile := user_profiles_mock.UserProfileMock{Exists: false, CanGet: true, CanUpdate: true, Handle: "johndoe"}
+
+	result, err := UsersService.UpdateUserProfile(profile, true)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, "johndoe", result.(user_profiles_mock.UserProfileMock).Handle)
+}
+
+func TestUpdateUserProfileGetError(t *testing.T) {
+	profile := user_profiles_mock.UserProfileMock{CanGet: false, CanUpdate: true}
+
+	result, err := UsersService.UpdateUserProfile(profile, true)
+
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "Error when trying to get user profile", err.Message)
+	assert.EqualValues(t, http.StatusInternalServerError, err.Status)
+}
+
+func TestUpdateUserWithProfileSuccess(t *testing.T) {
+	db := &database_mock.DatabaseClientMock{CanBeginTx: true}
+	UsersService.SetupDBClient(db)
+
+	user := users_mock.UserMock{CanGet: true, CanUpdate: true, Valid: true}
+	profile := user_profiles_mock.UserProfileMock{Exists: true, CanGet: true, CanUpdate: true, Bio: "New bio"}
+
+	updatedUser, updatedProfile, err := UsersService.UpdateUserWithProfile(user, profile, true)
+
+	UsersService.SetupDBClient(nil)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, updatedUser)
+	assert.EqualValues(t, "New bio", updatedProfile.(user_profiles_mock.UserProfileMock).Bio)
+	assert.EqualValues(t, 1, db.Committed)
+	assert.EqualValues(t, 0, db.RolledBack)
+}
+
+func TestUpdateUserWithProfileRollsBackUser(t *testing.T) {
+	db := &database_mock.DatabaseClientMock{CanBeginTx: true}
+	UsersService.SetupDBClient(db)
+
+	user := users_mock.UserMock{CanGet: true, CanUpdate: true, Valid: true}
+	profile := user_profiles_mock.UserProfileMock{CanGet: false}
+
+	updatedUser, updatedProfile, err := UsersService.UpdateUserWithProfile(user, profile, true)
+
+	UsersService.SetupDBClient(nil)
+
+	assert.Nil(t, updatedUser)
+	assert.Nil(t, updatedProfile)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "Error when trying to get user profile", err.Message)
+	assert.EqualValues(t, 0, db.Committed)
+	assert.EqualValues(t, 1, db.RolledBack)
+}
+
+func TestUpdateUserWithProfileBeginError(t *testing.T) {
+	UsersService.SetupDBClient(&database_mock.DatabaseClientMock{CanBeginTx: false})
+
+	user := users_mock.UserMock{CanGet: true, CanUpdate: true, Valid: true}
+	profile := user_profiles_mock.UserProfileMock{Exists: true, CanGet: true, CanUpdate: true}
+
+	_, _, err := UsersService.UpdateUserWithProfile(user, profile, true)
+
+	UsersService.SetupDBClient(nil)
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "Error when trying to update user", err.Message)
+	assert.EqualValues(t, http.StatusInternalServerError, err.Status)
+}
+
+func TestGetProfileByHandleNotFound(t *testing.T) {
+	profile := user_profiles_mock.UserProfileMock{Exists: false, CanGet: true}
+
+	result, err := UsersService.GetProfileByHandle(profile)
+
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "Profile not found", err.Message)
+	assert.EqualValues(t, http.StatusNotFound, err.Status)
+}
+
+func TestSearchUserSuccess(t *testing.T) {
+	var user users_mock.UserMock
+	user.CanGet = true
+
+	result, err := UsersService.SearchUser(user, users.SearchCriteria{Sort: users.SortByName, Limit: 10})
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, result.Total)
+	assert.EqualValues(t, 10, result.Limit)
+}
+
+func TestSearchUserError(t *testing.T) {
+	var user users_mock.UserMock
+	user.CanGet = false
+
+	result, err := UsersService.SearchUser(user, users.SearchCriteria{Sort: users.SortByName, Limit: 10})
+
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "Error when trying to search user", err.Message)
+	assert.EqualValues(t, http.StatusInternalServerError, err.Status)
+}
+
+func TestUpdateUserStatusSuccess(t *testing.T) {
+	var user users_mock.UserMock
+	user.CanGet = true
+	user.CanUpdate = true
+
+	err := UsersService.UpdateUserStatus(user, users.StatusSuspended)
+
+	assert.Nil(t, err)
+}
+
+func TestUpdateUserStatusGetError(t *testing.T) {
+	var user users_mock.UserMock
+	user.CanGet = false
+	user.CanUpdate = true
+
+	err := UsersService.UpdateUserStatus(user, users.StatusSuspended)
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "Failed to get user by ID", err.Message)
+}
+
+func TestUpdateUserStatusError(t *testing.T) {
+	var user users_mock.UserMock
+	user.CanGet = true
+	user.CanUpdate = false
+
+	err := UsersService.UpdateUserStatus(user, users.StatusSuspended)
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "Failed to update user status", err.Message)
+	assert.EqualValues(t, http.StatusInternalServerError, err.Status)
+}
+
+func TestUpdateUserRoleSuccess(t *testing.T) {
+	var user users_mock.UserMock
+	user.CanUpdate = true
+
+	err := UsersService.UpdateUserRole(user)
+
+	assert.Nil(t, err)
+}
+
+func TestUpdateUserRoleError(t *testing.T) {
+	var user users_mock.UserMock
+	user.CanUpdate = false
+
+	err := UsersService.UpdateUserRole(user)
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "Failed to update user role", err.Message)
+	assert.EqualValues(t, http.StatusInternalServerError, err.Status)
+}
+
+func TestGetUserFavoritesHidden(t *testing.T) {
+	var owner users_mock.UserMock
+	owner.CanGet = true
+	owner.FavoritesVisible = false
+
+	result, cachedIds, err := UsersService.GetUserFavorites(owner, user_favorites.UserFavorites{UserID: 1}, 2, "")
+
+	assert.Nil(t, result)
+	assert.Nil(t, cachedIds)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "Favorites not found", err.Message)
+	assert.EqualValues(t, http.StatusNotFound, err.Status)
+}
+
+func TestGetUserFavoritesOwnerNotFound(t *testing.T) {
+	var owner users_mock.UserMock
+	owner.Missing = true
+
+	result, cachedIds, err := UsersService.GetUserFavorites(owner, user_favorites.UserFavorites{UserID: 1}, 2, "")
+
+	assert.Nil(t, result)
+	assert.Nil(t, cachedIds)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "Favorites not found", err.Message)
+	assert.EqualValues(t, http.StatusNotFound, err.Status)
+}
+
+func TestGetUserFavoritesOwnerError(t *testing.T) {
+	var owner users_mock.UserMock
+	owner.CanGet = false
+
+	result, _, err := UsersService.GetUserFavorites(owner, user_favorites.UserFavorites{UserID: 1}, 0, "")
+
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "Failed to get user by ID", err.Message)
+}
+
+func TestUpdateFavoritesVisibilitySuccess(t *testing.T) {
+	var user users_mock.UserMock
+	user.CanUpdate = true
+
+	result, err := UsersService.UpdateFavoritesVisibility(user)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, result)
+}
+
+func TestUpdateFavoritesVisibilityError(t *testing.T) {
+	var user users_mock.UserMock
+	user.CanUpdate = false
+
+	result, err := UsersService.UpdateFavoritesVisibility(user)
+
+	assert.Nil(t, result)
+	assert.EqualValues(t, "Error when trying to update favorites visibility", err.Message)
+	assert.EqualValues(t, http.StatusInternalServerError, err.Status)
+}
+
+func TestFollowUserSuccess(t *testing.T) {
+	err := UsersService.FollowUser(user_follows_mock.UserFollowMock{CanFollow: true})
+
+	assert.Nil(t, err)
+}
+
+func TestFollowUserError(t *testing.T) {
+	err := UsersService.FollowUser(user_follows_mock.UserFollowMock{CanFollow: false})
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "Error when trying to follow user", err.Message)
+	assert.EqualValues(t, http.StatusInternalServerError, err.Status)
+}
+
+func TestUnfollowUserNotFollowing(t *testing.T) {
+	err := UsersService.UnfollowUser(user_follows_mock.UserFollowMock{Following: false})
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "User is not being followed", err.Message)
+	assert.EqualValues(t, http.StatusNotFound, err.Status)
+}
+
+func TestGetFollowersSuccess(t *testing.T) {
+	followers, err := UsersService.GetFollowers(user_follows_mock.UserFollowMock{CanList: true}, 10, "")
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, followers.Total)
+	assert.EqualValues(t, 1, len(followers.Items.([]users.UserInterface)))
+}
+
+func TestGetFollowingError(t *testing.T) {
+	following, err := UsersService.GetFollowing(user_follows_mock.UserFollowMock{CanList: false}, 10, "")
+
+	assert.Nil(t, following)
+	assert.EqualValues(t, "Error when trying to list follows", err.Message)
+}
+
+func TestGetUserByIdSuccess(t *testing.T) {
+	var user users_mock.UserMock
+	user.CanGet = true
+
+	result, err := UsersService.GetUserById(user)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, "Current Name", result.(users_mock.UserMock).FirstName)
+}
+
+func TestPurgeDeletedUsersSuccess(t *testing.T) {
+	var user users_mock.UserMock
+	user.CanDelete = true
+
+	purged, err := UsersService.PurgeDeletedUsers(user, time.Now())
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, purged)
+}
+
+func TestPurgeDeletedUsersError(t *testing.T) {
+	var user users_mock.UserMock
+	user.CanDelete = false
+
+	purged, err := UsersService.PurgeDeletedUsers(user, time.Now())
+
+	assert.EqualValues(t, 0, purged)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "Failed to purge deleted users", err.Message)
+}
+
+func TestSendVerificationEmailSuccess(t *testing.T) {
+	var user users_mock.UserMock
+	user.CanSave = true
+	user.Email = "johndoe@gmail.com"
+
+	sent := len(mailerMock.Sent)
+
+	err := UsersService.SendVerificationEmail(user)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, sent+1, len(mailerMock.Sent))
+	assert.EqualValues(t, "johndoe@gmail.com", mailerMock.Sent[sent].To)
+	assert.EqualValues(t, "http://localhost:8080/verify?token=verification_token", mailerMock.Sent[sent].Body)
+}
+
+func TestSendVerificationEmailTokenError(t *testing.T) {
+	var user users_mock.UserMock
+	user.CanSave = false
+
+	err := UsersService.SendVerificationEmail(user)
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "Failed to create verification token", err.Message)
+}
+
+func TestSendVerificationEmailSendError(t *testing.T) {
+	var user users_mock.UserMock
+	user.CanSave = true
+
+	mailerMock.CanSend = false
+
+	err := UsersService.SendVerificationEmail(user)
+
+	mailerMock.CanSend = true
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "Error when trying to send verification email", err.Message)
+	assert.EqualValues(t, http.StatusInternalServerError, err.Status)
+}
+
+func TestVerifyEmailSuccess(t *testing.T) {
+	var user users_mock.UserMock
+	user.CanUpdate = true
+
+	err := UsersService.VerifyEmail(user, "verification_token")
+
+	assert.Nil(t, err)
+}
+
+func TestVerifyEmailError(t *testing.T) {
+	var user users_mock.UserMock
+	user.CanUpdate = false
+
+	err := UsersService.VerifyEmail(user, "verification_token")
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "Invalid or expired verification token", err.Message)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status)
+}
+
+func TestUpdateUserPasswordSuccess(t *testing.T) {
+	var user users_mock.UserMock
+	user.CanUpdate = true
+
+	err := UsersService.UpdateUserPassword(user)
+
+	assert.Nil(t, err)
+}
+
+func TestUpdateUserPasswordError(t *testing.T) {
+	var user users_mock.UserMock
+	user.CanUpdate = false
+
+	err := UsersService.UpdateUserPassword(user)
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "Failed to update user password", err.Message)
+}
+
+func TestSendPasswordResetEmailSuccess(t *testing.T) {
+	var user users_mock.UserMock
+	user.CanSave = true
+	user.Email = "johndoe@gmail.com"
+
+	sent := len(mailerMock.Sent)
+
+	err := UsersService.SendPasswordResetEmail(user)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, sent+1, len(mailerMock.Sent))
+	assert.EqualValues(t, "johndoe@gmail.com", mailerMock.Sent[sent].To)
+	assert.EqualValues(t, "http://localhost:8080/password/reset?token=reset_token", mailerMock.Sent[sent].Body)
+}
+
+func TestSendPasswordResetEmailSendError(t *testing.T) {
+	var user users_mock.UserMock
+	user.CanSave = true
+
+	mailerMock.CanSend = false
+
+	err := UsersService.SendPasswordResetEmail(user)
+
+	mailerMock.CanSend = true
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "Error when trying to send password reset email", err.Message)
+	assert.EqualValues(t, http.StatusInternalServerError, err.Status)
+}
+
+func TestResetPasswordSuccess(t *testing.T) {
+	var user users_mock.UserMock
+	user.CanUpdate = true
+	user.Email = "johndoe@gmail.com"
+
+	result, err := UsersService.ResetPassword(user, "reset_token")
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, "johndoe@gmail.com", result.(users_mock.UserMock).Email)
+}
+
+func TestResetPasswordError(t *testing.T) {
+	var user users_mock.UserMock
+	user.CanUpdate = false
+
+	result, err := UsersService.ResetPassword(user, "reset_token")
+
+	assert.Nil(t, result)
+	assert.EqualValues(t, "Invalid or expired password reset token", err.Message)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status)
+}
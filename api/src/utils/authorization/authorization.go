@@ -1,20 +1,25 @@
 package authorization
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
-	redisdb "github.com/ericbg27/top10movies-api/src/datasources/redis"
+	"github.com/ericbg27/top10movies-api/src/datasources/cache"
 	"github.com/gofrs/uuid"
 	"github.com/golang-jwt/jwt"
 )
 
 type AccessDetails struct {
 	accessUuid string
+	familyUuid string
 	userId     uint64
+	role       string
 }
 
 type TokenDetails struct {
@@ -22,22 +27,55 @@ type TokenDetails struct {
 	RefreshToken string
 	AccessUuid   string
 	RefreshUuid  string
+	FamilyUuid   string
 	AtExpires    int64
 	RtExpires    int64
 }
 
+type Session struct {
+	FamilyUuid  string `json:"family_uuid"`
+	UserId      int64  `json:"user_id"`
+	Role        string `json:"role"`
+	AccessUuid  string `json:"access_uuid"`
+	RefreshUuid string `json:"refresh_uuid"`
+	CreatedAt   int64  `json:"created_at"`
+	UserAgent   string `json:"user_agent"`
+}
+
+type SessionInfo struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UserAgent string    `json:"user_agent"`
+}
+
 type AuthorizationManagerInterface interface {
-	CreateToken(int64) (*TokenDetails, error)
-	FetchAuth(bearToken string) (uint64, error)
+	SetupCacheClient(cache.CacheClient)
+	CreateToken(userId int64, role string, userAgent string) (*TokenDetails, error)
+	RefreshToken(refreshToken string) (*TokenDetails, error)
+	RefreshTokenUserID(refreshToken string) (int64, error)
+	FetchAuth(bearToken string) (uint64, string, error)
+	Logout(bearToken string) error
+	RevokeAllSessions(userId int64) error
+	ListSessions(userId int64) ([]SessionInfo, error)
 }
 
 type AuthorizationManager struct {
 	accessSecret  string
 	refreshSecret string
+	cache         cache.CacheClient
 }
 
 var (
 	AuthManager AuthorizationManagerInterface = &AuthorizationManager{}
+
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reused")
+	ErrInvalidAccessToken  = errors.New("invalid access token")
+)
+
+const (
+	sessionKeyPrefix      = "session:"
+	userSessionsKeyPrefix = "user_sessions:"
 )
 
 // TODO: Get environment variable names from config
@@ -46,9 +84,37 @@ func init() {
 	AuthManager.(*AuthorizationManager).refreshSecret = os.Getenv("TOP10MOVIES_REFRESH_SECRET")
 }
 
-func (a AuthorizationManager) CreateToken(userId int64) (*TokenDetails, error) {
+func (a *AuthorizationManager) SetupCacheClient(cacheClient cache.CacheClient) {
+	a.cache = cacheClient
+}
+
+func sessionKey(familyUuid string) string {
+	return sessionKeyPrefix + familyUuid
+}
+
+func userSessionsKey(userId int64) string {
+	return userSessionsKeyPrefix + strconv.FormatInt(userId, 10)
+}
+
+func (a AuthorizationManager) CreateToken(userId int64, role string, userAgent string) (*TokenDetails, error) {
+	newUuid, _ := uuid.NewV4()
+
+	session := &Session{
+		FamilyUuid: newUuid.String(),
+		UserId:     userId,
+		Role:       role,
+		CreatedAt:  time.Now().Unix(),
+		UserAgent:  userAgent,
+	}
+
+	return a.issueTokens(session)
+}
+
+func (a AuthorizationManager) issueTokens(session *Session) (*TokenDetails, error) {
 	tokenInfo := &TokenDetails{}
 
+	tokenInfo.FamilyUuid = session.FamilyUuid
+
 	tokenInfo.AtExpires = time.Now().Add(time.Minute * 15).Unix()
 	newUuid, _ := uuid.NewV4()
 	tokenInfo.AccessUuid = newUuid.String()
@@ -62,7 +128,9 @@ func (a AuthorizationManager) CreateToken(userId int64) (*TokenDetails, error) {
 	atClaims := jwt.MapClaims{}
 	atClaims["authorized"] = true
 	atClaims["access_uuid"] = tokenInfo.AccessUuid
-	atClaims["user_id"] = userId
+	atClaims["family_uuid"] = tokenInfo.FamilyUuid
+	atClaims["user_id"] = session.UserId
+	atClaims["role"] = session.Role
 	atClaims["exp"] = tokenInfo.AtExpires
 
 	at := jwt.NewWithClaims(jwt.SigningMethodHS256, atClaims)
@@ -73,7 +141,8 @@ func (a AuthorizationManager) CreateToken(userId int64) (*TokenDetails, error) {
 
 	rtClaims := jwt.MapClaims{}
 	rtClaims["refresh_uuid"] = tokenInfo.RefreshUuid
-	rtClaims["user_id"] = userId
+	rtClaims["family_uuid"] = tokenInfo.FamilyUuid
+	rtClaims["user_id"] = session.UserId
 	rtClaims["exp"] = tokenInfo.RtExpires
 
 	rt := jwt.NewWithClaims(jwt.SigningMethodHS256, rtClaims)
@@ -82,7 +151,10 @@ func (a AuthorizationManager) CreateToken(userId int64) (*TokenDetails, error) {
 		return nil, err
 	}
 
-	err = a.saveTokenMetadata(userId, tokenInfo)
+	session.AccessUuid = tokenInfo.AccessUuid
+	session.RefreshUuid = tokenInfo.RefreshUuid
+
+	err = a.saveTokenMetadata(session, tokenInfo)
 	if err != nil {
 		return nil, err
 	}
@@ -90,24 +162,227 @@ func (a AuthorizationManager) CreateToken(userId int64) (*TokenDetails, error) {
 	return tokenInfo, nil
 }
 
-func (a AuthorizationManager) saveTokenMetadata(userId int64, tokenInfo *TokenDetails) error {
+func (a AuthorizationManager) saveTokenMetadata(session *Session, tokenInfo *TokenDetails) error {
 	at := time.Unix(tokenInfo.AtExpires, 0)
 	rt := time.Unix(tokenInfo.RtExpires, 0)
 	now := time.Now()
 
-	errAccess := redisdb.Client.Set(tokenInfo.AccessUuid, strconv.Itoa(int(userId)), at.Sub(now)).Err()
+	errAccess := a.cache.Set(tokenInfo.AccessUuid, strconv.Itoa(int(session.UserId)), at.Sub(now))
 	if errAccess != nil {
 		return errAccess
 	}
 
-	errRefresh := redisdb.Client.Set(tokenInfo.RefreshUuid, strconv.Itoa(int(userId)), rt.Sub(now)).Err()
+	errRefresh := a.cache.Set(tokenInfo.RefreshUuid, strconv.Itoa(int(session.UserId)), rt.Sub(now))
 	if errRefresh != nil {
 		return errRefresh
 	}
 
+	sessionData, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	if err := a.cache.Set(sessionKey(session.FamilyUuid), sessionData, rt.Sub(now)); err != nil {
+		return err
+	}
+
+	// Every rotation extends the index along with the session, so a session that is
+	// refreshed for longer than a refresh token lifetime is still found on revocation
+	return a.cache.SAdd(userSessionsKey(session.UserId), rt.Sub(now), session.FamilyUuid)
+}
+
+func (a AuthorizationManager) getSession(familyUuid string) (*Session, error) {
+	sessionData, err := a.cache.Get(sessionKey(familyUuid))
+	if err != nil {
+		return nil, err
+	}
+
+	var session Session
+	if err := json.Unmarshal([]byte(sessionData), &session); err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+func (a AuthorizationManager) revokeSession(session *Session) error {
+	if _, err := a.cache.Del(session.AccessUuid, session.RefreshUuid, sessionKey(session.FamilyUuid)); err != nil {
+		return err
+	}
+
+	return a.cache.SRem(userSessionsKey(session.UserId), session.FamilyUuid)
+}
+
+// activeSessions returns the sessions of the user oldest first, dropping from the index
+// the families whose session already expired
+func (a AuthorizationManager) activeSessions(userId int64) ([]*Session, error) {
+	familyUuids, err := a.cache.SMembers(userSessionsKey(userId))
+	if err != nil || len(familyUuids) == 0 {
+		return nil, err
+	}
+
+	keys := make([]string, len(familyUuids))
+	for index, familyUuid := range familyUuids {
+		keys[index] = sessionKey(familyUuid)
+	}
+
+	sessionsData, err := a.cache.MGet(keys...)
+	if err != nil {
+		return nil, err
+	}
+
+	var sessions []*Session
+	var expired []string
+	for index, key := range keys {
+		sessionData, ok := sessionsData[key]
+		if !ok {
+			expired = append(expired, familyUuids[index])
+			continue
+		}
+
+		var session Session
+		if err := json.Unmarshal([]byte(sessionData), &session); err != nil {
+			return nil, err
+		}
+
+		sessions = append(sessions, &session)
+	}
+
+	if len(expired) > 0 {
+		if err := a.cache.SRem(userSessionsKey(userId), expired...); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].CreatedAt < sessions[j].CreatedAt
+	})
+
+	return sessions, nil
+}
+
+func (a AuthorizationManager) Logout(bearToken string) error {
+	accessDetails, err := a.extractTokenMetadata(bearToken)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidAccessToken, err)
+	}
+
+	session, err := a.getSession(accessDetails.familyUuid)
+	if errors.Is(err, cache.ErrCacheMiss) {
+		return ErrInvalidAccessToken
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if session.AccessUuid != accessDetails.accessUuid {
+		return ErrInvalidAccessToken
+	}
+
+	return a.revokeSession(session)
+}
+
+func (a AuthorizationManager) RevokeAllSessions(userId int64) error {
+	sessions, err := a.activeSessions(userId)
+	if err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		if err := a.revokeSession(session); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+func (a AuthorizationManager) ListSessions(userId int64) ([]SessionInfo, error) {
+	sessions, err := a.activeSessions(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionsInfo := make([]SessionInfo, 0, len(sessions))
+	for _, session := range sessions {
+		sessionsInfo = append(sessionsInfo, SessionInfo{
+			ID:        session.FamilyUuid,
+			CreatedAt: time.Unix(session.CreatedAt, 0).UTC(),
+			UserAgent: session.UserAgent,
+		})
+	}
+
+	return sessionsInfo, nil
+}
+
+func (a AuthorizationManager) RefreshToken(refreshToken string) (*TokenDetails, error) {
+	claims, err := a.parseToken(refreshToken, a.refreshSecret)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRefreshToken, err)
+	}
+
+	refreshUuid, ok := claims["refresh_uuid"].(string)
+	if !ok {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	familyUuid, ok := claims["family_uuid"].(string)
+	if !ok {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	session, err := a.getSession(familyUuid)
+	if errors.Is(err, cache.ErrCacheMiss) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if session.RefreshUuid != refreshUuid {
+		if err := a.revokeSession(session); err != nil {
+			return nil, err
+		}
+
+		return nil, ErrRefreshTokenReused
+	}
+
+	deleted, err := a.cache.Del(refreshUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	if deleted == 0 {
+		if err := a.revokeSession(session); err != nil {
+			return nil, err
+		}
+
+		return nil, ErrRefreshTokenReused
+	}
+
+	if _, err := a.cache.Del(session.AccessUuid); err != nil {
+		return nil, err
+	}
+
+	return a.issueTokens(session)
+}
+
+func (a AuthorizationManager) RefreshTokenUserID(refreshToken string) (int64, error) {
+	claims, err := a.parseToken(refreshToken, a.refreshSecret)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidRefreshToken, err)
+	}
+
+	userId, err := strconv.ParseInt(fmt.Sprintf("%.f", claims["user_id"]), 10, 64)
+	if err != nil {
+		return 0, ErrInvalidRefreshToken
+	}
+
+	return userId, nil
+}
+
 func (a AuthorizationManager) extractToken(bearToken string) string {
 	bearTokenArgs := strings.Split(bearToken, " ")
 	if len(bearTokenArgs) == 2 {
@@ -117,67 +392,66 @@ func (a AuthorizationManager) extractToken(bearToken string) string {
 	return ""
 }
 
-func (a AuthorizationManager) verifyToken(bearToken string) (*jwt.Token, error) {
-	tokenString := a.extractToken(bearToken)
-
+func (a AuthorizationManager) parseToken(tokenString string, secret string) (jwt.MapClaims, error) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
 
-		return []byte(a.accessSecret), nil
+		return []byte(secret), nil
 	})
 
 	if err != nil {
 		return nil, err
 	}
 
-	if _, ok := token.Claims.(jwt.Claims); !ok && !token.Valid {
-		return nil, err
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, errors.New("invalid token")
 	}
 
-	return token, nil
+	return claims, nil
 }
 
 func (a AuthorizationManager) extractTokenMetadata(bearToken string) (*AccessDetails, error) {
-	token, err := a.verifyToken(bearToken)
+	claims, err := a.parseToken(a.extractToken(bearToken), a.accessSecret)
 	if err != nil {
 		return nil, err
 	}
 
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if ok && token.Valid {
-		accessUuid, ok := claims["access_uuid"].(string)
-		if !ok {
-			return nil, err
-		}
+	accessUuid, ok := claims["access_uuid"].(string)
+	if !ok {
+		return nil, errors.New("access token has no access uuid")
+	}
 
-		userId, err := strconv.ParseUint(fmt.Sprintf("%.f", claims["user_id"]), 10, 64)
-		if err != nil {
-			return nil, err
-		}
+	familyUuid, _ := claims["family_uuid"].(string)
+	role, _ := claims["role"].(string)
 
-		return &AccessDetails{
-			accessUuid: accessUuid,
-			userId:     userId,
-		}, nil
+	userId, err := strconv.ParseUint(fmt.Sprintf("%.f", claims["user_id"]), 10, 64)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil, err
+	return &AccessDetails{
+		accessUuid: accessUuid,
+		familyUuid: familyUuid,
+		userId:     userId,
+		role:       role,
+	}, nil
 }
 
-func (a AuthorizationManager) FetchAuth(bearToken string) (uint64, error) {
+func (a AuthorizationManager) FetchAuth(bearToken string) (uint64, string, error) {
 	accessDetails, err := a.extractTokenMetadata(bearToken)
 	if err != nil {
-		return 0, err
+		return 0, "", err
 	}
 
-	userId, err := redisdb.Client.Get(accessDetails.accessUuid).Result()
+	userId, err := a.cache.Get(accessDetails.accessUuid)
 	if err != nil {
-		return 0, err
+		return 0, "", err
 	}
 
 	userID, _ := strconv.ParseUint(userId, 10, 64)
 
-	return userID, nil
+	return userID, accessDetails.role, nil
 }
@@ -0,0 +1,230 @@
+package authorization
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ericbg27/top10movies-api/src/datasources/cache"
+	"github.com/ericbg27/top10movies-api/src/datasources/lrucache"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	manager *AuthorizationManager
+)
+
+func TestMain(m *testing.M) {
+	cacheClient := &lrucache.LRUCacheClient{Capacity: 100}
+	cacheClient.SetupCacheConnection()
+
+	manager = &AuthorizationManager{
+		accessSecret:  "access_secret",
+		refreshSecret: "refresh_secret",
+	}
+	manager.SetupCacheClient(cacheClient)
+
+	os.Exit(m.Run())
+}
+
+func TestCreateTokenAndFetchAuth(t *testing.T) {
+	token, err := manager.CreateToken(1, "admin", "test-agent")
+	assert.Nil(t, err)
+
+	userId, role, err := manager.FetchAuth("Bearer " + token.AccessToken)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, userId)
+	assert.EqualValues(t, "admin", role)
+}
+
+func TestFetchAuthRejectsRefreshToken(t *testing.T) {
+	token, err := manager.CreateToken(1, "user", "test-agent")
+	assert.Nil(t, err)
+
+	_, _, err = manager.FetchAuth("Bearer " + token.RefreshToken)
+
+	assert.NotNil(t, err)
+}
+
+func TestRefreshTokenRotation(t *testing.T) {
+	token, err := manager.CreateToken(2, "moderator", "test-agent")
+	assert.Nil(t, err)
+
+	refreshed, err := manager.RefreshToken(token.RefreshToken)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, token.FamilyUuid, refreshed.FamilyUuid)
+	assert.NotEqual(t, token.RefreshToken, refreshed.RefreshToken)
+
+	_, _, err = manager.FetchAuth("Bearer " + token.AccessToken)
+	assert.True(t, errors.Is(err, cache.ErrCacheMiss))
+
+	userId, role, err := manager.FetchAuth("Bearer " + refreshed.AccessToken)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 2, userId)
+	assert.EqualValues(t, "moderator", role)
+}
+
+func TestRefreshTokenReuseRevokesFamily(t *testing.T) {
+	token, err := manager.CreateToken(3, "user", "test-agent")
+	assert.Nil(t, err)
+
+	refreshed, err := manager.RefreshToken(token.RefreshToken)
+	assert.Nil(t, err)
+
+	_, err = manager.RefreshToken(token.RefreshToken)
+	assert.True(t, errors.Is(err, ErrRefreshTokenReused))
+
+	_, _, err = manager.FetchAuth("Bearer " + refreshed.AccessToken)
+	assert.NotNil(t, err)
+
+	_, err = manager.RefreshToken(refreshed.RefreshToken)
+	assert.True(t, errors.Is(err, ErrInvalidRefreshToken))
+}
+
+func TestRefreshTokenInvalidSignature(t *testing.T) {
+	token, err := manager.CreateToken(4, "user", "test-agent")
+	assert.Nil(t, err)
+
+	_, err = manager.RefreshToken(token.AccessToken)
+
+	assert.True(t, errors.Is(err, ErrInvalidRefreshToken))
+}
+
+func TestLogoutRevokesCurrentSession(t *testing.T) {
+	token, err := manager.CreateToken(5, "user", "test-agent")
+	assert.Nil(t, err)
+
+	other, err := manager.CreateToken(5, "user", "other-agent")
+	assert.Nil(t, err)
+
+	err = manager.Logout("Bearer " + token.AccessToken)
+	assert.Nil(t, err)
+
+	_, _, err = manager.FetchAuth("Bearer " + token.AccessToken)
+	assert.NotNil(t, err)
+
+	_, err = manager.RefreshToken(token.RefreshToken)
+	assert.True(t, errors.Is(err, ErrInvalidRefreshToken))
+
+	userId, _, err := manager.FetchAuth("Bearer " + other.AccessToken)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 5, userId)
+
+	sessions, err := manager.ListSessions(5)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, len(sessions))
+	assert.EqualValues(t, other.FamilyUuid, sessions[0].ID)
+}
+
+func TestLogoutInvalidToken(t *testing.T) {
+	err := manager.Logout("Bearer invalid")
+
+	assert.True(t, errors.Is(err, ErrInvalidAccessToken))
+}
+
+func TestLogoutRotatedAccessToken(t *testing.T) {
+	token, err := manager.CreateToken(6, "user", "test-agent")
+	assert.Nil(t, err)
+
+	_, err = manager.RefreshToken(token.RefreshToken)
+	assert.Nil(t, err)
+
+	err = manager.Logout("Bearer " + token.AccessToken)
+
+	assert.True(t, errors.Is(err, ErrInvalidAccessToken))
+}
+
+func TestListSessions(t *testing.T) {
+	first, err := manager.CreateToken(7, "user", "first-agent")
+	assert.Nil(t, err)
+
+	second, err := manager.CreateToken(7, "user", "second-agent")
+	assert.Nil(t, err)
+
+	_, err = manager.RefreshToken(second.RefreshToken)
+	assert.Nil(t, err)
+
+	sessions, err := manager.ListSessions(7)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, 2, len(sessions))
+	assert.EqualValues(t, first.FamilyUuid, sessions[0].ID)
+	assert.EqualValues(t, "first-agent", sessions[0].UserAgent)
+	assert.EqualValues(t, second.FamilyUuid, sessions[1].ID)
+	assert.EqualValues(t, "second-agent", sessions[1].UserAgent)
+	assert.False(t, sessions[0].CreatedAt.IsZero())
+}
+
+func TestRevokeAllSessions(t *testing.T) {
+	first, err := manager.CreateToken(8, "user", "first-agent")
+	assert.Nil(t, err)
+
+	second, err := manager.CreateToken(8, "user", "second-agent")
+	assert.Nil(t, err)
+
+	err = manager.RevokeAllSessions(8)
+	assert.Nil(t, err)
+
+	_, _, err = manager.FetchAuth("Bearer " + first.AccessToken)
+	assert.NotNil(t, err)
+
+	_, err = manager.RefreshToken(second.RefreshToken)
+	assert.True(t, errors.Is(err, ErrInvalidRefreshToken))
+
+	sessions, err := manager.ListSessions(8)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 0, len(sessions))
+}
+
+func TestRevokeAllSessionsAfterRotatingPastIndexExpiry(t *testing.T) {
+	now := time.Now()
+	cacheClient := &lrucache.LRUCacheClient{
+		Capacity: 100,
+		Now: func() time.Time {
+			return now
+		},
+	}
+	cacheClient.SetupCacheConnection()
+
+	clockManager := &AuthorizationManager{
+		accessSecret:  "access_secret",
+		refreshSecret: "refresh_secret",
+	}
+	clockManager.SetupCacheClient(cacheClient)
+
+	token, err := clockManager.CreateToken(10, "user", "test-agent")
+	assert.Nil(t, err)
+
+	// Refreshing every 6 days keeps the session alive well past the first 7 days
+	for day := 6; day <= 18; day += 6 {
+		now = now.Add(6 * 24 * time.Hour)
+
+		token, err = clockManager.RefreshToken(token.RefreshToken)
+		assert.Nil(t, err, day)
+	}
+
+	sessions, err := clockManager.ListSessions(10)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, len(sessions))
+
+	err = clockManager.RevokeAllSessions(10)
+	assert.Nil(t, err)
+
+	_, err = clockManager.RefreshToken(token.RefreshToken)
+	assert.True(t, errors.Is(err, ErrInvalidRefreshToken))
+}
+
+func TestRefreshTokenUserID(t *testing.T) {
+	token, err := manager.CreateToken(9, "user", "test-agent")
+	assert.Nil(t, err)
+
+	userId, err := manager.RefreshTokenUserID(token.RefreshToken)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 9, userId)
+
+	_, err = manager.RefreshTokenUserID(token.AccessToken)
+	assert.True(t, errors.Is(err, ErrInvalidRefreshToken))
+}
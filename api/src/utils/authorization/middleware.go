@@ -0,0 +1,91 @@
+package authorization
+
+import (
+	"strconv"
+
+	"github.com/ericbg27/top10movies-api/src/utils/rest_errors"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	UserIDKey   = "authorized_user_id"
+	UserRoleKey = "authorized_user_role"
+)
+
+func Authenticate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, role, err := AuthManager.FetchAuth(c.Request.Header.Get("Authorization"))
+		if err != nil {
+			authErr := rest_errors.NewUnauthorizedError("Invalid JWT token")
+			c.AbortWithStatusJSON(authErr.Status, authErr)
+
+			return
+		}
+
+		c.Set(UserIDKey, int64(userID))
+		c.Set(UserRoleKey, role)
+
+		c.Next()
+	}
+}
+
+// OptionalAuthenticate identifies the user when the request carries a valid token but lets
+// anonymous requests through
+func OptionalAuthenticate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if bearToken := c.Request.Header.Get("Authorization"); bearToken != "" {
+			if userID, role, err := AuthManager.FetchAuth(bearToken); err == nil {
+				c.Set(UserIDKey, int64(userID))
+				c.Set(UserRoleKey, role)
+			}
+		}
+
+		c.Next()
+	}
+}
+
+func RequireOwner(param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestUserID, err := strconv.ParseInt(c.Param(param), 10, 64)
+		if err != nil {
+			idErr := rest_errors.NewBadRequestError("User ID should be a number")
+			c.AbortWithStatusJSON(idErr.Status, idErr)
+
+			return
+		}
+
+		if requestUserID != GetUserID(c) {
+			wrongIdErr := rest_errors.NewUnauthorizedError("User ID in the request does not match token user ID")
+			c.AbortWithStatusJSON(wrongIdErr.Status, wrongIdErr)
+
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userRole := GetUserRole(c)
+
+		for _, role := range roles {
+			if userRole == role {
+				c.Next()
+
+				return
+			}
+		}
+
+		roleErr := rest_errors.NewForbiddenError("User does not have permission to access this resource")
+		c.AbortWithStatusJSON(roleErr.Status, roleErr)
+	}
+}
+
+func GetUserID(c *gin.Context) int64 {
+	return c.GetInt64(UserIDKey)
+}
+
+func GetUserRole(c *gin.Context) string {
+	return c.GetString(UserRoleKey)
+}
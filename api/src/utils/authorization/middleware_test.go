@@ -0,0 +1,137 @@
+package authorization
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ericbg27/top10movies-api/src/utils/rest_errors"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+
+	owner := router.Group("/users/:user_id", Authenticate(), RequireOwner("user_id"))
+	owner.GET("", func(c *gin.Context) {
+		c.JSON(http.StatusOK, map[string]int64{"user_id": GetUserID(c)})
+	})
+
+	admin := router.Group("/admin", Authenticate(), RequireRole("moderator", "admin"))
+	admin.GET("", func(c *gin.Context) {
+		c.JSON(http.StatusOK, map[string]string{"role": GetUserRole(c)})
+	})
+
+	router.GET("/optional", OptionalAuthenticate(), func(c *gin.Context) {
+		c.JSON(http.StatusOK, map[string]interface{}{"user_id": GetUserID(c), "role": GetUserRole(c)})
+	})
+
+	return router
+}
+
+func performRequest(t *testing.T, path string, bearToken string) (*httptest.ResponseRecorder, rest_errors.RestErr) {
+	oldAuthManager := AuthManager
+	AuthManager = manager
+	defer func() { AuthManager = oldAuthManager }()
+
+	request, err := http.NewRequest(http.MethodGet, path, nil)
+	assert.Nil(t, err)
+
+	if bearToken != "" {
+		request.Header.Set("Authorization", "Bearer "+bearToken)
+	}
+
+	w := httptest.NewRecorder()
+	newTestRouter().ServeHTTP(w, request)
+
+	var restErr rest_errors.RestErr
+	if w.Code != http.StatusOK {
+		assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &restErr))
+	}
+
+	return w, restErr
+}
+
+func TestAuthenticateAndRequireOwnerSuccess(t *testing.T) {
+	token, err := manager.CreateToken(10, "user", "test-agent")
+	assert.Nil(t, err)
+
+	w, _ := performRequest(t, "/users/10", token.AccessToken)
+
+	var response map[string]int64
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+	assert.EqualValues(t, http.StatusOK, w.Code)
+	assert.EqualValues(t, 10, response["user_id"])
+}
+
+func TestAuthenticateMissingToken(t *testing.T) {
+	w, restErr := performRequest(t, "/users/10", "")
+
+	assert.EqualValues(t, http.StatusUnauthorized, w.Code)
+	assert.EqualValues(t, "Invalid JWT token", restErr.Message)
+}
+
+func TestRequireOwnerInvalidUserID(t *testing.T) {
+	token, err := manager.CreateToken(10, "user", "test-agent")
+	assert.Nil(t, err)
+
+	w, restErr := performRequest(t, "/users/abc", token.AccessToken)
+
+	assert.EqualValues(t, http.StatusBadRequest, w.Code)
+	assert.EqualValues(t, "User ID should be a number", restErr.Message)
+}
+
+func TestRequireOwnerWrongUser(t *testing.T) {
+	token, err := manager.CreateToken(10, "user", "test-agent")
+	assert.Nil(t, err)
+
+	w, restErr := performRequest(t, "/users/11", token.AccessToken)
+
+	assert.EqualValues(t, http.StatusUnauthorized, w.Code)
+	assert.EqualValues(t, "User ID in the request does not match token user ID", restErr.Message)
+}
+
+func TestRequireRoleSuccess(t *testing.T) {
+	token, err := manager.CreateToken(10, "admin", "test-agent")
+	assert.Nil(t, err)
+
+	w, _ := performRequest(t, "/admin", token.AccessToken)
+
+	var response map[string]string
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+	assert.EqualValues(t, http.StatusOK, w.Code)
+	assert.EqualValues(t, "admin", response["role"])
+}
+
+func TestRequireRoleForbidden(t *testing.T) {
+	token, err := manager.CreateToken(10, "user", "test-agent")
+	assert.Nil(t, err)
+
+	w, restErr := performRequest(t, "/admin", token.AccessToken)
+
+	assert.EqualValues(t, http.StatusForbidden, w.Code)
+	assert.EqualValues(t, "User does not have permission to access this resource", restErr.Message)
+}
+
+func TestOptionalAuthenticate(t *testing.T) {
+	token, err := manager.CreateToken(10, "admin", "test-agent")
+	assert.Nil(t, err)
+
+	w, _ := performRequest(t, "/optional", token.AccessToken)
+	assert.EqualValues(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"user_id":10,"role":"admin"}`, w.Body.String())
+
+	w, _ = performRequest(t, "/optional", "invalid")
+	assert.EqualValues(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"user_id":0,"role":""}`, w.Body.String())
+
+	w, _ = performRequest(t, "/optional", "")
+	assert.EqualValues(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"user_id":0,"role":""}`, w.Body.String())
+}
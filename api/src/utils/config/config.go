@@ -2,8 +2,10 @@ package config
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/spf13/viper"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type ServerCfg struct {
@@ -17,34 +19,126 @@ type LoggerCfg struct {
 }
 
 type DatabaseCfg struct {
-	Host     string `mapstructure:"host"`
-	Port     uint16 `mapstructure:"port"`
-	User     string `mapstructure:"user"`
-	Password string `mapstructure:"password"`
-	DbName   string `mapstructure:"dbname"`
-	LogLevel string `mapstructure:"log_level"`
+	Driver      string `mapstructure:"driver"`
+	Host        string `mapstructure:"host"`
+	Port        uint16 `mapstructure:"port"`
+	User        string `mapstructure:"user"`
+	Password    string `mapstructure:"password"`
+	DbName      string `mapstructure:"dbname"`
+	LogLevel    string `mapstructure:"log_level"`
+	AutoMigrate bool   `mapstructure:"auto_migrate"`
+
+	MaxConns          int32         `mapstructure:"max_conns"`
+	MinConns          int32         `mapstructure:"min_conns"`
+	MaxConnLifetime   time.Duration `mapstructure:"max_conn_lifetime"`
+	MaxConnIdleTime   time.Duration `mapstructure:"max_conn_idle_time"`
+	HealthCheckPeriod time.Duration `mapstructure:"health_check_period"`
+	StatsInterval     time.Duration `mapstructure:"stats_interval"`
 }
 
 type RedisCfg struct {
 	CacheTtl int64 `mapstructure:"cache_ttl"`
 }
 
+type CacheCfg struct {
+	Driver   string `mapstructure:"driver"`
+	Capacity int    `mapstructure:"capacity"`
+}
+
 type MovieApiCfg struct {
-	ApiKey string `mapstructure:"api_key"`
+	Provider             string        `mapstructure:"provider"`
+	ApiKey               string        `mapstructure:"api_key"`
+	FixturesPath         string        `mapstructure:"fixtures_path"`
+	MaxConcurrentFetches int           `mapstructure:"max_concurrent_fetches"`
+	FetchTimeout         time.Duration `mapstructure:"fetch_timeout"`
+}
+
+type UsersCfg struct {
+	DeletionGracePeriod   time.Duration `mapstructure:"deletion_grace_period"`
+	PurgeInterval         time.Duration `mapstructure:"purge_interval"`
+	VerificationUrl       string        `mapstructure:"verification_url"`
+	VerificationTokenTtl  time.Duration `mapstructure:"verification_token_ttl"`
+	PasswordResetUrl      string        `mapstructure:"password_reset_url"`
+	PasswordResetTokenTtl time.Duration `mapstructure:"password_reset_token_ttl"`
+}
+
+type PasswordCfg struct {
+	MinLength     int  `mapstructure:"min_length"`
+	MaxLength     int  `mapstructure:"max_length"`
+	RequireUpper  bool `mapstructure:"require_upper"`
+	RequireLower  bool `mapstructure:"require_lower"`
+	RequireDigit  bool `mapstructure:"require_digit"`
+	RequireSymbol bool `mapstructure:"require_symbol"`
+	BcryptCost    int  `mapstructure:"bcrypt_cost"`
+}
+
+type LoginCfg struct {
+	MaxAccountAttempts int           `mapstructure:"max_account_attempts"`
+	MaxIpAttempts      int           `mapstructure:"max_ip_attempts"`
+	AttemptWindow      time.Duration `mapstructure:"attempt_window"`
+	BaseLockout        time.Duration `mapstructure:"base_lockout"`
+	MaxLockout         time.Duration `mapstructure:"max_lockout"`
+}
+
+type RateLimitRuleCfg struct {
+	Method   string        `mapstructure:"method"`
+	Path     string        `mapstructure:"path"`
+	Requests int           `mapstructure:"requests"`
+	Period   time.Duration `mapstructure:"period"`
+	Burst    int           `mapstructure:"burst"`
+}
+
+type RateLimitCfg struct {
+	Driver  string             `mapstructure:"driver"`
+	Default RateLimitRuleCfg   `mapstructure:"default"`
+	Routes  []RateLimitRuleCfg `mapstructure:"routes"`
+}
+
+type MailerCfg struct {
+	Driver     string `mapstructure:"driver"`
+	Host       string `mapstructure:"host"`
+	Port       int    `mapstructure:"port"`
+	Username   string `mapstructure:"username"`
+	Password   string `mapstructure:"password"`
+	From       string `mapstructure:"from"`
+	OutputPath string `mapstructure:"output_path"`
 }
 
 type Config struct {
-	Server   ServerCfg   `mapstructure:"server"`
-	Logger   LoggerCfg   `mapstructure:"logger"`
-	Database DatabaseCfg `mapstructure:"database"`
-	Redis    RedisCfg    `mapstructure:"redis"`
-	MovieApi MovieApiCfg `mapstructure:"movieapi"`
+	Server    ServerCfg    `mapstructure:"server"`
+	Logger    LoggerCfg    `mapstructure:"logger"`
+	Database  DatabaseCfg  `mapstructure:"database"`
+	Redis     RedisCfg     `mapstructure:"redis"`
+	Cache     CacheCfg     `mapstructure:"cache"`
+	MovieApi  MovieApiCfg  `mapstructure:"movieapi"`
+	Users     UsersCfg     `mapstructure:"users"`
+	Password  PasswordCfg  `mapstructure:"password"`
+	Login     LoginCfg     `mapstructure:"login"`
+	RateLimit RateLimitCfg `mapstructure:"ratelimit"`
+	Mailer    MailerCfg    `mapstructure:"mailer"`
 }
 
 var (
 	cfg *Config
 )
 
+const (
+	DatabaseDriverPostgres = "postgres"
+	DatabaseDriverMemory   = "memory"
+
+	CacheDriverRedis = "redis"
+	CacheDriverLRU   = "lru"
+
+	MovieProviderTMDB    = "tmdb"
+	MovieProviderFixture = "fixture"
+
+	RateLimitDriverRedis  = "redis"
+	RateLimitDriverMemory = "memory"
+
+	MailerDriverSMTP = "smtp"
+	MailerDriverFile = "file"
+)
+
 const (
 	configName = "config"
 	configType = "yaml"
@@ -59,9 +153,142 @@ func init() {
 		panic(fmt.Errorf("fatal error in configuration file: %s", err))
 	}
 
+	if cfg.Database.Driver == "" {
+		cfg.Database.Driver = DatabaseDriverPostgres
+	}
+
+	if cfg.Cache.Driver == "" {
+		cfg.Cache.Driver = CacheDriverRedis
+	}
+
 	if cfg.Redis.CacheTtl == 0 {
 		cfg.Redis.CacheTtl = 10
 	}
+
+	if cfg.MovieApi.Provider == "" {
+		cfg.MovieApi.Provider = MovieProviderTMDB
+	}
+
+	if cfg.MovieApi.FixturesPath == "" {
+		cfg.MovieApi.FixturesPath = "fixtures/tmdb"
+	}
+
+	if cfg.MovieApi.MaxConcurrentFetches <= 0 {
+		cfg.MovieApi.MaxConcurrentFetches = 4
+	}
+
+	if cfg.MovieApi.FetchTimeout == 0 {
+		cfg.MovieApi.FetchTimeout = 5 * time.Second
+	}
+
+	if cfg.Users.DeletionGracePeriod == 0 {
+		cfg.Users.DeletionGracePeriod = 30 * 24 * time.Hour
+	}
+
+	if cfg.Users.PurgeInterval == 0 {
+		cfg.Users.PurgeInterval = time.Hour
+	}
+
+	if cfg.Users.VerificationUrl == "" {
+		cfg.Users.VerificationUrl = "http://localhost:8080/verify"
+	}
+
+	if cfg.Users.VerificationTokenTtl == 0 {
+		cfg.Users.VerificationTokenTtl = 24 * time.Hour
+	}
+
+	if cfg.Users.PasswordResetUrl == "" {
+		cfg.Users.PasswordResetUrl = "http://localhost:8080/password/reset"
+	}
+
+	if cfg.Users.PasswordResetTokenTtl == 0 {
+		cfg.Users.PasswordResetTokenTtl = time.Hour
+	}
+
+	if cfg.Password.MinLength <= 0 {
+		cfg.Password.MinLength = 8
+	}
+
+	// bcrypt only uses the first 72 bytes of a password
+	if cfg.Password.MaxLength <= 0 || cfg.Password.MaxLength > 72 {
+		cfg.Password.MaxLength = 72
+	}
+
+	if cfg.Password.BcryptCost == 0 {
+		cfg.Password.BcryptCost = bcrypt.DefaultCost
+	}
+
+	if cfg.Password.BcryptCost < bcrypt.MinCost || cfg.Password.BcryptCost > bcrypt.MaxCost {
+		panic(fmt.Errorf("fatal error in configuration file: bcrypt cost should be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost))
+	}
+
+	if cfg.Login.MaxAccountAttempts <= 0 {
+		cfg.Login.MaxAccountAttempts = 5
+	}
+
+	if cfg.Login.MaxIpAttempts <= 0 {
+		cfg.Login.MaxIpAttempts = 20
+	}
+
+	if cfg.Login.AttemptWindow == 0 {
+		cfg.Login.AttemptWindow = 15 * time.Minute
+	}
+
+	if cfg.Login.BaseLockout == 0 {
+		cfg.Login.BaseLockout = time.Minute
+	}
+
+	if cfg.Login.MaxLockout == 0 {
+		cfg.Login.MaxLockout = time.Hour
+	}
+
+	if cfg.RateLimit.Driver == "" {
+		if cfg.Cache.Driver == CacheDriverRedis {
+			cfg.RateLimit.Driver = RateLimitDriverRedis
+		} else {
+			cfg.RateLimit.Driver = RateLimitDriverMemory
+		}
+	}
+
+	if cfg.RateLimit.Default.Requests == 0 {
+		cfg.RateLimit.Default.Requests = 120
+	}
+
+	if cfg.RateLimit.Routes == nil {
+		cfg.RateLimit.Routes = []RateLimitRuleCfg{
+			{Method: "GET", Path: "/search", Requests: 30},
+			{Method: "POST", Path: "/login", Requests: 10},
+			{Method: "POST", Path: "/register", Requests: 5},
+		}
+	}
+
+	setRateLimitRuleDefaults(&cfg.RateLimit.Default)
+	for i := range cfg.RateLimit.Routes {
+		setRateLimitRuleDefaults(&cfg.RateLimit.Routes[i])
+	}
+
+	if cfg.Mailer.Driver == "" {
+		cfg.Mailer.Driver = MailerDriverSMTP
+	}
+
+	if cfg.Mailer.Port == 0 {
+		cfg.Mailer.Port = 587
+	}
+
+	if cfg.Mailer.From == "" {
+		cfg.Mailer.From = "no-reply@top10movies.com"
+	}
+}
+
+// setRateLimitRuleDefaults makes rules without a period per minute and lets them burst up to their request count
+func setRateLimitRuleDefaults(rule *RateLimitRuleCfg) {
+	if rule.Period == 0 {
+		rule.Period = time.Minute
+	}
+
+	if rule.Burst <= 0 {
+		rule.Burst = rule.Requests
+	}
 }
 
 func setupConfig(cname, ctype, cpath string) (*Config, error) {
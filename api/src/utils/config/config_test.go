@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
@@ -42,6 +43,53 @@ func TestSetUpConfigSuccess(t *testing.T) {
 	assert.EqualValues(t, "1234", testCfg.Database.Password)
 	assert.EqualValues(t, "dbtest", testCfg.Database.DbName)
 	assert.EqualValues(t, "info", testCfg.Database.LogLevel)
+	assert.EqualValues(t, 20, testCfg.Database.MaxConns)
+	assert.EqualValues(t, 2, testCfg.Database.MinConns)
+	assert.EqualValues(t, time.Hour, testCfg.Database.MaxConnLifetime)
+	assert.EqualValues(t, 30*time.Minute, testCfg.Database.MaxConnIdleTime)
+	assert.EqualValues(t, time.Minute, testCfg.Database.HealthCheckPeriod)
+	assert.EqualValues(t, 5*time.Minute, testCfg.Database.StatsInterval)
+
+	assert.EqualValues(t, "fixture", testCfg.MovieApi.Provider)
+	assert.EqualValues(t, "key", testCfg.MovieApi.ApiKey)
+	assert.EqualValues(t, "fixtures/tmdb", testCfg.MovieApi.FixturesPath)
+	assert.EqualValues(t, 8, testCfg.MovieApi.MaxConcurrentFetches)
+	assert.EqualValues(t, 3*time.Second, testCfg.MovieApi.FetchTimeout)
+
+	assert.EqualValues(t, 720*time.Hour, testCfg.Users.DeletionGracePeriod)
+	assert.EqualValues(t, 30*time.Minute, testCfg.Users.PurgeInterval)
+	assert.EqualValues(t, "http://localhost:8080/verify", testCfg.Users.VerificationUrl)
+	assert.EqualValues(t, 12*time.Hour, testCfg.Users.VerificationTokenTtl)
+	assert.EqualValues(t, "http://localhost:8080/password/reset", testCfg.Users.PasswordResetUrl)
+	assert.EqualValues(t, 30*time.Minute, testCfg.Users.PasswordResetTokenTtl)
+
+	assert.EqualValues(t, 10, testCfg.Password.MinLength)
+	assert.EqualValues(t, 64, testCfg.Password.MaxLength)
+	assert.True(t, testCfg.Password.RequireUpper)
+	assert.True(t, testCfg.Password.RequireLower)
+	assert.True(t, testCfg.Password.RequireDigit)
+	assert.False(t, testCfg.Password.RequireSymbol)
+	assert.EqualValues(t, 4, testCfg.Password.BcryptCost)
+
+	assert.EqualValues(t, 3, testCfg.Login.MaxAccountAttempts)
+	assert.EqualValues(t, 10, testCfg.Login.MaxIpAttempts)
+	assert.EqualValues(t, 10*time.Minute, testCfg.Login.AttemptWindow)
+	assert.EqualValues(t, 30*time.Second, testCfg.Login.BaseLockout)
+	assert.EqualValues(t, 15*time.Minute, testCfg.Login.MaxLockout)
+
+	assert.EqualValues(t, "memory", testCfg.RateLimit.Driver)
+	assert.EqualValues(t, 100, testCfg.RateLimit.Default.Requests)
+	assert.EqualValues(t, time.Minute, testCfg.RateLimit.Default.Period)
+	assert.EqualValues(t, 1, len(testCfg.RateLimit.Routes))
+	assert.EqualValues(t, "GET", testCfg.RateLimit.Routes[0].Method)
+	assert.EqualValues(t, "/search", testCfg.RateLimit.Routes[0].Path)
+	assert.EqualValues(t, 10, testCfg.RateLimit.Routes[0].Requests)
+	assert.EqualValues(t, time.Minute, testCfg.RateLimit.Routes[0].Period)
+	assert.EqualValues(t, 5, testCfg.RateLimit.Routes[0].Burst)
+
+	assert.EqualValues(t, "file", testCfg.Mailer.Driver)
+	assert.EqualValues(t, "no-reply@top10movies.com", testCfg.Mailer.From)
+	assert.EqualValues(t, "", testCfg.Mailer.OutputPath)
 }
 
 func TestSetUpConfigFailureNoFile(t *testing.T) {
@@ -0,0 +1,208 @@
+package loginguard
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ericbg27/top10movies-api/src/datasources/cache"
+	"github.com/ericbg27/top10movies-api/src/utils/config"
+	"github.com/ericbg27/top10movies-api/src/utils/logger"
+	"go.uber.org/zap"
+)
+
+type Attempts struct {
+	Failures    int       `json:"failures"`
+	LockedUntil time.Time `json:"locked_until"`
+}
+
+type LoginGuardInterface interface {
+	SetupCacheClient(cache.CacheClient)
+	Check(email string, ip string) (time.Duration, error)
+	RegisterFailure(email string, ip string, reason string) (time.Duration, error)
+	RegisterSuccess(email string) error
+}
+
+type Guard struct {
+	cache cache.CacheClient
+}
+
+var (
+	LoginGuard LoginGuardInterface = &Guard{}
+
+	attemptsLock sync.Mutex
+)
+
+const (
+	accountAttemptsKeyPrefix = "login_attempts:account:"
+	ipAttemptsKeyPrefix      = "login_attempts:ip:"
+
+	ReasonUnknownEmail  = "unknown_email"
+	ReasonWrongPassword = "wrong_password"
+)
+
+func (g *Guard) SetupCacheClient(cacheClient cache.CacheClient) {
+	g.cache = cacheClient
+}
+
+func accountAttemptsKey(email string) string {
+	return accountAttemptsKeyPrefix + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipAttemptsKey(ip string) string {
+	return ipAttemptsKeyPrefix + ip
+}
+
+// lockoutFor doubles the lockout for every failure past the allowed attempts, up to the configured maximum
+func lockoutFor(failures int, maxAttempts int) time.Duration {
+	loginCfg := config.GetConfig().Login
+
+	if failures < maxAttempts {
+		return 0
+	}
+
+	exponent := failures - maxAttempts
+	if exponent > 30 {
+		return loginCfg.MaxLockout
+	}
+
+	lockout := loginCfg.BaseLockout << exponent
+	if lockout <= 0 || lockout > loginCfg.MaxLockout {
+		return loginCfg.MaxLockout
+	}
+
+	return lockout
+}
+
+func (g *Guard) getAttempts(key string) (Attempts, error) {
+	var attempts Attempts
+
+	attemptsData, err := g.cache.Get(key)
+	if errors.Is(err, cache.ErrCacheMiss) {
+		return attempts, nil
+	}
+
+	if err != nil {
+		return attempts, err
+	}
+
+	if err := json.Unmarshal([]byte(attemptsData), &attempts); err != nil {
+		return attempts, err
+	}
+
+	return attempts, nil
+}
+
+func (g *Guard) registerFailure(key string, maxAttempts int, now time.Time) (Attempts, time.Duration, error) {
+	attempts, err := g.getAttempts(key)
+	if err != nil {
+		return attempts, 0, err
+	}
+
+	attempts.Failures++
+
+	lockout := lockoutFor(attempts.Failures, maxAttempts)
+	if lockout > 0 {
+		attempts.LockedUntil = now.Add(lockout)
+	}
+
+	ttl := config.GetConfig().Login.AttemptWindow
+	if lockout > ttl {
+		ttl = lockout
+	}
+
+	attemptsData, err := json.Marshal(attempts)
+	if err != nil {
+		return attempts, 0, err
+	}
+
+	if err := g.cache.Set(key, attemptsData, ttl); err != nil {
+		return attempts, 0, err
+	}
+
+	return attempts, lockout, nil
+}
+
+func (g *Guard) keys(email string, ip string) []string {
+	var keys []string
+	if strings.TrimSpace(email) != "" {
+		keys = append(keys, accountAttemptsKey(email))
+	}
+
+	if ip != "" {
+		keys = append(keys, ipAttemptsKey(ip))
+	}
+
+	return keys
+}
+
+// Check returns for how long logins for the account or from the IP are still locked
+func (g *Guard) Check(email string, ip string) (time.Duration, error) {
+	now := time.Now()
+
+	var remaining time.Duration
+	for _, key := range g.keys(email, ip) {
+		attempts, err := g.getAttempts(key)
+		if err != nil {
+			return 0, err
+		}
+
+		if lockedFor := attempts.LockedUntil.Sub(now); lockedFor > remaining {
+			remaining = lockedFor
+		}
+	}
+
+	return remaining, nil
+}
+
+// RegisterFailure counts a failed login against both the account and the IP, writes an
+// audit log entry and returns the lockout that now applies
+func (g *Guard) RegisterFailure(email string, ip string, reason string) (time.Duration, error) {
+	attemptsLock.Lock()
+	defer attemptsLock.Unlock()
+
+	loginCfg := config.GetConfig().Login
+	now := time.Now()
+
+	var accountAttempts, ipAttempts Attempts
+	var accountLockout, ipLockout time.Duration
+	var err error
+
+	if strings.TrimSpace(email) != "" {
+		if accountAttempts, accountLockout, err = g.registerFailure(accountAttemptsKey(email), loginCfg.MaxAccountAttempts, now); err != nil {
+			return 0, err
+		}
+	}
+
+	if ip != "" {
+		if ipAttempts, ipLockout, err = g.registerFailure(ipAttemptsKey(ip), loginCfg.MaxIpAttempts, now); err != nil {
+			return 0, err
+		}
+	}
+
+	lockout := accountLockout
+	if ipLockout > lockout {
+		lockout = ipLockout
+	}
+
+	logger.Info("Failed login attempt",
+		zap.String("email", email),
+		zap.String("ip", ip),
+		zap.String("reason", reason),
+		zap.Int("account_failures", accountAttempts.Failures),
+		zap.Int("ip_failures", ipAttempts.Failures),
+		zap.Duration("lockout", lockout),
+	)
+
+	return lockout, nil
+}
+
+// RegisterSuccess clears the account counter. The IP counter is kept so a single successful
+// login does not reset an IP that is guessing passwords for other accounts.
+func (g *Guard) RegisterSuccess(email string) error {
+	_, err := g.cache.Del(accountAttemptsKey(email))
+
+	return err
+}
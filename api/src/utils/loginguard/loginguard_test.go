@@ -0,0 +1,108 @@
+package loginguard
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ericbg27/top10movies-api/src/datasources/lrucache"
+	cache_mock "github.com/ericbg27/top10movies-api/src/mocks/cache"
+	"github.com/ericbg27/top10movies-api/src/utils/config"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	guard *Guard
+)
+
+func TestMain(m *testing.M) {
+	cacheClient := &lrucache.LRUCacheClient{Capacity: 100}
+	cacheClient.SetupCacheConnection()
+
+	guard = &Guard{}
+	guard.SetupCacheClient(cacheClient)
+
+	os.Exit(m.Run())
+}
+
+func TestLockoutFor(t *testing.T) {
+	loginCfg := config.GetConfig().Login
+
+	assert.EqualValues(t, 0, lockoutFor(loginCfg.MaxAccountAttempts-1, loginCfg.MaxAccountAttempts))
+	assert.EqualValues(t, loginCfg.BaseLockout, lockoutFor(loginCfg.MaxAccountAttempts, loginCfg.MaxAccountAttempts))
+	assert.EqualValues(t, 2*loginCfg.BaseLockout, lockoutFor(loginCfg.MaxAccountAttempts+1, loginCfg.MaxAccountAttempts))
+	assert.EqualValues(t, 4*loginCfg.BaseLockout, lockoutFor(loginCfg.MaxAccountAttempts+2, loginCfg.MaxAccountAttempts))
+	assert.EqualValues(t, loginCfg.MaxLockout, lockoutFor(loginCfg.MaxAccountAttempts+20, loginCfg.MaxAccountAttempts))
+	assert.EqualValues(t, loginCfg.MaxLockout, lockoutFor(loginCfg.MaxAccountAttempts+100, loginCfg.MaxAccountAttempts))
+}
+
+func TestAccountLockout(t *testing.T) {
+	maxAttempts := config.GetConfig().Login.MaxAccountAttempts
+
+	for i := 1; i < maxAttempts; i++ {
+		lockout, err := guard.RegisterFailure("locked@gmail.com", "", ReasonWrongPassword)
+
+		assert.Nil(t, err)
+		assert.EqualValues(t, 0, lockout)
+	}
+
+	remaining, err := guard.Check("locked@gmail.com", "")
+	assert.Nil(t, err)
+	assert.EqualValues(t, 0, remaining)
+
+	lockout, err := guard.RegisterFailure("LOCKED@gmail.com ", "", ReasonWrongPassword)
+	assert.Nil(t, err)
+	assert.EqualValues(t, config.GetConfig().Login.BaseLockout, lockout)
+
+	remaining, err = guard.Check("locked@gmail.com", "")
+	assert.Nil(t, err)
+	assert.True(t, remaining > 0)
+
+	assert.Nil(t, guard.RegisterSuccess("locked@gmail.com"))
+
+	remaining, err = guard.Check("locked@gmail.com", "")
+	assert.Nil(t, err)
+	assert.EqualValues(t, 0, remaining)
+}
+
+func TestIpLockout(t *testing.T) {
+	maxAttempts := config.GetConfig().Login.MaxIpAttempts
+
+	var lockout time.Duration
+	var err error
+	for i := 0; i < maxAttempts; i++ {
+		lockout, err = guard.RegisterFailure(fmt.Sprintf("user%d@gmail.com", i), "10.0.0.1", ReasonUnknownEmail)
+		assert.Nil(t, err)
+	}
+
+	assert.EqualValues(t, config.GetConfig().Login.BaseLockout, lockout)
+
+	remaining, err := guard.Check("another@gmail.com", "10.0.0.1")
+	assert.Nil(t, err)
+	assert.True(t, remaining > 0)
+
+	assert.Nil(t, guard.RegisterSuccess("another@gmail.com"))
+
+	remaining, err = guard.Check("another@gmail.com", "10.0.0.1")
+	assert.Nil(t, err)
+	assert.True(t, remaining > 0)
+
+	remaining, err = guard.Check("another@gmail.com", "10.0.0.2")
+	assert.Nil(t, err)
+	assert.EqualValues(t, 0, remaining)
+}
+
+func TestCacheError(t *testing.T) {
+	cacheClient := &cache_mock.CacheClientMock{CanGet: false}
+	cacheClient.SetupCacheConnection()
+
+	failingGuard := &Guard{}
+	failingGuard.SetupCacheClient(cacheClient)
+
+	_, err := failingGuard.Check("johndoe@gmail.com", "10.0.0.1")
+	assert.NotNil(t, err)
+
+	_, err = failingGuard.RegisterFailure("johndoe@gmail.com", "10.0.0.1", ReasonWrongPassword)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,28 @@
+package pagination
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	cursor := Cursor{
+		Sort:  "name",
+		Value: "john doe",
+		ID:    42,
+	}
+
+	decoded, err := DecodeCursor(EncodeCursor(cursor))
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, cursor, decoded)
+}
+
+func TestDecodeInvalidCursor(t *testing.T) {
+	_, err := DecodeCursor("not a cursor!")
+	assert.EqualValues(t, ErrInvalidCursor, err)
+
+	_, err = DecodeCursor("bm90IGpzb24")
+	assert.EqualValues(t, ErrInvalidCursor, err)
+}
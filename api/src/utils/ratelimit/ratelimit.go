@@ -0,0 +1,120 @@
+package ratelimit
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/ericbg27/top10movies-api/src/datasources/ratelimiter"
+	"github.com/ericbg27/top10movies-api/src/utils/authorization"
+	"github.com/ericbg27/top10movies-api/src/utils/config"
+	"github.com/ericbg27/top10movies-api/src/utils/logger"
+	"github.com/ericbg27/top10movies-api/src/utils/rest_errors"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	keyPrefix      = "rate_limit:"
+	defaultRuleKey = "default"
+	anyMethod      = "*"
+)
+
+type rule struct {
+	key   string
+	limit ratelimiter.Limit
+}
+
+// newRule returns nil for rules without a positive request count, which leaves the route unlimited
+func newRule(key string, ruleCfg config.RateLimitRuleCfg) *rule {
+	if ruleCfg.Requests <= 0 || ruleCfg.Period <= 0 {
+		return nil
+	}
+
+	return &rule{
+		key: key,
+		limit: ratelimiter.Limit{
+			Rate:  float64(ruleCfg.Requests) / ruleCfg.Period.Seconds(),
+			Burst: ruleCfg.Burst,
+		},
+	}
+}
+
+func routeKey(method string, path string) string {
+	return method + " " + path
+}
+
+func loadRules() (*rule, map[string]*rule) {
+	rateLimitCfg := config.GetConfig().RateLimit
+
+	routeRules := make(map[string]*rule)
+	for _, routeCfg := range rateLimitCfg.Routes {
+		method := strings.ToUpper(strings.TrimSpace(routeCfg.Method))
+		if method == "" {
+			method = anyMethod
+		}
+
+		key := routeKey(method, routeCfg.Path)
+		routeRules[key] = newRule(key, routeCfg)
+	}
+
+	return newRule(defaultRuleKey, rateLimitCfg.Default), routeRules
+}
+
+// subject identifies who is being throttled: the user when the request carries a valid access token,
+// the client IP otherwise
+func subject(c *gin.Context) string {
+	if bearToken := c.Request.Header.Get("Authorization"); bearToken != "" {
+		if userID, _, err := authorization.AuthManager.FetchAuth(bearToken); err == nil {
+			return "user:" + strconv.FormatUint(userID, 10)
+		}
+	}
+
+	return "ip:" + c.ClientIP()
+}
+
+// RateLimit throttles requests with a token bucket per subject and route. Routes listed in the
+// configuration get their own bucket, every other route shares the default one.
+func RateLimit(limiter ratelimiter.RateLimiter) gin.HandlerFunc {
+	defaultRule, routeRules := loadRules()
+
+	return func(c *gin.Context) {
+		selectedRule, ok := routeRules[routeKey(c.Request.Method, c.FullPath())]
+		if !ok {
+			selectedRule, ok = routeRules[routeKey(anyMethod, c.FullPath())]
+		}
+
+		if !ok {
+			selectedRule = defaultRule
+		}
+
+		if selectedRule == nil {
+			c.Next()
+
+			return
+		}
+
+		allowed, retryAfter, err := limiter.Allow(keyPrefix+selectedRule.key+":"+subject(c), selectedRule.limit)
+		if err != nil {
+			logger.Error("Could not check rate limit", err)
+			c.Next()
+
+			return
+		}
+
+		if !allowed {
+			retryAfterSeconds := int(math.Ceil(retryAfter.Seconds()))
+			if retryAfterSeconds < 1 {
+				retryAfterSeconds = 1
+			}
+
+			c.Header("Retry-After", strconv.Itoa(retryAfterSeconds))
+
+			limitErr := rest_errors.NewTooManyRequestsError("Too many requests, try again later")
+			c.AbortWithStatusJSON(limitErr.Status, limitErr)
+
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,136 @@
+package ratelimit
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ericbg27/top10movies-api/src/datasources/memoryratelimiter"
+	"github.com/ericbg27/top10movies-api/src/datasources/ratelimiter"
+	authorization_mock "github.com/ericbg27/top10movies-api/src/mocks/authorization"
+	"github.com/ericbg27/top10movies-api/src/utils/authorization"
+	"github.com/ericbg27/top10movies-api/src/utils/config"
+	"github.com/ericbg27/top10movies-api/src/utils/rest_errors"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingRateLimiter struct{}
+
+func (f failingRateLimiter) Allow(key string, limit ratelimiter.Limit) (bool, time.Duration, error) {
+	return false, 0, errors.New("unable to reach rate limiter")
+}
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+
+	oldAuthManager := authorization.AuthManager
+	authorization.AuthManager = &authorization_mock.AuthorizationMock{
+		Authorized: true,
+	}
+
+	exitCode := m.Run()
+
+	authorization.AuthManager = oldAuthManager
+
+	os.Exit(exitCode)
+}
+
+func newTestRouter(limiter ratelimiter.RateLimiter) *gin.Engine {
+	router := gin.New()
+	router.Use(RateLimit(limiter))
+
+	handler := func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	}
+
+	router.GET("/search", handler)
+	router.GET("/users/search", handler)
+
+	return router
+}
+
+func performRequest(router *gin.Engine, path string, bearToken string, ip string) *httptest.ResponseRecorder {
+	request, _ := http.NewRequest(http.MethodGet, path, nil)
+	request.RemoteAddr = ip + ":1234"
+
+	if bearToken != "" {
+		request.Header.Set("Authorization", bearToken)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, request)
+
+	return w
+}
+
+func TestRouteRuleLimitsBurst(t *testing.T) {
+	router := newTestRouter(memoryratelimiter.NewMemoryRateLimiter())
+	burst := config.GetConfig().RateLimit.Routes[0].Burst
+
+	for i := 0; i < burst; i++ {
+		w := performRequest(router, "/search", "", "10.0.0.1")
+		assert.EqualValues(t, http.StatusOK, w.Code)
+	}
+
+	w := performRequest(router, "/search", "", "10.0.0.1")
+
+	var receivedResponse rest_errors.RestErr
+	err := json.Unmarshal(w.Body.Bytes(), &receivedResponse)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusTooManyRequests, w.Code)
+	assert.EqualValues(t, "Too many requests, try again later", receivedResponse.Message)
+	assert.EqualValues(t, "6", w.Header().Get("Retry-After"))
+
+	w = performRequest(router, "/search", "", "10.0.0.2")
+	assert.EqualValues(t, http.StatusOK, w.Code)
+
+	w = performRequest(router, "/users/search", "", "10.0.0.1")
+	assert.EqualValues(t, http.StatusOK, w.Code)
+}
+
+func TestAuthenticatedRequestsAreKeyedByUser(t *testing.T) {
+	router := newTestRouter(memoryratelimiter.NewMemoryRateLimiter())
+	burst := config.GetConfig().RateLimit.Routes[0].Burst
+
+	for i := 0; i < burst; i++ {
+		w := performRequest(router, "/search", "token_1", "10.0.0.1")
+		assert.EqualValues(t, http.StatusOK, w.Code)
+	}
+
+	w := performRequest(router, "/search", "token_1", "10.0.0.2")
+	assert.EqualValues(t, http.StatusTooManyRequests, w.Code)
+
+	w = performRequest(router, "/search", "token_2", "10.0.0.1")
+	assert.EqualValues(t, http.StatusOK, w.Code)
+
+	w = performRequest(router, "/search", "", "10.0.0.1")
+	assert.EqualValues(t, http.StatusOK, w.Code)
+}
+
+func TestRateLimiterErrorLetsRequestsThrough(t *testing.T) {
+	router := newTestRouter(failingRateLimiter{})
+
+	w := performRequest(router, "/search", "", "10.0.0.1")
+
+	assert.EqualValues(t, http.StatusOK, w.Code)
+}
+
+func TestLoadRules(t *testing.T) {
+	defaultRule, routeRules := loadRules()
+
+	assert.EqualValues(t, defaultRuleKey, defaultRule.key)
+	assert.EqualValues(t, 100, defaultRule.limit.Burst)
+
+	searchRule := routeRules[routeKey("GET", "/search")]
+	assert.NotNil(t, searchRule)
+	assert.EqualValues(t, 5, searchRule.limit.Burst)
+	assert.InDelta(t, 10.0/60.0, searchRule.limit.Rate, 0.0001)
+
+	assert.Nil(t, newRule("unlimited", config.RateLimitRuleCfg{Requests: 0, Period: time.Minute}))
+}
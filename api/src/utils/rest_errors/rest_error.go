@@ -17,6 +17,10 @@ const (
 	notFoundString            = "not_found"
 	internalServerErrorString = "internal_server_error"
 	unauthorizedString        = "unauthorized"
+	forbiddenString           = "forbidden"
+	conflictString            = "conflict"
+	tooManyRequestsString     = "too_many_requests"
+	gatewayTimeoutString      = "gateway_timeout"
 )
 
 func (r RestErr) Error() string {
@@ -62,3 +66,35 @@ func NewUnauthorizedError(message string) *RestErr {
 		Err:     unauthorizedString,
 	}
 }
+
+func NewForbiddenError(message string) *RestErr {
+	return &RestErr{
+		Message: message,
+		Status:  http.StatusForbidden,
+		Err:     forbiddenString,
+	}
+}
+
+func NewConflictError(message string) *RestErr {
+	return &RestErr{
+		Message: message,
+		Status:  http.StatusConflict,
+		Err:     conflictString,
+	}
+}
+
+func NewTooManyRequestsError(message string) *RestErr {
+	return &RestErr{
+		Message: message,
+		Status:  http.StatusTooManyRequests,
+		Err:     tooManyRequestsString,
+	}
+}
+
+func NewGatewayTimeoutError(message string) *RestErr {
+	return &RestErr{
+		Message: message,
+		Status:  http.StatusGatewayTimeout,
+		Err:     gatewayTimeoutString,
+	}
+}
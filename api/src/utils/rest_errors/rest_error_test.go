@@ -10,7 +10,6 @@ import (
 )
 
 const (
-	forbiddenString     = "forbidden"
 	statusCreatedString = "status_created"
 )
 
@@ -70,3 +69,35 @@ func TestNewUnauthorizedError(t *testing.T) {
 	assert.EqualValues(t, http.StatusUnauthorized, unauthorizedErr.Status)
 	assert.EqualValues(t, unauthorizedString, unauthorizedErr.Err)
 }
+
+func TestNewForbiddenError(t *testing.T) {
+	forbiddenErr := NewForbiddenError("Forbidden")
+
+	assert.EqualValues(t, "Forbidden", forbiddenErr.Message)
+	assert.EqualValues(t, http.StatusForbidden, forbiddenErr.Status)
+	assert.EqualValues(t, forbiddenString, forbiddenErr.Err)
+}
+
+func TestNewConflictError(t *testing.T) {
+	conflictErr := NewConflictError("Conflict")
+
+	assert.EqualValues(t, "Conflict", conflictErr.Message)
+	assert.EqualValues(t, http.StatusConflict, conflictErr.Status)
+	assert.EqualValues(t, conflictString, conflictErr.Err)
+}
+
+func TestNewTooManyRequestsError(t *testing.T) {
+	tooManyRequestsErr := NewTooManyRequestsError("Too Many Requests")
+
+	assert.EqualValues(t, "Too Many Requests", tooManyRequestsErr.Message)
+	assert.EqualValues(t, http.StatusTooManyRequests, tooManyRequestsErr.Status)
+	assert.EqualValues(t, tooManyRequestsString, tooManyRequestsErr.Err)
+}
+
+func TestNewGatewayTimeoutError(t *testing.T) {
+	gatewayTimeoutErr := NewGatewayTimeoutError("Gateway Timeout")
+
+	assert.EqualValues(t, "Gateway Timeout", gatewayTimeoutErr.Message)
+	assert.EqualValues(t, http.StatusGatewayTimeout, gatewayTimeoutErr.Status)
+	assert.EqualValues(t, gatewayTimeoutString, gatewayTimeoutErr.Err)
+}